@@ -0,0 +1,99 @@
+package kerrors
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	kratoserrors "github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+	httpstatus "github.com/go-kratos/kratos/v2/transport/http/status"
+	"google.golang.org/grpc/status"
+
+	"github.com/opendevops-cn/codo-golang-sdk/cerr"
+)
+
+// ReasonMetadataKey keeps the reason of kratos errors whose reason is not a cerr code.
+const ReasonMetadataKey = "reason"
+
+// ToKratos converts e to a kratos error. The code is the http status of e.Code, the reason is
+// e.Code in decimal, the message is e.ErrMsg and the metadata is e.Details.Metadata.
+// e stays the cause, so errors.As still finds the *cerr.CodeError.
+func ToKratos(e *cerr.CodeError) *kratoserrors.Error {
+	if e == nil {
+		return nil
+	}
+	ke := kratoserrors.New(e.Code.AsHTTPCode(), strconv.Itoa(int(e.Code)), e.ErrMsg)
+	if len(e.Details.Metadata) > 0 {
+		ke = ke.WithMetadata(e.Details.Metadata)
+	}
+	return ke.WithCause(e)
+}
+
+// FromKratos converts ke to a *cerr.CodeError. A *cerr.CodeError cause is returned as is, a
+// decimal reason is used as the code, otherwise the code is derived from the http status and
+// the reason is kept in metadata under ReasonMetadataKey.
+func FromKratos(ke *kratoserrors.Error) *cerr.CodeError {
+	if ke == nil {
+		return nil
+	}
+	var e *cerr.CodeError
+	if errors.As(ke.Unwrap(), &e) {
+		return e
+	}
+
+	e = &cerr.CodeError{Src: ke, ErrMsg: ke.Message}
+	if code, err := strconv.ParseInt(ke.Reason, 10, 32); err == nil {
+		e.Code = cerr.ErrCode(code)
+	} else {
+		e.Code = cerr.FromGRPCCode(httpstatus.ToGRPCCode(int(ke.Code)))
+		if ke.Reason != "" {
+			e.WithMetadata(ReasonMetadataKey, ke.Reason)
+		}
+	}
+	for k, v := range ke.Metadata {
+		e.WithMetadata(k, v)
+	}
+	return e
+}
+
+// Server returns a server middleware converting *cerr.CodeError returned by handlers to kratos
+// errors, so kratos encoders, logging and tracing see the real status. Other errors are returned
+// as is. Put it after tracing in the chain, e.g. http.Middleware(ktracing.Server(), kerrors.Server()).
+func Server() middleware.Middleware {
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			reply, err := handler(ctx, req)
+			var e *cerr.CodeError
+			if err != nil && errors.As(err, &e) {
+				return reply, ToKratos(e)
+			}
+			return reply, err
+		}
+	}
+}
+
+// Client returns a client middleware converting kratos and gRPC status errors of downstream calls
+// to *cerr.CodeError.
+func Client() middleware.Middleware {
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			reply, err := handler(ctx, req)
+			if err == nil {
+				return reply, nil
+			}
+			var e *cerr.CodeError
+			if errors.As(err, &e) {
+				return reply, err
+			}
+			// only kratos and gRPC status errors are converted, context.Canceled and others are kept
+			if ke := new(kratoserrors.Error); errors.As(err, &ke) {
+				return reply, FromKratos(ke)
+			}
+			if _, ok := status.FromError(err); ok {
+				return reply, FromKratos(kratoserrors.FromError(err))
+			}
+			return reply, err
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package kerrors
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	kratoserrors "github.com/go-kratos/kratos/v2/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/opendevops-cn/codo-golang-sdk/cerr"
+)
+
+func TestKratosRoundTrip(t *testing.T) {
+	src := cerr.New(cerr.ENoEnoughPermissionCode, errors.New("role viewer")).WithMetadata("tenant", "t1")
+
+	ke := ToKratos(src)
+	if ke.Code != 403 || ke.Reason != "120" || ke.Message != "role viewer" || ke.Metadata["tenant"] != "t1" {
+		t.Errorf("kratos====%v", ke)
+	}
+	if got := FromKratos(ke); got != src {
+		t.Errorf("cause====%v", got)
+	}
+
+	// the cause is lost over gRPC, the code comes back from the reason
+	got := FromKratos(kratoserrors.FromError(ke.GRPCStatus().Err()))
+	if got.Code != cerr.ENoEnoughPermissionCode || got.ErrMsg != "role viewer" || got.Details.Metadata["tenant"] != "t1" {
+		t.Errorf("from grpc====%+v", got)
+	}
+}
+
+func TestFromKratosReason(t *testing.T) {
+	got := FromKratos(kratoserrors.NotFound("USER_NOT_FOUND", "user 1 not found"))
+	if got.Code != cerr.EDataNotFoundCode || got.ErrMsg != "user 1 not found" || got.Details.Metadata[ReasonMetadataKey] != "USER_NOT_FOUND" {
+		t.Errorf("from kratos====%+v", got)
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	server := Server()(func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, cerr.New(cerr.EDataExistsCode, errors.New("exists"))
+	})
+	_, err := server(context.Background(), nil)
+	if kratoserrors.Code(err) != 409 {
+		t.Errorf("server====%v", err)
+	}
+
+	client := Client()(func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, status.Error(codes.NotFound, "missing")
+	})
+	_, err = client(context.Background(), nil)
+	var e *cerr.CodeError
+	if !errors.As(err, &e) || e.Code != cerr.EDataNotFoundCode {
+		t.Errorf("client====%v", err)
+	}
+
+	client = Client()(func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, context.Canceled
+	})
+	if _, err = client(context.Background(), nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("canceled====%v", err)
+	}
+}
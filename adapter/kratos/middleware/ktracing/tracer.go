@@ -2,6 +2,7 @@ package ktracing
 
 import (
 	"context"
+	stderrors "errors"
 	"fmt"
 
 	"go.opentelemetry.io/otel"
@@ -12,6 +13,8 @@ import (
 	"google.golang.org/protobuf/proto"
 
 	"github.com/go-kratos/kratos/v2/errors"
+
+	"github.com/opendevops-cn/codo-golang-sdk/cerr"
 )
 
 // Tracer is otel span tracer
@@ -62,7 +65,13 @@ func (t *Tracer) Start(ctx context.Context, operation string, carrier propagatio
 func (t *Tracer) End(_ context.Context, span trace.Span, m interface{}, err error) {
 	if err != nil {
 		span.RecordError(err)
-		if e := errors.FromError(err); e != nil {
+		var ce *cerr.CodeError
+		if stderrors.As(err, &ce) {
+			span.SetAttributes(
+				attribute.Key("rpc.status_code").Int64(int64(ce.Code.AsHTTPCode())),
+				attribute.Key("rpc.biz_code").Int64(int64(ce.Code)),
+			)
+		} else if e := errors.FromError(err); e != nil {
 			span.SetAttributes(attribute.Key("rpc.status_code").Int64(int64(e.Code)))
 		}
 		span.SetStatus(codes.Error, err.Error())
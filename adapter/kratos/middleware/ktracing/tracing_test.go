@@ -2,17 +2,23 @@ package ktracing
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net/http"
 	"os"
 	"reflect"
 	"testing"
 
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/propagation"
 	tracesdk "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 	"go.opentelemetry.io/otel/trace"
 
 	"github.com/go-kratos/kratos/v2/log"
 	"github.com/go-kratos/kratos/v2/transport"
+
+	"github.com/opendevops-cn/codo-golang-sdk/cerr"
 )
 
 var _ transport.Transporter = (*mockTransport)(nil)
@@ -233,3 +239,19 @@ func TestClient(t *testing.T) {
 		t.Errorf("expected %v, got %v", childTraceID, span.SpanContext().TraceID().String())
 	}
 }
+
+func TestTracerEndCodeError(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tracer := NewTracer(trace.SpanKindServer, WithTracerProvider(tracesdk.NewTracerProvider(tracesdk.WithSpanProcessor(recorder))))
+
+	ctx, span := tracer.Start(context.Background(), "/test.server/hello", headerCarrier{})
+	tracer.End(ctx, span, nil, fmt.Errorf("wrap: %w", cerr.New(cerr.EDataNotFoundCode, errors.New("not found"))))
+
+	attrs := map[attribute.Key]int64{}
+	for _, kv := range recorder.Ended()[0].Attributes() {
+		attrs[kv.Key] = kv.Value.AsInt64()
+	}
+	if attrs["rpc.status_code"] != http.StatusNotFound || attrs["rpc.biz_code"] != int64(cerr.EDataNotFoundCode) {
+		t.Errorf("attributes====%v", attrs)
+	}
+}
@@ -8,6 +8,7 @@ import (
 
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/anypb"
 )
 
 type ErrCode int32
@@ -64,33 +65,6 @@ const (
 	EUnAuthCode = ErrCode(201)
 )
 
-var transMap = map[ErrCode]string{
-	SCode:                   "success",
-	EUnknownCode:            "未知错误!",
-	EParamUnparsedCode:      "参数解析错误!",
-	EDBErrorCode:            "数据库查询或操作错误!",
-	ENoPermCode:             "没有任何查看权限!",
-	EReqExpiredCode:         "时间范围不能超过24小时!",
-	ERequiredFieldsCode:     "必填参数不能为空!",
-	ECallApiCode:            "调用api接口失败或没有返回数据!",
-	EK8sCheckCode:           "k8s检查异常!",
-	EHealthCheckCode:        "API健康检查异常!",
-	EInvalidConfigCode:      "配置定义错误!",
-	EOpTimeExceedCode:       "已经过了操作时效!",
-	EInvalidParamCode:       "参数不符合规范!",
-	EOpK8sCode:              "k8s查询或操作异常!",
-	ECronCode:               "cron操作异常!",
-	EDataExistsCode:         "数据已存在!",
-	EDataNotFoundCode:       "数据不存在!",
-	EReadFileCode:           "文件读取错误!",
-	EAddrNotMatchedCode:     "地址类型和地址格式不匹配!",
-	EInspectionCode:         "巡检异常!",
-	EInvalidTokenCode:       "token验证失败!",
-	ENoEnoughPermissionCode: "权限不足!",
-	EDataFormatCode:         "数据格式不正确!",
-	EUnAuthCode:             "请登陆后再操作!",
-}
-
 var httpStatusCodeMap = map[ErrCode]int{
 	SCode:                   nethttp.StatusOK,
 	EUnknownCode:            nethttp.StatusInternalServerError,
@@ -103,7 +77,11 @@ var httpStatusCodeMap = map[ErrCode]int{
 }
 
 type registerCodeOptions struct {
-	httpCode int
+	httpCode     int
+	grpcCode     *codes.Code
+	translations map[string]string
+	name         string
+	module       string
 }
 
 func defaultRegisterCodeOptions() registerCodeOptions {
@@ -128,8 +106,19 @@ func WithRegisterCodeOptionHTTPCode(code int) RegisterCodeOption {
 	})
 }
 
-var registerCodeMu sync.Mutex
+// WithRegisterCodeOptionTranslation 注册 code 在 locale 下的提示信息, 例如 ("en", "Order not found!")
+func WithRegisterCodeOptionTranslation(locale, msg string) RegisterCodeOption {
+	return RegisterCodeFunc(func(o *registerCodeOptions) {
+		if o.translations == nil {
+			o.translations = make(map[string]string)
+		}
+		o.translations[locale] = msg
+	})
+}
+
+var registerCodeMu sync.RWMutex
 
+// RegisterCode 注册 code, transMsg 为 DefaultLocale 的提示信息, 其他语言见 WithRegisterCodeOptionTranslation
 func RegisterCode(code ErrCode, transMsg string, opts ...RegisterCodeOption) error {
 	registerCodeMu.Lock()
 	defer registerCodeMu.Unlock()
@@ -139,22 +128,38 @@ func RegisterCode(code ErrCode, transMsg string, opts ...RegisterCodeOption) err
 		o.apply(&options)
 	}
 
-	if _, ok := transMap[code]; ok {
+	if _, ok := catalog[code]; ok {
 		return fmt.Errorf("code %d already exists", code)
 	}
-	transMap[code] = transMsg
+	setMessageLocked(code, DefaultLocale, transMsg)
+	for locale, msg := range options.translations {
+		setMessageLocked(code, locale, msg)
+	}
+	for locale, msg := range pendingTranslations[code] {
+		setMessageLocked(code, locale, msg)
+	}
+	delete(pendingTranslations, code)
 	httpStatusCodeMap[code] = options.httpCode
+	if options.grpcCode != nil {
+		grpcCodeMap[code] = *options.grpcCode
+	}
+	if options.name != "" {
+		codeNames[code] = options.name
+	}
+	if options.module != "" {
+		codeModules[code] = options.module
+	}
 	return nil
 }
 
+// String 返回 DefaultLocale 的提示信息
 func (code ErrCode) String() string {
-	if v, ok := transMap[code]; ok {
-		return v
-	}
-	return "未知错误"
+	return code.Message(DefaultLocale)
 }
 
 func (code ErrCode) AsHTTPCode() int {
+	registerCodeMu.RLock()
+	defer registerCodeMu.RUnlock()
 	if v, ok := httpStatusCodeMap[code]; ok {
 		return v
 	}
@@ -165,6 +170,8 @@ type CodeError struct {
 	Code   ErrCode
 	Src    error
 	ErrMsg string
+	// Details 错误详情, 通过 WithFieldViolation 等方法添加
+	Details Details
 }
 
 func (x *CodeError) Error() string {
@@ -176,9 +183,26 @@ func (x *CodeError) Unwrap() error {
 	return x.Src
 }
 
+// AsGrpcError 转为标准 gRPC 状态码的 status, code 和 Metadata 放在 ErrorInfo 中, 见 FromGrpcStatus
 func (x *CodeError) AsGrpcError() *status.Status {
 	// 之所以引用 ErrMsg , 是为了防止 Error 嵌套循环引用
-	return status.New(codes.Code(x.Code), x.ErrMsg)
+	s := status.New(x.Code.AsGRPCCode(), x.ErrMsg)
+	if s.Code() == codes.OK {
+		return s
+	}
+	msgs := x.DetailsProto()
+	if len(x.Details.Metadata) == 0 {
+		msgs = append(msgs, x.errorInfo())
+	}
+	p := s.Proto()
+	for _, msg := range msgs {
+		a, err := anypb.New(msg)
+		if err != nil {
+			continue
+		}
+		p.Details = append(p.Details, a)
+	}
+	return status.FromProto(p)
 }
 
 // New 创建一个新的错误, 并且将 Src 错误包装进去
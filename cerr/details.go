@@ -0,0 +1,153 @@
+package cerr
+
+import (
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// ErrorInfoDomain 携带 code 和 Metadata 的 google.rpc.ErrorInfo 的 domain
+const ErrorInfoDomain = "codo"
+
+// FieldViolation 校验失败的字段
+type FieldViolation struct {
+	// Field 字段路径, 例如 user.name
+	Field string
+	// Description 失败原因
+	Description string
+}
+
+// RetryInfo 客户端应在 RetryDelay 之后重试
+type RetryInfo struct {
+	RetryDelay time.Duration
+}
+
+// ResourceInfo 出错的资源
+type ResourceInfo struct {
+	// ResourceType 资源类型, 例如 order
+	ResourceType string
+	// ResourceName 资源名称或 ID
+	ResourceName string
+	// Owner 资源所有者, 可为空
+	Owner string
+	// Description 错误说明
+	Description string
+}
+
+// Details 错误详情, 随 CodeError 一起返回给客户端
+type Details struct {
+	FieldViolations []FieldViolation
+	RetryInfo       *RetryInfo
+	ResourceInfo    *ResourceInfo
+	Metadata        map[string]string
+}
+
+// IsEmpty 是否没有任何详情
+func (d *Details) IsEmpty() bool {
+	return len(d.FieldViolations) == 0 && d.RetryInfo == nil && d.ResourceInfo == nil && len(d.Metadata) == 0
+}
+
+// WithFieldViolation 添加校验失败的字段
+func (x *CodeError) WithFieldViolation(field, description string) *CodeError {
+	x.Details.FieldViolations = append(x.Details.FieldViolations, FieldViolation{
+		Field:       field,
+		Description: description,
+	})
+	return x
+}
+
+// WithRetryInfo 设置重试间隔
+func (x *CodeError) WithRetryInfo(delay time.Duration) *CodeError {
+	x.Details.RetryInfo = &RetryInfo{RetryDelay: delay}
+	return x
+}
+
+// WithResourceInfo 设置出错的资源
+func (x *CodeError) WithResourceInfo(info ResourceInfo) *CodeError {
+	x.Details.ResourceInfo = &info
+	return x
+}
+
+// WithMetadata 添加元数据
+func (x *CodeError) WithMetadata(key, value string) *CodeError {
+	if x.Details.Metadata == nil {
+		x.Details.Metadata = make(map[string]string)
+	}
+	x.Details.Metadata[key] = value
+	return x
+}
+
+// DetailsProto 将详情转为 google.rpc 的错误详情, 顺序为 BadRequest、RetryInfo、ResourceInfo、ErrorInfo
+func (x *CodeError) DetailsProto() []proto.Message {
+	d := x.Details
+	var msgs []proto.Message
+	if len(d.FieldViolations) > 0 {
+		badRequest := &errdetails.BadRequest{}
+		for _, v := range d.FieldViolations {
+			badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       v.Field,
+				Description: v.Description,
+			})
+		}
+		msgs = append(msgs, badRequest)
+	}
+	if d.RetryInfo != nil {
+		msgs = append(msgs, &errdetails.RetryInfo{RetryDelay: durationpb.New(d.RetryInfo.RetryDelay)})
+	}
+	if d.ResourceInfo != nil {
+		msgs = append(msgs, &errdetails.ResourceInfo{
+			ResourceType: d.ResourceInfo.ResourceType,
+			ResourceName: d.ResourceInfo.ResourceName,
+			Owner:        d.ResourceInfo.Owner,
+			Description:  d.ResourceInfo.Description,
+		})
+	}
+	if len(d.Metadata) > 0 {
+		msgs = append(msgs, x.errorInfo())
+	}
+	return msgs
+}
+
+// DetailsFromAny 从 google.rpc 的错误详情还原 Details, 不认识的类型会被忽略
+// 只读取 Domain 为 ErrorInfoDomain 的 ErrorInfo, 其他服务的 ErrorInfo 不属于 Metadata
+func DetailsFromAny(details []*anypb.Any) Details {
+	var d Details
+	for _, a := range details {
+		msg, err := a.UnmarshalNew()
+		if err != nil {
+			continue
+		}
+		switch m := msg.(type) {
+		case *errdetails.BadRequest:
+			for _, v := range m.GetFieldViolations() {
+				d.FieldViolations = append(d.FieldViolations, FieldViolation{
+					Field:       v.GetField(),
+					Description: v.GetDescription(),
+				})
+			}
+		case *errdetails.RetryInfo:
+			d.RetryInfo = &RetryInfo{RetryDelay: m.GetRetryDelay().AsDuration()}
+		case *errdetails.ResourceInfo:
+			d.ResourceInfo = &ResourceInfo{
+				ResourceType: m.GetResourceType(),
+				ResourceName: m.GetResourceName(),
+				Owner:        m.GetOwner(),
+				Description:  m.GetDescription(),
+			}
+		case *errdetails.ErrorInfo:
+			if m.GetDomain() != ErrorInfoDomain || len(m.GetMetadata()) == 0 {
+				continue
+			}
+			if d.Metadata == nil {
+				d.Metadata = make(map[string]string, len(m.GetMetadata()))
+			}
+			for k, v := range m.GetMetadata() {
+				d.Metadata[k] = v
+			}
+		}
+	}
+	return d
+}
@@ -0,0 +1,50 @@
+package cerr
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+func TestDetailsGrpcRoundTrip(t *testing.T) {
+	e := New(EInvalidParamCode, errors.New("invalid")).
+		WithFieldViolation("user.name", "required").
+		WithFieldViolation("user.age", "must be positive").
+		WithRetryInfo(3*time.Second).
+		WithResourceInfo(ResourceInfo{ResourceType: "order", ResourceName: "1001"}).
+		WithMetadata("tenant", "t1")
+
+	s := e.AsGrpcError()
+	if len(s.Proto().GetDetails()) != 4 {
+		t.Fatalf("details====%d", len(s.Proto().GetDetails()))
+	}
+	if got := DetailsFromAny(s.Proto().GetDetails()); !reflect.DeepEqual(got, e.Details) {
+		t.Errorf("details====%+v", got)
+	}
+}
+
+func TestDetailsEmpty(t *testing.T) {
+	e := New(EUnknownCode, errors.New("unknown"))
+	if !e.Details.IsEmpty() || e.DetailsProto() != nil {
+		t.Errorf("details====%+v", e.Details)
+	}
+}
+
+func TestDetailsFromAnyOtherDomain(t *testing.T) {
+	other, err := anypb.New(&errdetails.ErrorInfo{Reason: "QUOTA", Domain: "googleapis.com", Metadata: map[string]string{"service": "x"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	own, err := anypb.New(New(EUnknownCode, errors.New("unknown")).WithMetadata("tenant", "t1").errorInfo())
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := DetailsFromAny([]*anypb.Any{other, own})
+	if !reflect.DeepEqual(got.Metadata, map[string]string{"tenant": "t1"}) {
+		t.Errorf("metadata====%v", got.Metadata)
+	}
+}
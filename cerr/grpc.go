@@ -0,0 +1,154 @@
+package cerr
+
+import (
+	"errors"
+	nethttp "net/http"
+	"strconv"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// grpcCodeMap 显式指定的 gRPC 状态码, 未指定的按 http 状态码推导, 见 HTTPToGRPCCode
+var grpcCodeMap = map[ErrCode]codes.Code{
+	SCode:               codes.OK,
+	EUnknownCode:        codes.Unknown,
+	EParamUnparsedCode:  codes.InvalidArgument,
+	EDBErrorCode:        codes.Internal,
+	EReqExpiredCode:     codes.InvalidArgument,
+	ERequiredFieldsCode: codes.InvalidArgument,
+	EOpTimeExceedCode:   codes.FailedPrecondition,
+	EInvalidTokenCode:   codes.Unauthenticated,
+	EDataFormatCode:     codes.InvalidArgument,
+	ENoPermCode:         codes.PermissionDenied,
+}
+
+// grpcFallbackCodes 没有 ErrorInfo 时 gRPC 状态码对应的 code
+var grpcFallbackCodes = map[codes.Code]ErrCode{
+	codes.OK:               SCode,
+	codes.InvalidArgument:  EInvalidParamCode,
+	codes.NotFound:         EDataNotFoundCode,
+	codes.AlreadyExists:    EDataExistsCode,
+	codes.PermissionDenied: ENoEnoughPermissionCode,
+	codes.Unauthenticated:  EUnAuthCode,
+}
+
+// WithRegisterCodeOptionGRPCCode 指定 code 对应的 gRPC 状态码, 默认按 http 状态码推导
+func WithRegisterCodeOptionGRPCCode(code codes.Code) RegisterCodeOption {
+	return RegisterCodeFunc(func(o *registerCodeOptions) {
+		o.grpcCode = &code
+	})
+}
+
+// AsGRPCCode 返回 code 对应的标准 gRPC 状态码, 例如 EDataNotFoundCode -> NotFound
+// 除 SCode 外的 code 都是错误, 指定或推导出 OK 时返回 Unknown, 避免错误在 gRPC 中丢失
+func (code ErrCode) AsGRPCCode() codes.Code {
+	registerCodeMu.RLock()
+	v, ok := grpcCodeMap[code]
+	registerCodeMu.RUnlock()
+	if !ok {
+		v = HTTPToGRPCCode(code.AsHTTPCode())
+	}
+	if v == codes.OK && code != SCode {
+		return codes.Unknown
+	}
+	return v
+}
+
+// HTTPToGRPCCode 返回 http 状态码对应的 gRPC 状态码, 没有对应的返回 Internal
+func HTTPToGRPCCode(httpCode int) codes.Code {
+	switch httpCode {
+	case nethttp.StatusOK:
+		return codes.OK
+	case nethttp.StatusBadRequest:
+		return codes.InvalidArgument
+	case nethttp.StatusUnauthorized:
+		return codes.Unauthenticated
+	case nethttp.StatusForbidden:
+		return codes.PermissionDenied
+	case nethttp.StatusNotFound:
+		return codes.NotFound
+	case nethttp.StatusConflict:
+		return codes.AlreadyExists
+	case nethttp.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case nethttp.StatusNotImplemented:
+		return codes.Unimplemented
+	case nethttp.StatusServiceUnavailable:
+		return codes.Unavailable
+	case nethttp.StatusGatewayTimeout:
+		return codes.DeadlineExceeded
+	default:
+		return codes.Internal
+	}
+}
+
+// FromGRPCCode 返回 gRPC 状态码对应的内置 code, 例如 NotFound -> EDataNotFoundCode, 没有对应的返回 EUnknownCode
+// 大于 Unauthenticated 的不是标准 gRPC 状态码, 为旧版本服务端直接作为 gRPC 状态码返回的 code, 原样返回
+func FromGRPCCode(code codes.Code) ErrCode {
+	if v, ok := grpcFallbackCodes[code]; ok {
+		return v
+	}
+	if code > codes.Unauthenticated {
+		return ErrCode(code)
+	}
+	return EUnknownCode
+}
+
+// errorInfo 携带 code 和 Metadata 的 ErrorInfo, Reason 为 code 的十进制字符串
+func (x *CodeError) errorInfo() *errdetails.ErrorInfo {
+	return &errdetails.ErrorInfo{
+		Reason:   strconv.Itoa(int(x.Code)),
+		Domain:   ErrorInfoDomain,
+		Metadata: x.Details.Metadata,
+	}
+}
+
+// GRPCStatus 实现 grpc status.FromError 识别的接口, 直接返回 CodeError 时 grpc 也会转换
+func (x *CodeError) GRPCStatus() *status.Status {
+	return x.AsGrpcError()
+}
+
+// FromGrpcStatus 还原 AsGrpcError 转换的错误, s 为 OK 时返回 nil.
+// 优先使用 ErrorInfo 中的 code, 没有时按 gRPC 状态码推导, 见 FromGRPCCode
+func FromGrpcStatus(s *status.Status) *CodeError {
+	if s.Code() == codes.OK {
+		return nil
+	}
+	code, ok := EUnknownCode, false
+	for _, a := range s.Proto().GetDetails() {
+		info := &errdetails.ErrorInfo{}
+		if a.UnmarshalTo(info) != nil || info.GetDomain() != ErrorInfoDomain {
+			continue
+		}
+		if v, err := strconv.ParseInt(info.GetReason(), 10, 32); err == nil {
+			code, ok = ErrCode(v), true
+		}
+	}
+	if !ok {
+		code = FromGRPCCode(s.Code())
+	}
+	return &CodeError{
+		Code:    code,
+		Src:     s.Err(),
+		ErrMsg:  s.Message(),
+		Details: DetailsFromAny(s.Proto().GetDetails()),
+	}
+}
+
+// FromGrpcError 将 gRPC 返回的错误转为 CodeError, 不是 gRPC 状态的错误原样返回
+func FromGrpcError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var e *CodeError
+	if errors.As(err, &e) {
+		return err
+	}
+	s, ok := status.FromError(err)
+	if !ok || s.Code() == codes.OK {
+		return err
+	}
+	return FromGrpcStatus(s)
+}
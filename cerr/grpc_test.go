@@ -0,0 +1,90 @@
+package cerr
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestAsGRPCCode(t *testing.T) {
+	cases := map[ErrCode]codes.Code{
+		SCode:                   codes.OK,
+		EDataNotFoundCode:       codes.NotFound,
+		ENoEnoughPermissionCode: codes.PermissionDenied,
+		EUnAuthCode:             codes.Unauthenticated,
+		EInvalidParamCode:       codes.InvalidArgument,
+		EDataExistsCode:         codes.AlreadyExists,
+		EK8sCheckCode:           codes.Internal,
+		ErrCode(99998):          codes.Internal,
+	}
+	for code, want := range cases {
+		if got := code.AsGRPCCode(); got != want {
+			t.Errorf("%d====%s", code, got)
+		}
+	}
+}
+
+func TestAsGRPCCodeNeverOK(t *testing.T) {
+	code := ErrCode(90102)
+	if err := RegisterCode(code, "已处理!", WithRegisterCodeOptionHTTPCode(200)); err != nil {
+		t.Fatal(err)
+	}
+	if got := code.AsGRPCCode(); got != codes.Unknown {
+		t.Errorf("http 200====%s", got)
+	}
+	if got := FromGrpcStatus(New(code, errors.New("done")).AsGrpcError()); got == nil || got.Code != code {
+		t.Errorf("from status====%+v", got)
+	}
+
+	explicit := ErrCode(90103)
+	if err := RegisterCode(explicit, "已处理!", WithRegisterCodeOptionGRPCCode(codes.OK)); err != nil {
+		t.Fatal(err)
+	}
+	if got := explicit.AsGRPCCode(); got != codes.Unknown {
+		t.Errorf("explicit ok====%s", got)
+	}
+}
+
+func TestGrpcStatusRoundTrip(t *testing.T) {
+	code := ErrCode(90101)
+	if err := RegisterCode(code, "余额不足!", WithRegisterCodeOptionGRPCCode(codes.FailedPrecondition)); err != nil {
+		t.Fatal(err)
+	}
+	e := New(code, errors.New("balance 0")).WithMetadata("account", "a1")
+
+	s := e.AsGrpcError()
+	if s.Code() != codes.FailedPrecondition || s.Message() != "balance 0" {
+		t.Errorf("status====%s", s)
+	}
+	got := FromGrpcStatus(s)
+	if got.Code != code || got.ErrMsg != "balance 0" || got.Details.Metadata["account"] != "a1" {
+		t.Errorf("from status====%+v", got)
+	}
+
+	// 直接返回 CodeError 时 grpc 也能识别
+	if s, ok := status.FromError(fmt.Errorf("wrap: %w", e)); !ok || s.Code() != codes.FailedPrecondition {
+		t.Errorf("from error====%s", s)
+	}
+}
+
+func TestFromGrpcStatusFallback(t *testing.T) {
+	if got := FromGrpcStatus(status.New(codes.NotFound, "no such order")); got.Code != EDataNotFoundCode {
+		t.Errorf("not found====%d", got.Code)
+	}
+	if got := FromGrpcStatus(status.New(codes.Unavailable, "down")); got.Code != EUnknownCode {
+		t.Errorf("unavailable====%d", got.Code)
+	}
+	// 旧版本服务端直接返回 code 作为 gRPC 状态码
+	if got := FromGrpcStatus(status.New(codes.Code(115), "legacy")); got.Code != ErrCode(115) || got.ErrMsg != "legacy" {
+		t.Errorf("legacy====%+v", got)
+	}
+	if got := FromGrpcStatus(status.New(codes.OK, "")); got != nil {
+		t.Errorf("ok====%+v", got)
+	}
+	if err := FromGrpcError(errors.New("plain")); err.Error() != "plain" {
+		t.Errorf("plain====%s", err)
+	}
+}
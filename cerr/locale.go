@@ -0,0 +1,255 @@
+package cerr
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// 内置语言
+const (
+	LocaleZh = "zh"
+	LocaleEn = "en"
+	LocaleJa = "ja"
+)
+
+// DefaultLocale 默认语言, 其他语言都找不到提示信息时使用, ErrCode.String 也使用该语言
+const DefaultLocale = LocaleZh
+
+// catalog 提示信息, code -> locale -> msg
+var catalog = map[ErrCode]map[string]string{}
+
+// pendingTranslations 通过 RegisterTranslations 为尚未注册的 code 添加的提示信息, code -> locale -> msg,
+// RegisterCode 时合并到 catalog
+var pendingTranslations = map[ErrCode]map[string]string{}
+
+// fallbacks 自定义的回退链, 见 SetLocaleFallback
+var fallbacks = map[string][]string{}
+
+// locales 至少有一条提示信息的语言
+var locales = map[string]bool{}
+
+// unknownMessages code 未注册时的提示信息
+var unknownMessages = map[string]string{
+	LocaleZh: "未知错误",
+	LocaleEn: "Unknown error",
+	LocaleJa: "不明なエラー",
+}
+
+func init() {
+	builtin := map[ErrCode][3]string{
+		SCode:                   {"success", "success", "success"},
+		EUnknownCode:            {"未知错误!", "Unknown error!", "不明なエラーです!"},
+		EParamUnparsedCode:      {"参数解析错误!", "Failed to parse parameters!", "パラメータの解析に失敗しました!"},
+		EDBErrorCode:            {"数据库查询或操作错误!", "Database query or operation error!", "データベースの照会または操作に失敗しました!"},
+		ENoPermCode:             {"没有任何查看权限!", "No permission to view!", "閲覧権限がありません!"},
+		EReqExpiredCode:         {"时间范围不能超过24小时!", "Time range cannot exceed 24 hours!", "時間範囲は24時間を超えることはできません!"},
+		ERequiredFieldsCode:     {"必填参数不能为空!", "Required parameters cannot be empty!", "必須パラメータを入力してください!"},
+		ECallApiCode:            {"调用api接口失败或没有返回数据!", "API call failed or returned no data!", "API の呼び出しに失敗したか、データが返されませんでした!"},
+		EK8sCheckCode:           {"k8s检查异常!", "k8s check failed!", "k8s のチェックに失敗しました!"},
+		EHealthCheckCode:        {"API健康检查异常!", "API health check failed!", "API のヘルスチェックに失敗しました!"},
+		EInvalidConfigCode:      {"配置定义错误!", "Invalid configuration!", "設定が正しくありません!"},
+		EOpTimeExceedCode:       {"已经过了操作时效!", "Operation time limit exceeded!", "操作の有効期限が切れています!"},
+		EInvalidParamCode:       {"参数不符合规范!", "Invalid parameters!", "パラメータが正しくありません!"},
+		EOpK8sCode:              {"k8s查询或操作异常!", "k8s query or operation failed!", "k8s の照会または操作に失敗しました!"},
+		ECronCode:               {"cron操作异常!", "cron operation failed!", "cron の操作に失敗しました!"},
+		EDataExistsCode:         {"数据已存在!", "Data already exists!", "データは既に存在します!"},
+		EDataNotFoundCode:       {"数据不存在!", "Data not found!", "データが存在しません!"},
+		EReadFileCode:           {"文件读取错误!", "Failed to read file!", "ファイルの読み込みに失敗しました!"},
+		EAddrNotMatchedCode:     {"地址类型和地址格式不匹配!", "Address type does not match address format!", "アドレスの種類と形式が一致しません!"},
+		EInspectionCode:         {"巡检异常!", "Inspection failed!", "点検で異常が見つかりました!"},
+		EInvalidTokenCode:       {"token验证失败!", "Token verification failed!", "トークンの検証に失敗しました!"},
+		ENoEnoughPermissionCode: {"权限不足!", "Permission denied!", "権限が不足しています!"},
+		EDataFormatCode:         {"数据格式不正确!", "Invalid data format!", "データ形式が正しくありません!"},
+		EUnAuthCode:             {"请登陆后再操作!", "Please log in first!", "ログインしてから操作してください!"},
+	}
+	for code, msgs := range builtin {
+		setMessageLocked(code, LocaleZh, msgs[0])
+		setMessageLocked(code, LocaleEn, msgs[1])
+		setMessageLocked(code, LocaleJa, msgs[2])
+	}
+}
+
+func setMessageLocked(code ErrCode, locale, msg string) {
+	locale = NormalizeLocale(locale)
+	msgs, ok := catalog[code]
+	if !ok {
+		msgs = make(map[string]string)
+		catalog[code] = msgs
+	}
+	msgs[locale] = msg
+	locales[locale] = true
+}
+
+// RegisterTranslations 注册或覆盖 locale 下的提示信息, 可用于为内置 code 增加新的语言.
+// code 尚未注册时先保存, 在 RegisterCode 时生效, 不会因此被视为已注册
+func RegisterTranslations(locale string, msgs map[ErrCode]string) {
+	registerCodeMu.Lock()
+	defer registerCodeMu.Unlock()
+	for code, msg := range msgs {
+		if _, ok := catalog[code]; ok {
+			setMessageLocked(code, locale, msg)
+			continue
+		}
+		pending, ok := pendingTranslations[code]
+		if !ok {
+			pending = make(map[string]string)
+			pendingTranslations[code] = pending
+		}
+		pending[NormalizeLocale(locale)] = msg
+	}
+}
+
+// SetLocaleFallback 设置 locale 找不到提示信息时依次尝试的语言, 例如 zh-TW -> zh-HK;
+// 回退链之后还会尝试去掉地区后的语言和 DefaultLocale
+func SetLocaleFallback(locale string, fallback ...string) {
+	registerCodeMu.Lock()
+	defer registerCodeMu.Unlock()
+	normalized := make([]string, 0, len(fallback))
+	for _, item := range fallback {
+		normalized = append(normalized, NormalizeLocale(item))
+	}
+	fallbacks[NormalizeLocale(locale)] = normalized
+}
+
+// Message 返回 locale 的提示信息, 按 locale、回退链、父语言(zh-CN -> zh)、DefaultLocale 的顺序查找
+func (code ErrCode) Message(locale string) string {
+	msg, _ := code.Localize(locale)
+	return msg
+}
+
+// Localize 按偏好语言的优先级为 code 选择提示信息, 同时返回实际使用的语言, 可用于设置 Content-Language.
+// 每个偏好语言依次查找其回退链和父语言, 都没有时使用 DefaultLocale
+func (code ErrCode) Localize(preferred ...string) (msg string, locale string) {
+	registerCodeMu.RLock()
+	defer registerCodeMu.RUnlock()
+
+	msgs, ok := catalog[code]
+	if !ok {
+		msgs = unknownMessages
+	}
+	for _, item := range preferred {
+		item = NormalizeLocale(item)
+		if item == "" || item == "*" {
+			continue
+		}
+		for _, candidate := range localeChainLocked(item) {
+			// DefaultLocale 总是在链尾, 跳过以便尝试下一个偏好语言
+			if candidate == DefaultLocale && !isDefaultLocale(item) {
+				break
+			}
+			if msg, ok := msgs[candidate]; ok {
+				return msg, candidate
+			}
+		}
+	}
+	if msg, ok := msgs[DefaultLocale]; ok {
+		return msg, DefaultLocale
+	}
+	return unknownMessages[DefaultLocale], DefaultLocale
+}
+
+// isDefaultLocale 判断 locale 是否为 DefaultLocale 或其地区变体, 例如 zh-CN
+func isDefaultLocale(locale string) bool {
+	return locale == DefaultLocale || strings.HasPrefix(locale, DefaultLocale+"-")
+}
+
+// localeChainLocked 返回 locale 的查找顺序
+func localeChainLocked(locale string) []string {
+	var chain []string
+	seen := make(map[string]bool)
+	var add func(string)
+	add = func(item string) {
+		if item == "" || seen[item] {
+			return
+		}
+		seen[item] = true
+		chain = append(chain, item)
+		for _, fallback := range fallbacks[item] {
+			add(fallback)
+		}
+		if i := strings.LastIndexByte(item, '-'); i > 0 {
+			add(item[:i])
+		}
+	}
+	add(locale)
+	add(DefaultLocale)
+	return chain
+}
+
+// NormalizeLocale 规范化语言标签, 例如 en_us -> en-US, zh-hans-cn -> zh-Hans-CN
+func NormalizeLocale(locale string) string {
+	parts := strings.Split(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"), "-")
+	for i, part := range parts {
+		switch {
+		case i == 0:
+			parts[i] = strings.ToLower(part)
+		case len(part) == 2:
+			parts[i] = strings.ToUpper(part)
+		case len(part) == 4:
+			parts[i] = strings.ToUpper(part[:1]) + strings.ToLower(part[1:])
+		default:
+			parts[i] = strings.ToLower(part)
+		}
+	}
+	return strings.Join(parts, "-")
+}
+
+// NegotiateLocale 按优先级返回第一个有提示信息的语言(包括其回退链和父语言), 都没有时返回 DefaultLocale.
+// 返回的语言不一定有某个 code 的提示信息, 为单个 code 选择语言使用 ErrCode.Localize
+func NegotiateLocale(preferred ...string) string {
+	registerCodeMu.RLock()
+	defer registerCodeMu.RUnlock()
+
+	for _, locale := range preferred {
+		locale = NormalizeLocale(locale)
+		if locale == "" || locale == "*" {
+			continue
+		}
+		for _, item := range localeChainLocked(locale) {
+			// DefaultLocale 总是在链尾, 跳过以便尝试下一个偏好语言
+			if item == DefaultLocale && !isDefaultLocale(locale) {
+				break
+			}
+			if locales[item] {
+				return item
+			}
+		}
+	}
+	return DefaultLocale
+}
+
+// ParseAcceptLanguage 按 q 值从高到低返回 Accept-Language 中的语言, 例如 "ja,en;q=0.8" -> [ja en]
+func ParseAcceptLanguage(header string) []string {
+	type weighted struct {
+		locale string
+		q      float64
+	}
+	var items []weighted
+	for _, part := range strings.Split(header, ",") {
+		locale, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if locale == "" {
+			continue
+		}
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q <= 0 {
+			continue
+		}
+		items = append(items, weighted{locale: strings.TrimSpace(locale), q: q})
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].q > items[j].q
+	})
+	result := make([]string, 0, len(items))
+	for _, item := range items {
+		result = append(result, item.locale)
+	}
+	return result
+}
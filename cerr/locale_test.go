@@ -0,0 +1,127 @@
+package cerr
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMessage(t *testing.T) {
+	cases := []struct {
+		locale string
+		want   string
+	}{
+		{"", "权限不足!"},
+		{"zh-CN", "权限不足!"},
+		{"en", "Permission denied!"},
+		{"en_us", "Permission denied!"},
+		{"ja-JP", "権限が不足しています!"},
+		{"fr", "权限不足!"},
+	}
+	for _, c := range cases {
+		if got := ENoEnoughPermissionCode.Message(c.locale); got != c.want {
+			t.Errorf("%s====%s", c.locale, got)
+		}
+	}
+	if got := ErrCode(99999).Message("en"); got != "Unknown error" {
+		t.Errorf("unknown====%s", got)
+	}
+}
+
+func TestRegisterCodeTranslation(t *testing.T) {
+	code := ErrCode(90001)
+	err := RegisterCode(code, "订单不存在!",
+		WithRegisterCodeOptionTranslation("en", "Order not found!"),
+		WithRegisterCodeOptionHTTPCode(404),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := RegisterCode(code, "订单不存在!"); err == nil {
+		t.Error("duplicate code registered")
+	}
+	if code.String() != "订单不存在!" || code.Message("en-GB") != "Order not found!" || code.Message("ja") != "订单不存在!" {
+		t.Errorf("msg====%s %s %s", code.String(), code.Message("en-GB"), code.Message("ja"))
+	}
+	if code.AsHTTPCode() != 404 {
+		t.Errorf("http code====%d", code.AsHTTPCode())
+	}
+}
+
+func TestLocalize(t *testing.T) {
+	code := ErrCode(90002)
+	if err := RegisterCode(code, "库存不足!", WithRegisterCodeOptionTranslation("en", "Out of stock!")); err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		preferred   []string
+		msg, locale string
+	}{
+		// 内置 code 有 ja, 但 90002 没有, 应该协商到 en 而不是回退到 zh
+		{[]string{"ja", "en"}, "Out of stock!", "en"},
+		{[]string{"en-US"}, "Out of stock!", "en"},
+		{[]string{"fr"}, "库存不足!", "zh"},
+		{nil, "库存不足!", "zh"},
+	}
+	for _, c := range cases {
+		if msg, locale := code.Localize(c.preferred...); msg != c.msg || locale != c.locale {
+			t.Errorf("%v====%s %s", c.preferred, msg, locale)
+		}
+	}
+}
+
+func TestLocaleFallback(t *testing.T) {
+	RegisterTranslations("zh-HK", map[ErrCode]string{EDataNotFoundCode: "數據不存在!"})
+	SetLocaleFallback("zh-TW", "zh-HK")
+
+	if got := EDataNotFoundCode.Message("zh-TW"); got != "數據不存在!" {
+		t.Errorf("zh-TW====%s", got)
+	}
+	if got := EDataExistsCode.Message("zh-TW"); got != "数据已存在!" {
+		t.Errorf("zh-TW parent====%s", got)
+	}
+}
+
+func TestRegisterTranslationsBeforeCode(t *testing.T) {
+	code := ErrCode(90003)
+	RegisterTranslations("en", map[ErrCode]string{code: "Coupon expired!"})
+	if _, ok := LookupCode(code); ok {
+		t.Fatal("code registered by translations")
+	}
+	for _, info := range Codes() {
+		if info.Code == code {
+			t.Fatalf("code exported====%+v", info)
+		}
+	}
+
+	if err := RegisterCode(code, "优惠券已过期!", WithRegisterCodeOptionHTTPCode(409)); err != nil {
+		t.Fatal(err)
+	}
+	if code.String() != "优惠券已过期!" || code.Message("en-US") != "Coupon expired!" || code.AsHTTPCode() != 409 {
+		t.Errorf("msg====%s %s %d", code.String(), code.Message("en-US"), code.AsHTTPCode())
+	}
+}
+
+func TestNegotiateLocale(t *testing.T) {
+	cases := []struct {
+		header string
+		want   string
+	}{
+		{"", DefaultLocale},
+		{"fr-FR,fr;q=0.9,en;q=0.8", "en"},
+		{"en;q=0.5,ja", "ja"},
+		{"en-US", "en"},
+		{"*", DefaultLocale},
+	}
+	for _, c := range cases {
+		if got := NegotiateLocale(ParseAcceptLanguage(c.header)...); got != c.want {
+			t.Errorf("%q====%s", c.header, got)
+		}
+	}
+}
+
+func TestParseAcceptLanguage(t *testing.T) {
+	got := ParseAcceptLanguage("fr;q=0.5, en-US , ja;q=0.8, de;q=0")
+	if want := []string{"en-US", "ja", "fr"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("parse====%v", got)
+	}
+}
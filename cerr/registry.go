@@ -0,0 +1,119 @@
+package cerr
+
+import (
+	"sort"
+
+	"google.golang.org/grpc/codes"
+)
+
+// BuiltinModule 内置 code 所属的模块
+const BuiltinModule = "builtin"
+
+// codeNames code 的常量名, 用于导出和代码生成
+var codeNames = map[ErrCode]string{
+	SCode:                   "SCode",
+	EUnknownCode:            "EUnknownCode",
+	EParamUnparsedCode:      "EParamUnparsedCode",
+	EDBErrorCode:            "EDBErrorCode",
+	ENoPermCode:             "ENoPermCode",
+	EReqExpiredCode:         "EReqExpiredCode",
+	ERequiredFieldsCode:     "ERequiredFieldsCode",
+	ECallApiCode:            "ECallApiCode",
+	EK8sCheckCode:           "EK8sCheckCode",
+	EHealthCheckCode:        "EHealthCheckCode",
+	EInvalidConfigCode:      "EInvalidConfigCode",
+	EOpTimeExceedCode:       "EOpTimeExceedCode",
+	EInvalidParamCode:       "EInvalidParamCode",
+	EOpK8sCode:              "EOpK8sCode",
+	ECronCode:               "ECronCode",
+	EDataExistsCode:         "EDataExistsCode",
+	EDataNotFoundCode:       "EDataNotFoundCode",
+	EReadFileCode:           "EReadFileCode",
+	EAddrNotMatchedCode:     "EAddrNotMatchedCode",
+	EInspectionCode:         "EInspectionCode",
+	EInvalidTokenCode:       "EInvalidTokenCode",
+	ENoEnoughPermissionCode: "ENoEnoughPermissionCode",
+	EDataFormatCode:         "EDataFormatCode",
+	EUnAuthCode:             "EUnAuthCode",
+}
+
+// codeModules code 所属的模块, 内置 code 为 BuiltinModule
+var codeModules = map[ErrCode]string{}
+
+func init() {
+	for code := range codeNames {
+		codeModules[code] = BuiltinModule
+	}
+}
+
+// WithRegisterCodeOptionName 指定 code 的常量名, 例如 EOrderNotFoundCode
+func WithRegisterCodeOptionName(name string) RegisterCodeOption {
+	return RegisterCodeFunc(func(o *registerCodeOptions) {
+		o.name = name
+	})
+}
+
+// WithRegisterCodeOptionModule 指定 code 所属的模块, 例如 order
+func WithRegisterCodeOptionModule(module string) RegisterCodeOption {
+	return RegisterCodeFunc(func(o *registerCodeOptions) {
+		o.module = module
+	})
+}
+
+// CodeInfo code 的注册信息
+type CodeInfo struct {
+	Code ErrCode
+	// Name 常量名, 未指定时为空
+	Name string
+	// Module 所属模块, 未指定时为空
+	Module string
+	// Messages 各语言的提示信息, locale -> msg
+	Messages map[string]string
+	HTTPCode int
+	GRPCCode codes.Code
+}
+
+// Codes 返回所有已注册的 code, 按 code 升序
+func Codes() []CodeInfo {
+	registerCodeMu.RLock()
+	list := make([]ErrCode, 0, len(catalog))
+	for code := range catalog {
+		list = append(list, code)
+	}
+	registerCodeMu.RUnlock()
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i] < list[j]
+	})
+	infos := make([]CodeInfo, 0, len(list))
+	for _, code := range list {
+		if info, ok := LookupCode(code); ok {
+			infos = append(infos, info)
+		}
+	}
+	return infos
+}
+
+// LookupCode 返回 code 的注册信息, 未注册时返回 false
+func LookupCode(code ErrCode) (CodeInfo, bool) {
+	registerCodeMu.RLock()
+	msgs, ok := catalog[code]
+	if !ok {
+		registerCodeMu.RUnlock()
+		return CodeInfo{}, false
+	}
+	info := CodeInfo{
+		Code:     code,
+		Name:     codeNames[code],
+		Module:   codeModules[code],
+		Messages: make(map[string]string, len(msgs)),
+	}
+	for locale, msg := range msgs {
+		info.Messages[locale] = msg
+	}
+	registerCodeMu.RUnlock()
+
+	info.HTTPCode = code.AsHTTPCode()
+	info.GRPCCode = code.AsGRPCCode()
+	return info, true
+}
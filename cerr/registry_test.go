@@ -0,0 +1,39 @@
+package cerr
+
+import (
+	"testing"
+
+	"google.golang.org/grpc/codes"
+)
+
+func TestCodes(t *testing.T) {
+	code := ErrCode(90201)
+	err := RegisterCode(code, "库存不足!",
+		WithRegisterCodeOptionName("EStockNotEnoughCode"),
+		WithRegisterCodeOptionModule("stock"),
+		WithRegisterCodeOptionHTTPCode(409),
+		WithRegisterCodeOptionTranslation("en", "Out of stock!"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	infos := Codes()
+	if infos[0].Code != SCode || infos[0].Name != "SCode" || infos[0].Module != BuiltinModule {
+		t.Errorf("first====%+v", infos[0])
+	}
+	for i := 1; i < len(infos); i++ {
+		if infos[i-1].Code >= infos[i].Code {
+			t.Fatalf("not sorted====%d %d", infos[i-1].Code, infos[i].Code)
+		}
+	}
+
+	info, ok := LookupCode(code)
+	if !ok || info.Name != "EStockNotEnoughCode" || info.Module != "stock" || info.Messages["en"] != "Out of stock!" ||
+		info.HTTPCode != 409 || info.GRPCCode != codes.AlreadyExists {
+		t.Errorf("info====%+v", info)
+	}
+	if _, ok := LookupCode(ErrCode(99997)); ok {
+		t.Error("unknown code found")
+	}
+}
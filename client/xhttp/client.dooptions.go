@@ -1,7 +1,10 @@
 package xhttp
 
+import "github.com/opendevops-cn/codo-golang-sdk/redact"
+
 type options struct {
 	recordSize uint32 // 最大4MB
+	redactor   *redact.Redactor
 }
 
 func defaultDoOptions() options {
@@ -25,3 +28,16 @@ func NewDoOptionsWithRecordSize(size uint32) *DoOptionsWithRecordSize {
 func (x *DoOptionsWithRecordSize) apply(o *options) {
 	o.recordSize = x.size
 }
+
+// DoOptionsWithRedactor 记录到 span event 的响应头和响应体先经过 redactor 脱敏
+type DoOptionsWithRedactor struct {
+	redactor *redact.Redactor
+}
+
+func NewDoOptionsWithRedactor(redactor *redact.Redactor) *DoOptionsWithRedactor {
+	return &DoOptionsWithRedactor{redactor: redactor}
+}
+
+func (x *DoOptionsWithRedactor) apply(o *options) {
+	o.redactor = x.redactor
+}
@@ -152,7 +152,8 @@ func (x *Client) Do(ctx context.Context, request *http.Request, opts ...IDoOptio
 		"codo/xhttp",
 		trace.WithInstrumentationVersion(meta.Version),
 	)
-	ctx, span := tr.Start(ctx, request.URL.String(), trace.WithSpanKind(trace.SpanKindClient))
+	// span 名称不包含 query, 避免 token 等参数泄露到 trace 中
+	ctx, span := tr.Start(ctx, request.Method+" "+operation, trace.WithSpanKind(trace.SpanKindClient))
 	defer span.End()
 
 	span.SetAttributes(commonLabels()...)
@@ -202,12 +203,16 @@ func (x *Client) Do(ctx context.Context, request *http.Request, opts ...IDoOptio
 		),
 	)
 
-	// 记录 trace
-	bs, _ := json.Marshal(headerToMap(response.Header))
+	// 记录 trace, 脱敏在截断之前, 保证 JSON 完整
+	header, body := response.Header, reqBodyContentBytes
+	if doOptions.redactor != nil {
+		header, body = doOptions.redactor.Header(header), doOptions.redactor.JSON(body)
+	}
+	bs, _ := json.Marshal(headerToMap(header))
 	span.AddEvent("http.response", trace.WithAttributes(
 		attribute.String(tracingEventHttpResponseHeaders, string(bs)),
 		attribute.String(tracingEventHttpResponseBody, strLimit(
-			string(reqBodyContentBytes),
+			string(body),
 			int(doOptions.recordSize),
 			"...",
 		)),
@@ -1,6 +1,8 @@
 package config
 
 import (
+	"context"
+	"encoding"
 	"encoding/json"
 	"flag"
 	"fmt"
@@ -9,38 +11,44 @@ import (
 	"reflect"
 	"strconv"
 	"strings"
+	"time"
 	"unsafe"
 
 	"github.com/spf13/pflag"
 	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/proto"
-	"gopkg.in/yaml.v3"
+	"google.golang.org/protobuf/reflect/protoreflect"
 )
 
 type ConfigOptions struct {
 	loadFromEnv bool
 	envPrefix   string
+	dotEnvFiles []string
 
-	loadFromYaml bool
-	yamlPath     string
+	// sources 按添加顺序合并, WithYaml 也是其中之一
+	sources []Source
+	ctx     context.Context
 
-	loadFromFlag bool
-	flagSet      *flag.FlagSet
-	flagArgs     []string
+	flag  *flagSource
+	pflag *pflagSource
 
-	loadFromPFlag bool
-	pflagSet      *pflag.FlagSet
-	pflagArgs     []string
+	watchInterval     time.Duration
+	watchErrorHandler func(err error)
+
+	secretKey string
+	// resolveRemoteSecrets 是否解析远程配置源中的 ${env:}/${file:} 引用
+	resolveRemoteSecrets bool
+
+	provenance *Provenance
 }
 
 type ConfigOption func(*ConfigOptions)
 
 func defaultConfigOptions() ConfigOptions {
 	return ConfigOptions{
-		loadFromEnv:  true,
-		envPrefix:    "CODO",
-		loadFromYaml: false,
-		yamlPath:     "",
+		loadFromEnv: true,
+		envPrefix:   "CODO",
+		ctx:         context.Background(),
 	}
 }
 
@@ -53,89 +61,122 @@ func WithEnv(prefix string) ConfigOption {
 }
 
 // WithYaml 从 YAML 加载配置, 依赖 json flag , 需要定义正确的数据类型
-func WithYaml(filepath string) ConfigOption {
+// 多个文件或多次调用时按顺序深度合并, 后面的文件覆盖前面的, 路径支持通配符和 profile, 见 NewFileSource
+func WithYaml(filepaths ...string) ConfigOption {
+	sources := make([]Source, 0, len(filepaths))
+	for _, filepath := range filepaths {
+		sources = append(sources, NewFileSource(filepath))
+	}
+	return WithSource(sources...)
+}
+
+// WithJSONFile 从 JSON 文件加载配置, 和 WithYaml 一样按添加顺序合并
+func WithJSONFile(filepaths ...string) ConfigOption {
+	sources := make([]Source, 0, len(filepaths))
+	for _, filepath := range filepaths {
+		sources = append(sources, NewJSONFileSource(filepath))
+	}
+	return WithSource(sources...)
+}
+
+// WithTOMLFile 从 TOML 文件加载配置, 和 WithYaml 一样按添加顺序合并
+func WithTOMLFile(filepaths ...string) ConfigOption {
+	sources := make([]Source, 0, len(filepaths))
+	for _, filepath := range filepaths {
+		sources = append(sources, NewTOMLFileSource(filepath))
+	}
+	return WithSource(sources...)
+}
+
+// WithHCLFile 从 HCL 文件加载配置, 和 WithYaml 一样按添加顺序合并
+func WithHCLFile(filepaths ...string) ConfigOption {
+	sources := make([]Source, 0, len(filepaths))
+	for _, filepath := range filepaths {
+		sources = append(sources, NewHCLFileSource(filepath))
+	}
+	return WithSource(sources...)
+}
+
+// WithDotEnv 从 .env 文件读取环境变量, 作为环境变量层加载, 不修改进程的环境变量
+// 多个文件按顺序覆盖, 进程中已有的环境变量优先于 .env 文件
+func WithDotEnv(filepaths ...string) ConfigOption {
 	return func(options *ConfigOptions) {
-		options.loadFromYaml = true
-		options.yamlPath = filepath
+		options.loadFromEnv = true
+		options.dotEnvFiles = append(options.dotEnvFiles, filepaths...)
 	}
 }
 
 // WithFlag 从 flag 加载配置
 func WithFlag(flagSet *flag.FlagSet, args []string) ConfigOption {
 	return func(options *ConfigOptions) {
-		options.loadFromFlag = true
-		options.flagSet = flagSet
-		options.flagArgs = args
+		options.flag = &flagSource{flagSet: flagSet, args: args}
 	}
 }
 
 // WithPFlag 从 pflag 加载配置
 func WithPFlag(pflagSet *pflag.FlagSet, args []string) ConfigOption {
 	return func(options *ConfigOptions) {
-		options.loadFromPFlag = true
-		options.pflagSet = pflagSet
-		options.pflagArgs = args
+		options.pflag = &pflagSource{flagSet: pflagSet, args: args}
 	}
 }
 
-// LoadConfig 加载配置, 优先级为 flag > env > yaml
+// LoadConfig 加载配置, 默认优先级为 flag > env > yaml/source > default tag
+// 需要调整优先级时使用 NewEnvSource/NewFlagSource 和其他配置源一起通过 WithSource 按顺序添加
+// 字符串配置中的 ${env:}/${file:} 引用和 enc: 密文会被解析, 见 ResolveSecrets, 远程配置源中的引用默认不解析, 见 WithSecretResolution
+// 加载完成后会按 required/min/max/oneof/regex tag 校验, 返回所有不合法字段的汇总错误
 func LoadConfig(dst interface{}, opts ...ConfigOption) error {
 	c := defaultConfigOptions()
 	for _, opt := range opts {
 		opt(&c)
 	}
-	if c.loadFromYaml {
-		err := LoadYaml(c.yamlPath, dst)
-		if err != nil {
-			return err
-		}
-	}
-	if c.loadFromEnv {
-		err := LoadEnv(c.envPrefix, dst)
-		if err != nil {
-			return err
-		}
-	}
-	if c.loadFromFlag {
-		err := LoadFlag(c.flagSet, c.flagArgs, dst)
-		if err != nil {
-			return err
-		}
-	}
-	if c.loadFromPFlag {
-		err := LoadPFlag(c.pflagSet, c.pflagArgs, dst)
-		if err != nil {
-			return err
-		}
-	}
-	return nil
+	return loadConfig(dst, c)
 }
 
-func LoadYaml(filepath string, conf interface{}) error {
-	f, err := os.Open(filepath)
-	if err != nil {
+func loadConfig(dst interface{}, c ConfigOptions) error {
+	recorder := newProvenanceRecorder(c.provenance)
+	defer recorder.save()
+
+	// 先填充默认值, 让 flag 的默认值和 help 信息可以展示出来
+	if err := applyDefaultsTo(dst, nil, recorder.recordFunc(OriginDefault)); err != nil {
 		return err
 	}
-	defer f.Close()
-	var dst map[string]interface{}
-	err = yaml.NewDecoder(f).Decode(&dst)
-	if err != nil {
+	if err := applyLayers(c.ctx, dst, c.layers(), recorder, !c.resolveRemoteSecrets); err != nil {
 		return err
 	}
-	bs, _ := Marshal(dst)
-	return Unmarshal(bs, conf)
+	if err := ResolveSecrets(dst, c.secretKey); err != nil {
+		return err
+	}
+	return Validate(dst)
+}
+
+func LoadYaml(filepath string, conf interface{}) error {
+	return LoadSources(context.Background(), conf, NewFileSource(filepath))
 }
 
 // LoadEnv 解析环境变量到结构体
+// 除基础类型外还支持 time.Duration("30s")、map("K=V,K2=V2")、任意层级的指针,
+// 以及实现了 encoding.TextUnmarshaler 或 flag.Value 的类型
+// 结构体切片按下标展开, 例如 PREFIX_SERVERS_0_HOST, 下标需要从 0 开始连续
 func LoadEnv(prefix string, v interface{}) error {
-	_, err := parseEnv(prefix, reflect.ValueOf(v))
+	_, err := parseEnv(prefix, "", reflect.ValueOf(v), envParser{lookup: os.Getenv})
 	return err
 }
 
-func parseEnv(prefix string, v reflect.Value) (bool, error) {
+// envParser lookup 返回环境变量的值, 未设置时返回空字符串
+// record 不为 nil 时记录被环境变量设置的字段路径, 见 WithProvenance
+type envParser struct {
+	lookup func(string) string
+	record func(path string)
+}
+
+// parseEnv path 为 v 在配置中的字段路径
+func parseEnv(prefix, path string, v reflect.Value, env envParser) (bool, error) {
 	if v.Kind() != reflect.Ptr || v.IsNil() {
 		return false, fmt.Errorf("invalid value: must be a non-nil pointer")
 	}
+	if msg, ok := asProtoMessage(v); ok {
+		return parseProtoEnv(prefix, path, msg, env, map[protoreflect.MessageDescriptor]bool{})
+	}
 
 	v = v.Elem()
 	t := v.Type()
@@ -144,120 +185,224 @@ func parseEnv(prefix string, v reflect.Value) (bool, error) {
 	for i := 0; i < t.NumField(); i++ {
 		field := t.Field(i)
 		value := v.Field(i)
+		if !value.CanSet() {
+			continue
+		}
+
+		envKeyPrefix, envKey := parseEnvKey(prefix, field)
 
-		keys := []string{
-			strings.ToUpper(field.Tag.Get("yaml")),
-			strings.ToUpper(field.Tag.Get("json")),
-			strings.ToUpper(field.Name),
+		ok, err := parseEnvValue(envKeyPrefix, envKey, joinFieldPath(path, field), value, env)
+		if err != nil {
+			return false, err
+		}
+		if ok {
+			isSetFields = true
 		}
+	}
+
+	return isSetFields, nil
+}
 
-		var envKeyPrefix string
-		for _, key := range keys {
-			if key != "" {
-				envKeyPrefix = key
-				if prefix != "" {
-					envKeyPrefix = prefix + "_" + envKeyPrefix
-				}
-				break
+// parseEnvKey 返回字段的环境变量前缀和环境变量名, 设置了 env tag 时使用 tag 作为环境变量名
+func parseEnvKey(prefix string, field reflect.StructField) (string, string) {
+	keys := []string{
+		strings.ToUpper(field.Tag.Get("yaml")),
+		strings.ToUpper(field.Tag.Get("json")),
+		strings.ToUpper(field.Name),
+	}
+
+	var envKeyPrefix string
+	for _, key := range keys {
+		if key != "" {
+			envKeyPrefix = key
+			if prefix != "" {
+				envKeyPrefix = prefix + "_" + envKeyPrefix
 			}
+			break
 		}
+	}
 
-		// 如果 ENV 是 空, 则使用 envKeyPrefix 作为 KEY
-		envKey := field.Tag.Get("env")
-		if envKey == "" {
-			envKey = envKeyPrefix
-		}
+	// 如果 ENV 是 空, 则使用 envKeyPrefix 作为 KEY
+	envKey := field.Tag.Get("env")
+	if envKey == "" {
+		envKey = envKeyPrefix
+	}
+	return envKeyPrefix, envKey
+}
 
-		var valueElement = value
-		if value.Kind() == reflect.Pointer && value.CanSet() {
-			if value.IsNil() {
-				valueElement = reflect.New(value.Type().Elem()).Elem()
-			} else {
-				valueElement = value.Elem()
-			}
+// parseEnvValue 解析单个字段, nil 指针只有在设置了环境变量时才会分配
+func parseEnvValue(envKeyPrefix, envKey, path string, value reflect.Value, env envParser) (bool, error) {
+	if isTextType(value.Type()) {
+		return setField(value, envKey, path, env)
+	}
+
+	switch value.Kind() {
+	case reflect.Pointer:
+		elem := value
+		if value.IsNil() {
+			elem = reflect.New(value.Type().Elem())
+		}
+		ok, err := parseEnvValue(envKeyPrefix, envKey, path, elem.Elem(), env)
+		if ok && value.IsNil() {
+			value.Set(elem)
+		}
+		return ok, err
+	case reflect.Struct:
+		return parseEnv(envKeyPrefix, path, value.Addr(), env)
+	case reflect.Slice:
+		if elemType := value.Type().Elem(); isStructType(elemType) && !isTextType(elemType) {
+			return parseEnvStructSlice(envKeyPrefix, path, value, env)
+		}
+		return setField(value, envKey, path, env)
+	default:
+		return setField(value, envKey, path, env)
+	}
+}
+
+// parseEnvStructSlice 按 PREFIX_0_FIELD 的格式解析结构体切片, 已有的元素会被对应下标的环境变量覆盖
+func parseEnvStructSlice(envKeyPrefix, path string, value reflect.Value, env envParser) (bool, error) {
+	slice := reflect.MakeSlice(value.Type(), 0, value.Len())
+
+	var isSetFields bool
+	for i := 0; ; i++ {
+		item := reflect.New(value.Type().Elem()).Elem()
+		if i < value.Len() {
+			item.Set(value.Index(i))
 		}
+		ok, err := parseEnvValue(fmt.Sprintf("%s_%d", envKeyPrefix, i), "", fmt.Sprintf("%s[%d]", path, i), item, env)
+		if err != nil {
+			return false, err
+		}
+		if !ok && i >= value.Len() {
+			break
+		}
+		if ok {
+			isSetFields = true
+		}
+		slice = reflect.Append(slice, item)
+	}
 
-		switch valueElement.Kind() {
-		case reflect.Struct:
-			ok, err := parseEnv(envKeyPrefix, valueElement.Addr())
-			if err != nil {
-				return false, err
-			}
-			if ok {
-				if value.Kind() == reflect.Pointer && value.CanSet() {
-					value.Set(valueElement.Addr())
-				} else {
-					value.Set(valueElement)
-				}
-				isSetFields = true
-			}
-		case reflect.Slice:
-			if err := setSliceField(valueElement, envKey); err != nil {
-				return false, err
-			}
-		default:
-			ok, err := setField(valueElement, envKey)
-			if err != nil {
-				return false, err
+	if isSetFields {
+		value.Set(slice)
+	}
+	return isSetFields, nil
+}
+
+// parseFlagTag 解析 flag tag, 例如 flag:"host|H", 返回带嵌套结构体前缀的名称和短名称
+func parseFlagTag(prefix string, field reflect.StructField) (string, string) {
+	flagName := field.Tag.Get("flag")
+	if flagName == "" {
+		return "", ""
+	}
+
+	var flagShot string
+	flags := strings.Split(flagName, "|")
+	if len(flags) > 1 {
+		for _, s := range flags {
+			if s == "" {
+				continue
 			}
-			if ok {
-				isSetFields = true
+			if len(s) == 1 {
+				flagShot = s
+			} else {
+				flagName = s
 			}
 		}
 	}
+	return prefix + flagName, flagShot
+}
 
-	return isSetFields, nil
+// flagPrefix 嵌套结构体中的 flag 以字段名为前缀, 例如 --mysql.host, 匿名嵌入的结构体不加前缀
+func flagPrefix(prefix string, field reflect.StructField) string {
+	if field.Anonymous {
+		return prefix
+	}
+	return prefix + fieldName(field) + "."
 }
 
 func LoadFlag(flagSet *flag.FlagSet, args []string, conf interface{}) error {
-	err := parseFlagStruct(flagSet, reflect.ValueOf(conf))
+	return loadFlag(flagSet, args, conf, nil)
+}
+
+// loadFlag record 不为 nil 时记录被命令行参数设置的字段路径
+func loadFlag(flagSet *flag.FlagSet, args []string, conf interface{}, record func(path string)) error {
+	binder := newFlagBinder(record)
+	err := parseFlagStruct(flagSet, reflect.ValueOf(conf), "", "", binder)
 	if err != nil {
 		return err
 	}
-	return flagSet.Parse(args)
+	// 标准库 flag 不支持短名称, 注册一个共用同一个 Value 的别名
+	for _, names := range binder.aliases {
+		if f := flagSet.Lookup(names[1]); f != nil && flagSet.Lookup(names[0]) == nil {
+			flagSet.Var(f.Value, names[0], f.Usage)
+		}
+	}
+	if err := flagSet.Parse(args); err != nil {
+		return err
+	}
+	flagSet.Visit(func(f *flag.Flag) {
+		binder.commit(f.Name)
+	})
+	return nil
 }
 
-func parseFlagStruct(flagSet *flag.FlagSet, v reflect.Value) error {
+// parseFlagStruct prefix 为 flag 名称的前缀, path 为 v 在配置中的字段路径
+func parseFlagStruct(flagSet *flag.FlagSet, v reflect.Value, prefix, path string, binder *flagBinder) error {
 	if v.Kind() != reflect.Ptr || v.IsNil() {
 		return fmt.Errorf("invalid value: must be a non-nil pointer, current=%s", v.Kind())
 	}
+	if msg, ok := asProtoMessage(v); ok {
+		for _, field := range protoFlagFields(prefix, msg, msg, nil, map[protoreflect.MessageDescriptor]bool{}) {
+			binder.bind(field.value.name, field.value.fieldPath(path))
+			flagSet.Var(field.value, field.value.name, field.usage)
+			if field.short != "" {
+				binder.alias(field.short, field.value.name)
+			}
+		}
+		return nil
+	}
 	v = v.Elem()
 	t := v.Type()
 	for i := 0; i < t.NumField(); i++ {
 		field := t.Field(i)
 		fieldValue := v.Field(i)
+		if !fieldValue.CanSet() {
+			continue
+		}
 
-		valueElement := fieldValue
-		if field.Type.Kind() == reflect.Struct {
-			err := parseFlagStruct(flagSet, valueElement.Addr())
+		if isFlagStruct(field.Type) {
+			structValue, leave := binder.enter(fieldValue)
+			err := parseFlagStruct(flagSet, structValue, flagPrefix(prefix, field), joinFieldPath(path, field), binder)
+			leave()
 			if err != nil {
 				return err
 			}
 			continue
 		}
 
-		flagName := field.Tag.Get("flag")
+		flagName, flagShot := parseFlagTag(prefix, field)
 		if flagName == "" {
 			continue
 		}
 
-		var flagShot string
-		flags := strings.Split(flagName, "|")
-		if len(flags) > 1 {
-			for _, s := range flags {
-				if s == "" {
-					continue
-				}
-				if len(s) == 1 {
-					flagShot = s
-				} else {
-					flagName = s
-				}
-			}
+		usage := field.Tag.Get("usage")
+		binder.bind(flagName, joinFieldPath(path, field))
+		if flagShot != "" {
+			binder.alias(flagShot, flagName)
 		}
-		_ = flagShot
 
-		usage := field.Tag.Get("usage")
+		if field.Type == durationType {
+			ptr := fieldValue.Addr().Interface().(*time.Duration)
+			flagSet.DurationVar(ptr, flagName, *ptr, usage)
+			continue
+		}
+		if isReflectFlag(field.Type) {
+			if !isValueType(field.Type) {
+				return fmt.Errorf("unsupported type === %s", field.Type)
+			}
+			flagSet.Var(&reflectFlag{value: fieldValue, name: flagName}, flagName, usage)
+			continue
+		}
 
 		switch fieldValue.Kind() {
 		case reflect.String:
@@ -344,55 +489,85 @@ func parseFlagStruct(flagSet *flag.FlagSet, v reflect.Value) error {
 }
 
 func LoadPFlag(flagSet *pflag.FlagSet, args []string, conf interface{}) error {
-	err := parsePFlagStruct(flagSet, reflect.ValueOf(conf))
+	return loadPFlag(flagSet, args, conf, nil)
+}
+
+// loadPFlag 同 loadFlag
+func loadPFlag(flagSet *pflag.FlagSet, args []string, conf interface{}, record func(path string)) error {
+	binder := newFlagBinder(record)
+	err := parsePFlagStruct(flagSet, reflect.ValueOf(conf), "", "", binder)
 	if err != nil {
 		return err
 	}
-	return flagSet.Parse(args)
+	if err := flagSet.Parse(args); err != nil {
+		return err
+	}
+	flagSet.Visit(func(f *pflag.Flag) {
+		binder.commit(f.Name)
+	})
+	return nil
 }
 
-func parsePFlagStruct(flagSet *pflag.FlagSet, v reflect.Value) error {
+// parsePFlagStruct 同 parseFlagStruct
+func parsePFlagStruct(flagSet *pflag.FlagSet, v reflect.Value, prefix, path string, binder *flagBinder) error {
 	if v.Kind() != reflect.Ptr || v.IsNil() {
 		return fmt.Errorf("invalid value: must be a non-nil pointer, current=%s", v.Kind())
 	}
+	if msg, ok := asProtoMessage(v); ok {
+		for _, field := range protoFlagFields(prefix, msg, msg, nil, map[protoreflect.MessageDescriptor]bool{}) {
+			binder.bind(field.value.name, field.value.fieldPath(path))
+			pf := flagSet.VarPF(field.value, field.value.name, field.short, field.usage)
+			if field.value.IsBoolFlag() {
+				pf.NoOptDefVal = "true"
+			}
+		}
+		return nil
+	}
 	v = v.Elem()
 	t := v.Type()
 
 	for i := 0; i < t.NumField(); i++ {
 		field := t.Field(i)
 		fieldValue := v.Field(i)
+		if !fieldValue.CanSet() {
+			continue
+		}
 
-		valueElement := fieldValue
-		if field.Type.Kind() == reflect.Struct {
-			err := parsePFlagStruct(flagSet, valueElement.Addr())
+		if isFlagStruct(field.Type) {
+			structValue, leave := binder.enter(fieldValue)
+			err := parsePFlagStruct(flagSet, structValue, flagPrefix(prefix, field), joinFieldPath(path, field), binder)
+			leave()
 			if err != nil {
 				return err
 			}
 			continue
 		}
 
-		flagName := field.Tag.Get("flag")
+		flagName, flagShot := parseFlagTag(prefix, field)
 		if flagName == "" {
 			continue
 		}
 
-		var flagShot string
-		flags := strings.Split(flagName, "|")
-		if len(flags) > 1 {
-			for _, s := range flags {
-				if s == "" {
-					continue
-				}
-				if len(s) == 1 {
-					flagShot = s
-				} else {
-					flagName = s
-				}
+		usage := field.Tag.Get("usage")
+		binder.bind(flagName, joinFieldPath(path, field))
+
+		if field.Type == durationType {
+			ptr := fieldValue.Addr().Interface().(*time.Duration)
+			flagSet.DurationVarP(ptr, flagName, flagShot, *ptr, usage)
+			continue
+		}
+		if isReflectFlag(field.Type) {
+			if !isValueType(field.Type) {
+				return fmt.Errorf("unsupported type === %s", field.Type)
+			}
+			value := &reflectFlag{value: fieldValue, name: flagName}
+			pf := flagSet.VarPF(value, flagName, flagShot, usage)
+			if value.IsBoolFlag() {
+				pf.NoOptDefVal = "true"
 			}
+			continue
 		}
 
-		usage := field.Tag.Get("usage")
-
 		switch fieldValue.Kind() {
 		case reflect.String:
 			ptr := fieldValue.Addr().Interface().(*string)
@@ -602,11 +777,63 @@ func (x *sliceValue[T]) Set(value string) error {
 	return nil
 }
 
-func setField(value reflect.Value, envKey string) (bool, error) {
-	envValue := os.Getenv(envKey)
+func setField(value reflect.Value, envKey, path string, env envParser) (bool, error) {
+	envValue := env.lookup(envKey)
 	if envValue == "" {
 		return false, nil
 	}
+	if err := setValue(value, envKey, envValue); err != nil {
+		return false, err
+	}
+	if env.record != nil {
+		env.record(path)
+	}
+	return true, nil
+}
+
+// setValue 将字符串解析后写入 value, key 仅用于错误信息
+// nil 指针会先分配, 实现了 encoding.TextUnmarshaler 或 flag.Value 的类型使用自身的方法解析
+func setValue(value reflect.Value, envKey string, envValue string) error {
+	if value.Kind() == reflect.Pointer {
+		if !value.IsNil() {
+			return setValue(value.Elem(), envKey, envValue)
+		}
+		elem := reflect.New(value.Type().Elem())
+		if err := setValue(elem.Elem(), envKey, envValue); err != nil {
+			return err
+		}
+		value.Set(elem)
+		return nil
+	}
+
+	if value.CanAddr() && value.Addr().CanInterface() {
+		switch x := value.Addr().Interface().(type) {
+		case encoding.TextUnmarshaler:
+			if err := x.UnmarshalText([]byte(envValue)); err != nil {
+				return fmt.Errorf("invalid value for %s: %s, err: %w", envKey, envValue, err)
+			}
+			return nil
+		case flag.Value:
+			if err := x.Set(envValue); err != nil {
+				return fmt.Errorf("invalid value for %s: %s, err: %w", envKey, envValue, err)
+			}
+			return nil
+		}
+	}
+
+	// time.Duration 支持 "30s" 格式, 兼容 JSON 序列化出来的纳秒整数
+	if value.Type() == durationType {
+		d, err := time.ParseDuration(strings.TrimSpace(envValue))
+		if err != nil {
+			i64, intErr := strconv.ParseInt(strings.TrimSpace(envValue), 10, 64)
+			if intErr != nil {
+				return fmt.Errorf("invalid duration value for %s: %s", envKey, envValue)
+			}
+			d = time.Duration(i64)
+		}
+		value.SetInt(int64(d))
+		return nil
+	}
 
 	switch value.Kind() {
 	case reflect.String:
@@ -614,76 +841,46 @@ func setField(value reflect.Value, envKey string) (bool, error) {
 	case reflect.Float64, reflect.Float32:
 		floatValue, err := strconv.ParseFloat(envValue, 64)
 		if err != nil {
-			return false, fmt.Errorf("invalid float value for %s: %s", envKey, envValue)
+			return fmt.Errorf("invalid float value for %s: %s", envKey, envValue)
 		}
 		value.SetFloat(floatValue)
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
 		i64, err := strconv.ParseInt(envValue, 10, 64)
 		if err != nil {
-			return false, fmt.Errorf("invalid integer value for %s: %s", envKey, envValue)
+			return fmt.Errorf("invalid integer value for %s: %s", envKey, envValue)
 		}
 		value.SetInt(i64)
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
 		u64, err := strconv.ParseUint(envValue, 10, 64)
 		if err != nil {
-			return false, fmt.Errorf("invalid unsigned integer value for %s: %s", envKey, envValue)
+			return fmt.Errorf("invalid unsigned integer value for %s: %s", envKey, envValue)
 		}
 		value.SetUint(u64)
 	case reflect.Bool:
 		boolValue, err := strconv.ParseBool(envValue)
 		if err != nil {
-			return false, fmt.Errorf("invalid boolean value for %s: %s", envKey, envValue)
+			return fmt.Errorf("invalid boolean value for %s: %s", envKey, envValue)
 		}
 		value.SetBool(boolValue)
+	case reflect.Slice:
+		return setSliceValue(value, envKey, envValue)
+	case reflect.Map:
+		return setMapValue(value, envKey, envValue)
 	default:
-		return false, fmt.Errorf("unsupported type for %s, %s", envKey, value.Type())
+		return fmt.Errorf("unsupported type for %s, %s", envKey, value.Type())
 	}
 
-	return true, nil
+	return nil
 }
 
-func setSliceField(value reflect.Value, envKey string) error {
-	envValue := os.Getenv(envKey)
-	if envValue == "" {
-		return nil
-	}
-
+// setSliceValue 将逗号分隔的字符串解析后写入 slice, key 仅用于错误信息
+func setSliceValue(value reflect.Value, envKey string, envValue string) error {
 	elements := strings.Split(envValue, ",")
-	sliceType := value.Type().Elem()
 	slice := reflect.MakeSlice(value.Type(), len(elements), len(elements))
 
 	for i, element := range elements {
-		element = strings.TrimSpace(element)
-		switch sliceType.Kind() {
-		case reflect.String:
-			slice.Index(i).SetString(element)
-		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-			intValue, err := strconv.ParseInt(element, 10, 64)
-			if err != nil {
-				return fmt.Errorf("invalid integer value in slice for %s: %s", envKey, element)
-			}
-			slice.Index(i).SetInt(intValue)
-			// uint
-		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-			uintValue, err := strconv.ParseUint(element, 10, 64)
-			if err != nil {
-				return fmt.Errorf("invalid unsigned integer value in slice for %s: %s", envKey, element)
-			}
-			slice.Index(i).SetUint(uintValue)
-		case reflect.Float64, reflect.Float32:
-			floatValue, err := strconv.ParseFloat(element, 64)
-			if err != nil {
-				return fmt.Errorf("invalid float value in slice for %s: %s", envKey, element)
-			}
-			slice.Index(i).SetFloat(floatValue)
-		case reflect.Bool:
-			boolValue, err := strconv.ParseBool(element)
-			if err != nil {
-				return fmt.Errorf("invalid boolean value in slice for %s: %s", envKey, element)
-			}
-			slice.Index(i).SetBool(boolValue)
-		default:
-			return fmt.Errorf("unsupported slice type for %s", envKey)
+		if err := setValue(slice.Index(i), envKey, strings.TrimSpace(element)); err != nil {
+			return err
 		}
 	}
 
@@ -691,6 +888,35 @@ func setSliceField(value reflect.Value, envKey string) error {
 	return nil
 }
 
+// setMapValue 将 K=V,K2=V2 格式的字符串解析后写入 map, key 仅用于错误信息
+func setMapValue(value reflect.Value, envKey string, envValue string) error {
+	mapType := value.Type()
+	m := reflect.MakeMap(mapType)
+
+	for _, element := range strings.Split(envValue, ",") {
+		element = strings.TrimSpace(element)
+		if element == "" {
+			continue
+		}
+		k, v, ok := strings.Cut(element, "=")
+		if !ok {
+			return fmt.Errorf("invalid map value for %s: %s", envKey, element)
+		}
+		mapKey := reflect.New(mapType.Key()).Elem()
+		if err := setValue(mapKey, envKey, strings.TrimSpace(k)); err != nil {
+			return err
+		}
+		mapValue := reflect.New(mapType.Elem()).Elem()
+		if err := setValue(mapValue, envKey, strings.TrimSpace(v)); err != nil {
+			return err
+		}
+		m.SetMapIndex(mapKey, mapValue)
+	}
+
+	value.Set(m)
+	return nil
+}
+
 var (
 	// MarshalOptions is a configurable JSON format marshaller.
 	MarshalOptions = protojson.MarshalOptions{
@@ -2,10 +2,13 @@ package config
 
 import (
 	"flag"
+	"fmt"
 	"log"
+	"net"
 	"os"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/opendevops-cn/codo-golang-sdk/config/testdata"
 	"github.com/spf13/pflag"
@@ -134,3 +137,105 @@ func TestLoadConfig2(t *testing.T) {
 		t.Errorf("load config error")
 	}
 }
+
+type testLevel int
+
+func (x *testLevel) String() string {
+	return []string{"debug", "info"}[*x]
+}
+
+func (x *testLevel) Set(s string) error {
+	switch s {
+	case "debug":
+		*x = 0
+	case "info":
+		*x = 1
+	default:
+		return fmt.Errorf("unknown level %s", s)
+	}
+	return nil
+}
+
+func TestLoadConfigTypes(t *testing.T) {
+	type serverConfig struct {
+		Host    string        `json:"host"`
+		Port    int           `json:"port"`
+		Timeout time.Duration `json:"timeout"`
+	}
+	type dbConfig struct {
+		DSN string `json:"dsn" flag:"db-dsn"`
+	}
+	type cacheConfig struct {
+		Addr string `json:"addr" flag:"cache-addr"`
+	}
+	type testConfig struct {
+		Timeout  time.Duration     `json:"timeout" flag:"timeout"`
+		Labels   map[string]int    `json:"labels" flag:"labels"`
+		IP       net.IP            `json:"ip" flag:"ip"`
+		Level    testLevel         `json:"level" flag:"level"`
+		Retry    **int             `json:"retry" flag:"retry"`
+		Debug    *bool             `json:"debug" flag:"debug"`
+		Backoffs []time.Duration   `json:"backoffs"`
+		Servers  []serverConfig    `json:"servers"`
+		Tags     map[string]string `json:"tags"`
+		DB       *dbConfig         `json:"db"`
+		Cache    *cacheConfig      `json:"cache"`
+	}
+
+	os.Setenv("TEST_TYPES_TIMEOUT", "30s")
+	os.Setenv("TEST_TYPES_LABELS", "a=1, b=2")
+	os.Setenv("TEST_TYPES_IP", "10.0.0.1")
+	os.Setenv("TEST_TYPES_LEVEL", "info")
+	os.Setenv("TEST_TYPES_RETRY", "3")
+	os.Setenv("TEST_TYPES_BACKOFFS", "1s,2s")
+	os.Setenv("TEST_TYPES_SERVERS_0_HOST", "10.0.0.2")
+	os.Setenv("TEST_TYPES_SERVERS_0_TIMEOUT", "1m")
+	os.Setenv("TEST_TYPES_SERVERS_1_HOST", "10.0.0.3")
+	os.Setenv("TEST_TYPES_SERVERS_1_PORT", "8080")
+	os.Setenv("TEST_TYPES_SERVERS_3_HOST", "ignored")
+
+	var env testConfig
+	if err := LoadConfig(&env, WithEnv("TEST_TYPES")); err != nil {
+		t.Fatal(err)
+	}
+	if env.Timeout != time.Second*30 || env.Level != 1 || **env.Retry != 3 || !env.IP.Equal(net.ParseIP("10.0.0.1")) {
+		t.Errorf("env====%+v", env)
+	}
+	if !reflect.DeepEqual(env.Labels, map[string]int{"a": 1, "b": 2}) {
+		t.Errorf("labels====%+v", env.Labels)
+	}
+	if !reflect.DeepEqual(env.Backoffs, []time.Duration{time.Second, time.Second * 2}) {
+		t.Errorf("backoffs====%+v", env.Backoffs)
+	}
+	wantServers := []serverConfig{
+		{Host: "10.0.0.2", Timeout: time.Minute},
+		{Host: "10.0.0.3", Port: 8080},
+	}
+	if !reflect.DeepEqual(env.Servers, wantServers) {
+		t.Errorf("servers====%+v", env.Servers)
+	}
+	if env.Debug != nil || env.Tags != nil || env.DB != nil {
+		t.Errorf("unset fields should stay nil, env====%+v", env)
+	}
+
+	args := []string{"--timeout=1m", "--labels=c=3", "--ip=::1", "--level=debug", "--retry=5", "--debug", "--db.db-dsn=root@tcp"}
+	var stdFlag testConfig
+	if err := LoadConfig(&stdFlag, WithFlag(flag.NewFlagSet("test", flag.ContinueOnError), args)); err != nil {
+		t.Fatal(err)
+	}
+	var pFlag testConfig
+	if err := LoadConfig(&pFlag, WithPFlag(pflag.NewFlagSet("ptest", pflag.ContinueOnError), args)); err != nil {
+		t.Fatal(err)
+	}
+	for _, dst := range []testConfig{stdFlag, pFlag} {
+		if dst.Timeout != time.Minute || dst.Level != 0 || **dst.Retry != 5 || dst.Debug == nil || !*dst.Debug {
+			t.Errorf("flag====%+v", dst)
+		}
+		if !reflect.DeepEqual(dst.Labels, map[string]int{"c": 3}) || !dst.IP.Equal(net.IPv6loopback) {
+			t.Errorf("flag====%+v", dst)
+		}
+		if dst.DB == nil || dst.DB.DSN != "root@tcp" || dst.Cache != nil {
+			t.Errorf("flag db====%+v, cache====%+v", dst.DB, dst.Cache)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// newEnvLookup 返回环境变量层的查找函数, 进程环境变量优先, 未设置时再查找 .env 文件
+func newEnvLookup(dotEnvFiles []string) (func(string) string, error) {
+	if len(dotEnvFiles) == 0 {
+		return os.Getenv, nil
+	}
+
+	values := make(map[string]string)
+	for _, path := range dotEnvFiles {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		err = parseDotEnv(f, values)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("decode %s err: %w", path, err)
+		}
+	}
+	return func(key string) string {
+		if value, ok := os.LookupEnv(key); ok {
+			return value
+		}
+		return values[key]
+	}, nil
+}
+
+// parseDotEnv 解析 .env 格式, 结果写入 dst
+//
+//	# 注释
+//	export KEY=value
+//	KEY=value # 行尾注释
+//	KEY="带空格和 \n 转义的值"
+//	KEY='原样的值'
+func parseDotEnv(r io.Reader, dst map[string]string) error {
+	scanner := bufio.NewScanner(r)
+	var lineNo int
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		key, value, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return fmt.Errorf("line %d: invalid line %q", lineNo, line)
+		}
+		value, err := parseDotEnvValue(strings.TrimSpace(value))
+		if err != nil {
+			return fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		dst[key] = value
+	}
+	return scanner.Err()
+}
+
+func parseDotEnvValue(value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+	switch quote := value[0]; quote {
+	case '"', '\'':
+		end := strings.LastIndexByte(value, quote)
+		if end == 0 {
+			return "", fmt.Errorf("unterminated quoted value %s", value)
+		}
+		if quote == '\'' {
+			return value[1:end], nil
+		}
+		return strconv.Unquote(value[:end+1])
+	default:
+		if i := strings.Index(value, " #"); i >= 0 {
+			value = value[:i]
+		}
+		return strings.TrimSpace(value), nil
+	}
+}
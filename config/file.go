@@ -0,0 +1,368 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/hashicorp/hcl"
+)
+
+// ProfileEnv 默认指定配置 profile 的环境变量, 例如 CODO_PROFILE=prod 时,
+// 加载 config.yaml 之后会继续加载同目录下的 config.prod.yaml(存在时), 见 WithFileProfileEnv
+const ProfileEnv = "CODO_PROFILE"
+
+// includeKey 配置文件顶层的 include 指令, 值为单个路径或路径列表, 支持通配符,
+// 相对路径相对于当前文件所在目录, 被包含的文件先加载, 当前文件覆盖被包含的文件
+const includeKey = "include"
+
+// FileSourceOption 文件配置源的选项
+type FileSourceOption func(*fileSource)
+
+// WithFileProfileEnv 指定读取 profile 的环境变量, 默认为 ProfileEnv
+func WithFileProfileEnv(name string) FileSourceOption {
+	return func(x *fileSource) {
+		x.profileEnv = name
+	}
+}
+
+// NewFileSource 从 YAML 文件加载配置, JSON 是 YAML 的子集, 所以也可以加载 JSON 文件
+// path 支持通配符, 例如 conf.d/*.yaml, 匹配的文件按文件名顺序合并, 没有匹配的文件时不报错,
+// 同时匹配到 app.yaml 和 app.<profile>.yaml 时, 后者视为 profile 文件, 不作为普通文件加载
+// 所有文件加载之后再按顺序加载当前 profile 的文件, 见 ProfileEnv, 文件中的 include 指令见 includeKey
+func NewFileSource(path string, opts ...FileSourceOption) Source {
+	return newFileSource(path, decodeYaml, opts)
+}
+
+// NewJSONFileSource 从 JSON 文件加载配置, 路径规则和 NewFileSource 相同
+func NewJSONFileSource(path string, opts ...FileSourceOption) Source {
+	return newFileSource(path, decodeJSON, opts)
+}
+
+// NewTOMLFileSource 从 TOML 文件加载配置, 路径规则和 NewFileSource 相同
+func NewTOMLFileSource(path string, opts ...FileSourceOption) Source {
+	return newFileSource(path, decodeTOML, opts)
+}
+
+// NewHCLFileSource 从 HCL 文件加载配置, 路径规则和 NewFileSource 相同, 格式见 decodeHCL
+func NewHCLFileSource(path string, opts ...FileSourceOption) Source {
+	return newFileSource(path, decodeHCL, opts)
+}
+
+func newFileSource(path string, decode func(r io.Reader) (map[string]interface{}, error), opts []FileSourceOption) *fileSource {
+	x := &fileSource{path: path, decode: decode, profileEnv: ProfileEnv}
+	for _, opt := range opts {
+		opt(x)
+	}
+	return x
+}
+
+var _ layeredSource = (*fileSource)(nil)
+
+type fileSource struct {
+	path       string
+	decode     func(r io.Reader) (map[string]interface{}, error)
+	profileEnv string
+}
+
+// configFile 解析后的单个文件, include 指令已从 data 中移除
+type configFile struct {
+	path string
+	raw  []byte
+	data map[string]interface{}
+}
+
+func (x *fileSource) Load(ctx context.Context) (map[string]interface{}, error) {
+	layers, err := x.loadLayers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	merged := make(map[string]interface{})
+	for _, layer := range layers {
+		mergeMap(merged, layer, nil)
+	}
+	return merged, nil
+}
+
+func (x *fileSource) loadLayers(_ context.Context) ([]map[string]interface{}, error) {
+	files, err := x.files()
+	if err != nil {
+		return nil, err
+	}
+	layers := make([]map[string]interface{}, 0, len(files))
+	for _, file := range files {
+		layers = append(layers, file.data)
+	}
+	return layers, nil
+}
+
+func (x *fileSource) String() string {
+	return "file:" + x.path
+}
+
+// digest 包含所有被加载的文件, 包括通配符新匹配到的文件和 include 的文件
+func (x *fileSource) digest() ([]byte, error) {
+	files, err := x.files()
+	if err != nil {
+		return nil, err
+	}
+	h := sha256.New()
+	for _, file := range files {
+		h.Write([]byte(file.path))
+		h.Write(file.raw)
+	}
+	return h.Sum(nil), nil
+}
+
+// files 按合并顺序返回需要加载的文件
+func (x *fileSource) files() ([]*configFile, error) {
+	paths, err := expandPath(x.path)
+	if err != nil {
+		return nil, err
+	}
+
+	resolver := &fileResolver{decode: x.decode, visited: make(map[string]bool)}
+	for _, path := range paths {
+		if err := resolver.add(path, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	// profile 文件在所有文件之后加载, 覆盖所有普通文件
+	profile := os.Getenv(x.profileEnv)
+	if profile == "" {
+		return resolver.files, nil
+	}
+	for _, path := range paths {
+		profilePath := profileFilePath(path, profile)
+		if _, err := os.Stat(profilePath); err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		if err := resolver.add(profilePath, nil); err != nil {
+			return nil, err
+		}
+	}
+	return resolver.files, nil
+}
+
+// profileFilePath config.yaml -> config.prod.yaml
+func profileFilePath(path, profile string) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "." + profile + ext
+}
+
+// expandPath 展开通配符, 不含通配符的路径原样返回, 文件不存在时在读取时报错
+// 匹配结果中的 profile 文件会被排除, 见 isProfileFile
+func expandPath(pattern string) ([]string, error) {
+	if !strings.ContainsAny(pattern, "*?[") {
+		return []string{pattern}, nil
+	}
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid path pattern %s: %w", pattern, err)
+	}
+	matched := make(map[string]bool, len(matches))
+	for _, match := range matches {
+		matched[match] = true
+	}
+	paths := matches[:0]
+	for _, match := range matches {
+		if !isProfileFile(match, matched) {
+			paths = append(paths, match)
+		}
+	}
+	return paths, nil
+}
+
+// isProfileFile 判断 path 是否为 matched 中其他文件的 profile 文件, 例如 app.yaml 对应的 app.prod.yaml
+func isProfileFile(path string, matched map[string]bool) bool {
+	ext := filepath.Ext(path)
+	name := strings.TrimSuffix(path, ext)
+	i := strings.LastIndexByte(name, '.')
+	if i < 0 || strings.ContainsRune(name[i:], filepath.Separator) {
+		return false
+	}
+	return matched[name[:i]+ext]
+}
+
+// fileResolver 展开 include 指令, 同一个文件只加载一次
+type fileResolver struct {
+	decode  func(r io.Reader) (map[string]interface{}, error)
+	files   []*configFile
+	visited map[string]bool
+}
+
+// add 先递归加载 include 的文件, 再加载 path 本身, stack 为当前的 include 链, 用于检测循环引用
+func (r *fileResolver) add(path string, stack []string) error {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	for _, item := range stack {
+		if item == absPath {
+			return fmt.Errorf("config include cycle: %s", strings.Join(append(stack, absPath), " -> "))
+		}
+	}
+	if r.visited[absPath] {
+		return nil
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	data, err := r.decode(bytes.NewReader(raw))
+	if err != nil {
+		return fmt.Errorf("decode %s err: %w", path, err)
+	}
+	includes, err := parseIncludes(data[includeKey])
+	if err != nil {
+		return fmt.Errorf("decode %s err: %w", path, err)
+	}
+	delete(data, includeKey)
+
+	stack = append(stack, absPath)
+	for _, include := range includes {
+		if !filepath.IsAbs(include) {
+			include = filepath.Join(filepath.Dir(path), include)
+		}
+		matches, err := expandPath(include)
+		if err != nil {
+			return err
+		}
+		for _, match := range matches {
+			if err := r.add(match, stack); err != nil {
+				return err
+			}
+		}
+	}
+
+	r.visited[absPath] = true
+	r.files = append(r.files, &configFile{path: path, raw: raw, data: data})
+	return nil
+}
+
+func parseIncludes(v interface{}) ([]string, error) {
+	switch value := v.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []string{value}, nil
+	case []interface{}:
+		includes := make([]string, 0, len(value))
+		for _, item := range value {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("invalid %s item %v", includeKey, item)
+			}
+			includes = append(includes, s)
+		}
+		return includes, nil
+	default:
+		return nil, fmt.Errorf("invalid %s %v", includeKey, v)
+	}
+}
+
+// decodeJSON 数字保持为 json.Number, 避免大整数转换为 float64 丢失精度
+func decodeJSON(r io.Reader) (map[string]interface{}, error) {
+	var dst map[string]interface{}
+	decoder := json.NewDecoder(r)
+	decoder.UseNumber()
+	err := decoder.Decode(&dst)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	if dst == nil {
+		dst = make(map[string]interface{})
+	}
+	return dst, nil
+}
+
+func decodeTOML(r io.Reader) (map[string]interface{}, error) {
+	dst := make(map[string]interface{})
+	if _, err := toml.NewDecoder(r).Decode(&dst); err != nil {
+		return nil, err
+	}
+	return normalizeTOML(dst).(map[string]interface{}), nil
+}
+
+// normalizeTOML 表数组解码为 []map[string]interface{}, 统一转换为 []interface{} 以便按切片合并
+func normalizeTOML(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for k, item := range value {
+			value[k] = normalizeTOML(item)
+		}
+		return value
+	case []map[string]interface{}:
+		items := make([]interface{}, 0, len(value))
+		for _, item := range value {
+			items = append(items, normalizeTOML(item))
+		}
+		return items
+	case []interface{}:
+		for i, item := range value {
+			value[i] = normalizeTOML(item)
+		}
+		return value
+	default:
+		return v
+	}
+}
+
+// decodeHCL 使用 HCL v1 语法, 块和对象解码为 map, 同名的多个块解码为列表
+//
+//	mysql {
+//	  host = "127.0.0.1"
+//	}
+//	servers = [{ host = "10.0.0.1" }]
+//
+// 只有一个元素的列表需要使用 [...] 写法, 单个块会被当作对象
+func decodeHCL(r io.Reader) (map[string]interface{}, error) {
+	bs, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	dst := make(map[string]interface{})
+	if err := hcl.Unmarshal(bs, &dst); err != nil {
+		return nil, err
+	}
+	return normalizeHCL(dst).(map[string]interface{}), nil
+}
+
+// normalizeHCL 块和对象都解码为 []map[string]interface{}, 单个的转换为 map, 多个的转换为 []interface{}
+func normalizeHCL(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for k, item := range value {
+			value[k] = normalizeHCL(item)
+		}
+		return value
+	case []map[string]interface{}:
+		if len(value) == 1 {
+			return normalizeHCL(value[0])
+		}
+		items := make([]interface{}, 0, len(value))
+		for _, item := range value {
+			items = append(items, normalizeHCL(item))
+		}
+		return items
+	case []interface{}:
+		for i, item := range value {
+			value[i] = normalizeHCL(item)
+		}
+		return value
+	default:
+		return v
+	}
+}
@@ -0,0 +1,241 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/opendevops-cn/codo-golang-sdk/config/testdata"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestLoadConfigMultiYaml(t *testing.T) {
+	type mysqlConfig struct {
+		Host string `json:"host"`
+		Port int    `json:"port"`
+	}
+	type testConfig struct {
+		Name    string      `json:"name"`
+		Level   string      `json:"level"`
+		Hosts   []string    `json:"hosts" merge:"append"`
+		Plugins []string    `json:"plugins"`
+		MySQL   mysqlConfig `json:"mysql"`
+	}
+
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"common.yaml":         "level: debug\nhosts: [a]\n",
+		"base.yaml":           "include: common.yaml\nname: base\nhosts: [b]\nplugins: [p1, p2]\nmysql:\n  host: 127.0.0.1\n  port: 3306\n",
+		"base.prod.yaml":      "level: info\nmysql:\n  host: 10.0.0.1\n",
+		"conf.d/01.yaml":      "hosts: [c]\nplugins: [p3]\n",
+		"conf.d/02.yaml":      "name: local\n",
+		"conf.d/02.prod.yaml": "mysql:\n  port: 3307\n",
+	})
+	t.Setenv(ProfileEnv, "prod")
+
+	var dst testConfig
+	err := LoadConfig(&dst,
+		WithYaml(filepath.Join(dir, "base.yaml"), filepath.Join(dir, "conf.d", "*.yaml")),
+		WithEnv("TEST_MULTI_YAML"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := testConfig{
+		Name:    "local",
+		Level:   "info",
+		Hosts:   []string{"a", "b", "c"},
+		Plugins: []string{"p3"},
+		MySQL:   mysqlConfig{Host: "10.0.0.1", Port: 3307},
+	}
+	if !reflect.DeepEqual(dst, want) {
+		t.Errorf("got====%+v\nwant====%+v", dst, want)
+	}
+}
+
+func TestLoadConfigGlobProfiles(t *testing.T) {
+	type testConfig struct {
+		Name  string `json:"name"`
+		Level string `json:"level"`
+		Port  int    `json:"port"`
+	}
+
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"conf.d/01.yaml":      "name: base\nport: 80\n",
+		"conf.d/01.dev.yaml":  "level: debug\n",
+		"conf.d/01.prod.yaml": "name: prod\n",
+		"conf.d/02.yaml":      "name: local\nport: 8080\n",
+	})
+	t.Setenv("TEST_GLOB_PROFILE", "prod")
+
+	var dst testConfig
+	source := NewFileSource(filepath.Join(dir, "conf.d", "*.yaml"), WithFileProfileEnv("TEST_GLOB_PROFILE"))
+	if err := LoadConfig(&dst, WithSource(source)); err != nil {
+		t.Fatal(err)
+	}
+	// 01.dev.yaml 不属于当前 profile, 01.prod.yaml 在 02.yaml 之后加载
+	want := testConfig{Name: "prod", Port: 8080}
+	if !reflect.DeepEqual(dst, want) {
+		t.Errorf("got====%+v\nwant====%+v", dst, want)
+	}
+}
+
+func TestLoadConfigIncludeCycle(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"a.yaml": "include: b.yaml\n",
+		"b.yaml": "include: [a.yaml]\n",
+	})
+
+	var dst struct{}
+	if err := LoadConfig(&dst, WithYaml(filepath.Join(dir, "a.yaml"))); err == nil {
+		t.Error("include cycle should fail")
+	}
+}
+
+func TestLoadConfigMultiYamlProto(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"override.yaml": "PROMETHEUS:\n  ADDR: 0.0.0.0:9092\n",
+	})
+
+	var cfg testdata.Bootstrap
+	err := LoadConfig(&cfg,
+		WithYaml("testdata/config.yaml", filepath.Join(dir, "override.yaml")),
+		WithEnv("TEST_MULTI_YAML_PROTO"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.PROMETHEUS.ADDR != "0.0.0.0:9092" || !cfg.PROMETHEUS.ENABLED || cfg.APP.NAME != "codo-kubernetes" {
+		t.Errorf("proto config====%v", &cfg)
+	}
+}
+
+func TestLoadConfigFileFormats(t *testing.T) {
+	type serverConfig struct {
+		Host string `json:"host"`
+		Port int    `json:"port"`
+	}
+	type testConfig struct {
+		Name    string         `json:"name"`
+		ID      int64          `json:"id"`
+		Servers []serverConfig `json:"servers" merge:"append"`
+		Token   string         `json:"token"`
+		Debug   bool           `json:"debug"`
+	}
+
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"app.toml": "name = \"from-toml\"\n\n[[servers]]\nhost = \"10.0.0.1\"\nport = 80\n",
+		"app.json": `{"id": 9007199254740993, "servers": [{"host": "10.0.0.2", "port": 81}]}`,
+		".env":     "# comment\nexport TEST_FORMATS_TOKEN=\"a b\\tc\"\nTEST_FORMATS_DEBUG=true # inline\nTEST_FORMATS_NAME='from-dotenv'\n",
+	})
+	t.Setenv("TEST_FORMATS_NAME", "from-env")
+
+	var dst testConfig
+	err := LoadConfig(&dst,
+		WithTOMLFile(filepath.Join(dir, "app.toml")),
+		WithJSONFile(filepath.Join(dir, "app.json")),
+		WithEnv("TEST_FORMATS"),
+		WithDotEnv(filepath.Join(dir, ".env")),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := testConfig{
+		Name:    "from-env",
+		ID:      9007199254740993,
+		Servers: []serverConfig{{Host: "10.0.0.1", Port: 80}, {Host: "10.0.0.2", Port: 81}},
+		Token:   "a b\tc",
+		Debug:   true,
+	}
+	if !reflect.DeepEqual(dst, want) {
+		t.Errorf("got====%+v\nwant====%+v", dst, want)
+	}
+	if _, ok := os.LookupEnv("TEST_FORMATS_DEBUG"); ok {
+		t.Error("dotenv should not modify os env")
+	}
+
+	var cfg testdata.Bootstrap
+	writeFiles(t, dir, map[string]string{
+		"bootstrap.toml": "[APP]\nNAME = \"from-toml\"\nTIMEOUT = \"3s\"\n\n[PROMETHEUS]\nENABLED = true\n",
+	})
+	err = LoadConfig(&cfg, WithTOMLFile(filepath.Join(dir, "bootstrap.toml")), WithEnv("TEST_FORMATS_PROTO"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.APP.NAME != "from-toml" || cfg.APP.TIMEOUT.AsDuration() != time.Second*3 || !cfg.PROMETHEUS.ENABLED {
+		t.Errorf("proto config====%v", &cfg)
+	}
+}
+
+func TestLoadConfigHCLFile(t *testing.T) {
+	type serverConfig struct {
+		Host string `json:"host"`
+		Port int    `json:"port"`
+	}
+	type testConfig struct {
+		Name    string            `json:"name"`
+		Ratio   float64           `json:"ratio"`
+		MySQL   serverConfig      `json:"mysql"`
+		Servers []serverConfig    `json:"servers"`
+		Blocks  []serverConfig    `json:"blocks"`
+		Labels  map[string]string `json:"labels"`
+	}
+
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"app.hcl": `name = "from-hcl"
+ratio = 1.5
+mysql {
+  host = "127.0.0.1"
+  port = 3306
+}
+servers = [{ host = "10.0.0.1", port = 80 }]
+blocks { host = "a" }
+blocks { host = "b" }
+labels = { env = "prod" }
+`,
+		"bootstrap.hcl": "APP {\n  NAME = \"from-hcl\"\n  TIMEOUT = \"3s\"\n}\nPROMETHEUS {\n  ENABLED = true\n}\n",
+	})
+
+	var dst testConfig
+	if err := LoadConfig(&dst, WithHCLFile(filepath.Join(dir, "app.hcl"))); err != nil {
+		t.Fatal(err)
+	}
+	want := testConfig{
+		Name:    "from-hcl",
+		Ratio:   1.5,
+		MySQL:   serverConfig{Host: "127.0.0.1", Port: 3306},
+		Servers: []serverConfig{{Host: "10.0.0.1", Port: 80}},
+		Blocks:  []serverConfig{{Host: "a"}, {Host: "b"}},
+		Labels:  map[string]string{"env": "prod"},
+	}
+	if !reflect.DeepEqual(dst, want) {
+		t.Errorf("got====%+v\nwant====%+v", dst, want)
+	}
+
+	var cfg testdata.Bootstrap
+	if err := LoadConfig(&cfg, WithHCLFile(filepath.Join(dir, "bootstrap.hcl"))); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.APP.NAME != "from-hcl" || cfg.APP.TIMEOUT.AsDuration() != time.Second*3 || !cfg.PROMETHEUS.ENABLED {
+		t.Errorf("proto config====%v", &cfg)
+	}
+}
@@ -0,0 +1,278 @@
+package config
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"reflect"
+
+	"github.com/spf13/pflag"
+	"google.golang.org/protobuf/proto"
+)
+
+var (
+	_ structSource = (*envSource)(nil)
+	_ structSource = (*flagSource)(nil)
+	_ structSource = (*pflagSource)(nil)
+)
+
+// structSource 需要按目标类型解析的配置源, 例如环境变量和命令行参数, 直接写入 dst
+// record 不为 nil 时记录被设置的字段路径, 见 WithProvenance
+type structSource interface {
+	Source
+	apply(dst interface{}, record func(path string)) error
+}
+
+// NewEnvSource 环境变量配置源, 格式见 LoadEnv, dotEnvFiles 同 WithDotEnv
+// 和其他配置源一起通过 WithSource 添加, 按添加顺序决定优先级, 例如放在 WithYaml 之前时文件中的配置优先
+// 添加后不再加载 WithEnv 默认追加在最后的环境变量层, 只能通过 LoadConfig/Watch 加载
+func NewEnvSource(prefix string, dotEnvFiles ...string) Source {
+	return &envSource{prefix: prefix, dotEnvFiles: dotEnvFiles}
+}
+
+// NewFlagSource 命令行参数配置源, 和 NewEnvSource 一样通过 WithSource 决定优先级
+func NewFlagSource(flagSet *flag.FlagSet, args []string) Source {
+	return &flagSource{flagSet: flagSet, args: args}
+}
+
+// NewPFlagSource 同 NewFlagSource
+func NewPFlagSource(flagSet *pflag.FlagSet, args []string) Source {
+	return &pflagSource{flagSet: flagSet, args: args}
+}
+
+type envSource struct {
+	prefix      string
+	dotEnvFiles []string
+}
+
+func (x *envSource) String() string {
+	return OriginEnv
+}
+
+func (x *envSource) Load(context.Context) (map[string]interface{}, error) {
+	return nil, errStructSource(x)
+}
+
+func (x *envSource) apply(dst interface{}, record func(path string)) error {
+	lookup, err := newEnvLookup(x.dotEnvFiles)
+	if err != nil {
+		return err
+	}
+	_, err = parseEnv(x.prefix, "", reflect.ValueOf(dst), envParser{lookup: lookup, record: record})
+	return err
+}
+
+type flagSource struct {
+	flagSet *flag.FlagSet
+	args    []string
+	// appFlags 加载前 flagSet 中业务自己注册的 flag, Watch 重新加载时需要带上
+	appFlags []appFlag
+}
+
+func (x *flagSource) String() string {
+	return OriginFlag
+}
+
+func (x *flagSource) Load(context.Context) (map[string]interface{}, error) {
+	return nil, errStructSource(x)
+}
+
+func (x *flagSource) apply(dst interface{}, record func(path string)) error {
+	return loadFlag(x.flagSet, x.args, dst, record)
+}
+
+// snapshot 记录加载前 FlagSet 中业务自己注册的 flag, 例如 -conf
+func (x *flagSource) snapshot() {
+	x.appFlags = nil
+	x.flagSet.VisitAll(func(f *flag.Flag) {
+		boolFlag, _ := f.Value.(interface{ IsBoolFlag() bool })
+		x.appFlags = append(x.appFlags, appFlag{
+			name:     f.Name,
+			usage:    f.Usage,
+			defValue: f.DefValue,
+			boolFlag: boolFlag != nil && boolFlag.IsBoolFlag(),
+		})
+	})
+}
+
+// fork 返回使用全新 FlagSet 的副本
+// 同一个 FlagSet 不能重复注册同名 flag, 所以重新加载时需要一份新的,
+// 业务自己注册的 flag 需要注册为 discardFlag, 否则解析原来的参数时会报 flag provided but not defined
+func (x *flagSource) fork() *flagSource {
+	flagSet := flag.NewFlagSet(x.flagSet.Name(), flag.ContinueOnError)
+	flagSet.SetOutput(io.Discard)
+	for _, f := range x.appFlags {
+		flagSet.Var(f.discard(), f.name, f.usage)
+	}
+	return &flagSource{flagSet: flagSet, args: x.args, appFlags: x.appFlags}
+}
+
+type pflagSource struct {
+	flagSet  *pflag.FlagSet
+	args     []string
+	appFlags []appFlag
+}
+
+func (x *pflagSource) String() string {
+	return OriginPFlag
+}
+
+func (x *pflagSource) Load(context.Context) (map[string]interface{}, error) {
+	return nil, errStructSource(x)
+}
+
+func (x *pflagSource) apply(dst interface{}, record func(path string)) error {
+	return loadPFlag(x.flagSet, x.args, dst, record)
+}
+
+func (x *pflagSource) snapshot() {
+	x.appFlags = nil
+	x.flagSet.VisitAll(func(f *pflag.Flag) {
+		x.appFlags = append(x.appFlags, appFlag{
+			name:        f.Name,
+			shorthand:   f.Shorthand,
+			usage:       f.Usage,
+			defValue:    f.DefValue,
+			noOptDefVal: f.NoOptDefVal,
+			typ:         f.Value.Type(),
+		})
+	})
+}
+
+func (x *pflagSource) fork() *pflagSource {
+	flagSet := pflag.NewFlagSet("", pflag.ContinueOnError)
+	flagSet.SetOutput(io.Discard)
+	flagSet.ParseErrorsWhitelist = x.flagSet.ParseErrorsWhitelist
+	for _, f := range x.appFlags {
+		flagSet.VarPF(f.discard(), f.name, f.shorthand, f.usage).NoOptDefVal = f.noOptDefVal
+	}
+	return &pflagSource{flagSet: flagSet, args: x.args, appFlags: x.appFlags}
+}
+
+// appFlag 业务自己注册的 flag, 只记录注册信息, 不持有业务的变量
+type appFlag struct {
+	name        string
+	shorthand   string
+	usage       string
+	defValue    string
+	noOptDefVal string
+	typ         string
+	boolFlag    bool
+}
+
+func (x appFlag) discard() *discardFlag {
+	return &discardFlag{value: x.defValue, typ: x.typ, boolFlag: x.boolFlag}
+}
+
+// discardFlag 重新加载时代替业务自己注册的 flag, 只用于让参数能够解析,
+// 不会写入业务的变量, 避免和业务并发读写, 也不会覆盖业务在启动后修改的值
+type discardFlag struct {
+	value    string
+	typ      string
+	boolFlag bool
+}
+
+func (x *discardFlag) String() string {
+	return x.value
+}
+
+func (x *discardFlag) Set(value string) error {
+	x.value = value
+	return nil
+}
+
+// Type 实现 pflag.Value
+func (x *discardFlag) Type() string {
+	return x.typ
+}
+
+// IsBoolFlag 标准库 flag 的布尔参数可以不带值
+func (x *discardFlag) IsBoolFlag() bool {
+	return x.boolFlag
+}
+
+func errStructSource(source Source) error {
+	return fmt.Errorf("config source %s can only be loaded by LoadConfig or Watch", source)
+}
+
+// layers 返回按优先级从低到高排列的配置源
+// WithEnv/WithFlag/WithPFlag 追加在 WithSource 添加的配置源之后, 通过 WithSource 添加了 NewEnvSource 时不再追加 WithEnv
+func (c ConfigOptions) layers() []Source {
+	layers := append([]Source{}, c.sources...)
+	if c.loadFromEnv && c.lastEnvSource() == nil {
+		layers = append(layers, &envSource{prefix: c.envPrefix, dotEnvFiles: c.dotEnvFiles})
+	}
+	if c.flag != nil {
+		layers = append(layers, c.flag)
+	}
+	if c.pflag != nil {
+		layers = append(layers, c.pflag)
+	}
+	return layers
+}
+
+// lastEnvSource 返回通过 WithSource 添加的最后一个环境变量配置源
+func (c ConfigOptions) lastEnvSource() *envSource {
+	var result *envSource
+	for _, source := range c.sources {
+		if env, ok := source.(*envSource); ok {
+			result = env
+		}
+	}
+	return result
+}
+
+// applyLayers 按优先级依次加载, 相邻的 map 配置源先合并再一起反序列化, 环境变量和命令行参数直接写入 dst
+// escapeRemote 见 loadOptions
+func applyLayers(ctx context.Context, dst interface{}, layers []Source, recorder *provenanceRecorder, escapeRemote bool) error {
+	var applied bool
+	for i := 0; i < len(layers); {
+		if source, ok := layers[i].(structSource); ok {
+			if err := source.apply(dst, recorder.recordFunc(sourceName(source))); err != nil {
+				return err
+			}
+			applied = true
+			i++
+			continue
+		}
+
+		j := i + 1
+		for j < len(layers) {
+			if _, ok := layers[j].(structSource); ok {
+				break
+			}
+			j++
+		}
+		opts := loadOptions{overlay: applied, escapeRemote: escapeRemote}
+		if err := loadSourceGroup(ctx, dst, layers[i:j], recorder, opts); err != nil {
+			return err
+		}
+		i = j
+	}
+	return nil
+}
+
+// loadOptions 加载 map 配置源的选项
+type loadOptions struct {
+	// overlay 为 true 时 dst 已经被环境变量或命令行参数写入过, proto.Message 不能再被整体重置, 只覆盖配置源中出现的字段
+	overlay bool
+	// escapeRemote 为 true 时转义远程配置源中的 ${env:}/${file:} 引用, 见 WithSecretResolution
+	escapeRemote bool
+}
+
+// loadSourceGroup 合并一组相邻的配置源并反序列化到 dst
+func loadSourceGroup(ctx context.Context, dst interface{}, sources []Source, recorder *provenanceRecorder, opts loadOptions) error {
+	merged, err := loadSources(ctx, dst, sources, recorder, opts)
+	if err != nil {
+		return err
+	}
+	_, isProto := dst.(proto.Message)
+	reset := isProto && !opts.overlay
+	recorder.recordSources(dst, reset)
+	if reset {
+		// proto.Message 在反序列化时会被整体重置, 为配置源中没有出现的字段重新填充默认值
+		return applyDefaultsTo(dst, merged, recorder.recordFunc(OriginDefault))
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package config
+
+import (
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/opendevops-cn/codo-golang-sdk/config/testdata"
+)
+
+func TestLoadConfigLayerOrder(t *testing.T) {
+	type testConfig struct {
+		Name  string `json:"name" flag:"name"`
+		Port  int    `json:"port" flag:"port"`
+		Debug bool   `json:"debug"`
+	}
+
+	path := filepath.Join(t.TempDir(), "app.yaml")
+	if err := os.WriteFile(path, []byte("name: from-yaml\nport: 8000\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TEST_LAYER_NAME", "from-env")
+	t.Setenv("TEST_LAYER_DEBUG", "true")
+
+	// 默认环境变量覆盖文件
+	var dst testConfig
+	if err := LoadConfig(&dst, WithYaml(path), WithEnv("TEST_LAYER")); err != nil {
+		t.Fatal(err)
+	}
+	if dst.Name != "from-env" || dst.Port != 8000 || !dst.Debug {
+		t.Errorf("default order====%+v", dst)
+	}
+
+	// 环境变量放在文件之前时文件优先, 命令行参数放在最前时优先级最低
+	dst = testConfig{}
+	provenance := NewProvenance()
+	err := LoadConfig(&dst,
+		WithSource(
+			NewFlagSource(flag.NewFlagSet("test", flag.ContinueOnError), []string{"-port", "9000", "-name", "from-flag"}),
+			NewEnvSource("TEST_LAYER"),
+			NewFileSource(path),
+		),
+		WithProvenance(provenance),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dst.Name != "from-yaml" || dst.Port != 8000 || !dst.Debug {
+		t.Errorf("custom order====%+v", dst)
+	}
+	if got := provenance.Explain(); got["name"] != "file:"+path || got["debug"] != OriginEnv {
+		t.Errorf("origins====%v", got)
+	}
+
+	if _, err := NewEnvSource("TEST_LAYER").Load(context.Background()); err == nil {
+		t.Error("env source should only be loaded by LoadConfig")
+	}
+}
+
+func TestLoadConfigProtoLayerOrder(t *testing.T) {
+	t.Setenv("CODO_APP_NAME", "from-env")
+	t.Setenv("CODO_APP_ADDR", "0.0.0.0:9000")
+	t.Setenv("CODO_PPROF_ENABLE", "true")
+
+	path := filepath.Join(t.TempDir(), "app.yaml")
+	if err := os.WriteFile(path, []byte("APP:\n  NAME: from-yaml\nPPROF:\n  ENABLE: false\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// proto.Message 在环境变量之后加载文件时不能被整体重置, 文件中显式配置的零值同样覆盖环境变量
+	var cfg testdata.Bootstrap
+	if err := LoadConfig(&cfg, WithSource(NewEnvSource("TEST_PROTO_LAYER"), NewFileSource(path))); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.APP.NAME != "from-yaml" || cfg.APP.ADDR != "0.0.0.0:9000" || cfg.PPROF.ENABLE {
+		t.Errorf("app====%v, pprof====%v", cfg.APP, cfg.PPROF)
+	}
+}
+
+func TestWatchReloadFlagSource(t *testing.T) {
+	type appConfig struct {
+		Name string `json:"name" flag:"name"`
+	}
+
+	path := filepath.Join(t.TempDir(), "app.yaml")
+	if err := os.WriteFile(path, []byte("name: v1\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	flagSet := flag.NewFlagSet("app", flag.ContinueOnError)
+	conf := flagSet.String("conf", "", "config file")
+	w, err := Watch[appConfig](
+		WithSource(NewFlagSource(flagSet, []string{"-conf", path, "-name", "from-flag"}), NewFileSource(path)),
+		WithWatchInterval(time.Hour),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	if got := w.Load(); got.Name != "v1" {
+		t.Fatalf("config====%+v", got)
+	}
+	if err := os.WriteFile(path, []byte("name: v2\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if got := w.Load(); got.Name != "v2" || *conf != path {
+		t.Errorf("config====%+v conf====%s", got, *conf)
+	}
+}
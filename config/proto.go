@@ -0,0 +1,528 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// proto.Message 按 protoreflect 描述加载 env/flag, 而不是遍历生成代码中的 state/sizeCache 等内部字段
+// 环境变量名为 PREFIX_字段名(大写), flag 名为小写的字段名, 嵌套消息以 . 连接, 例如 --app.name,
+// 生成代码中的 env/flag/usage tag 仍然有效
+//
+// 除标量外还支持:
+//
+//	google.protobuf.Duration   "30s"
+//	google.protobuf.Timestamp  "2006-01-02T15:04:05Z"
+//	google.protobuf.XxxValue   按包装的类型解析
+//	enum                       名称或数字
+//	repeated                   "a,b", 消息列表的环境变量按下标展开, 例如 PREFIX_SERVERS_0_HOST
+//	map                        "K=V,K2=V2"
+const (
+	protoDurationName  protoreflect.FullName = "google.protobuf.Duration"
+	protoTimestampName protoreflect.FullName = "google.protobuf.Timestamp"
+)
+
+var protoWrapperNames = map[protoreflect.FullName]bool{
+	"google.protobuf.DoubleValue": true,
+	"google.protobuf.FloatValue":  true,
+	"google.protobuf.Int64Value":  true,
+	"google.protobuf.UInt64Value": true,
+	"google.protobuf.Int32Value":  true,
+	"google.protobuf.UInt32Value": true,
+	"google.protobuf.BoolValue":   true,
+	"google.protobuf.StringValue": true,
+	"google.protobuf.BytesValue":  true,
+}
+
+// asProtoMessage v 为指向 proto.Message 的非 nil 指针时返回其 protoreflect.Message
+func asProtoMessage(v reflect.Value) (protoreflect.Message, bool) {
+	if !v.CanInterface() {
+		return nil, false
+	}
+	msg, ok := v.Interface().(proto.Message)
+	if !ok {
+		return nil, false
+	}
+	return msg.ProtoReflect(), true
+}
+
+// isProtoValueMessage 判断消息能否整体从字符串解析
+func isProtoValueMessage(md protoreflect.MessageDescriptor) bool {
+	name := md.FullName()
+	return name == protoDurationName || name == protoTimestampName || protoWrapperNames[name]
+}
+
+// isProtoNestedField 判断字段是否需要展开为嵌套的 env/flag
+func isProtoNestedField(fd protoreflect.FieldDescriptor) bool {
+	return fd.Message() != nil && !fd.IsMap() && !isProtoValueMessage(fd.Message())
+}
+
+// protoStructField 查找字段在生成代码中对应的结构体字段, 用于读取 env/flag/usage tag
+// oneof 字段和动态消息没有对应的结构体字段
+func protoStructField(msg protoreflect.Message, fd protoreflect.FieldDescriptor) (reflect.StructField, bool) {
+	t := reflect.TypeOf(msg.Interface())
+	if t.Kind() != reflect.Pointer || t.Elem().Kind() != reflect.Struct {
+		return reflect.StructField{}, false
+	}
+	t = t.Elem()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		for _, item := range strings.Split(field.Tag.Get("protobuf"), ",") {
+			if item == "name="+string(fd.Name()) {
+				return field, true
+			}
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// joinProtoPath 生成代码中字段的 json tag 为 proto 字段名, 和 joinFieldPath 生成的路径一致
+func joinProtoPath(path string, fd protoreflect.FieldDescriptor) string {
+	if path == "" {
+		return string(fd.Name())
+	}
+	return path + "." + string(fd.Name())
+}
+
+// overlayProto 将 src 中出现在 present 里的字段覆盖到 dst, 显式配置的零值同样会覆盖
+// 嵌套消息递归覆盖, 列表、map 和 Duration 等值类型的消息整体替换
+func overlayProto(dst, src protoreflect.Message, present map[string]interface{}) {
+	fields := src.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		item, ok := lookupProtoKey(present, fd)
+		if !ok {
+			continue
+		}
+		if sub, isMap := item.(map[string]interface{}); isMap && !fd.IsList() && isProtoNestedField(fd) && src.Has(fd) {
+			overlayProto(dst.Mutable(fd).Message(), src.Get(fd).Message(), sub)
+			continue
+		}
+		if src.Has(fd) {
+			dst.Set(fd, src.Get(fd))
+		} else {
+			dst.Clear(fd)
+		}
+	}
+}
+
+// lookupProtoKey 同 lookupKey, 按 proto 字段名或 json 名称查找, 忽略大小写
+func lookupProtoKey(m map[string]interface{}, fd protoreflect.FieldDescriptor) (interface{}, bool) {
+	for k, v := range m {
+		if strings.EqualFold(k, string(fd.Name())) || strings.EqualFold(k, fd.JSONName()) {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+// visiting 记录当前路径上的消息类型, 防止 google.protobuf.Value 等自引用的消息无限展开
+func parseProtoEnv(prefix, path string, msg protoreflect.Message, env envParser, visiting map[protoreflect.MessageDescriptor]bool) (bool, error) {
+	md := msg.Descriptor()
+	if visiting[md] {
+		return false, nil
+	}
+	visiting[md] = true
+	defer delete(visiting, md)
+
+	var isSetFields bool
+	fields := md.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		envKeyPrefix := strings.ToUpper(string(fd.Name()))
+		if prefix != "" {
+			envKeyPrefix = prefix + "_" + envKeyPrefix
+		}
+		envKey := envKeyPrefix
+		if field, ok := protoStructField(msg, fd); ok && field.Tag.Get("env") != "" {
+			envKey = field.Tag.Get("env")
+		}
+
+		ok, err := parseProtoEnvField(envKeyPrefix, envKey, joinProtoPath(path, fd), msg, fd, env, visiting)
+		if err != nil {
+			return false, err
+		}
+		if ok {
+			isSetFields = true
+		}
+	}
+	return isSetFields, nil
+}
+
+// parseProtoEnvField 解析单个字段, 未设置的嵌套消息只有在设置了环境变量时才会分配
+func parseProtoEnvField(envKeyPrefix, envKey, path string, msg protoreflect.Message, fd protoreflect.FieldDescriptor, env envParser, visiting map[protoreflect.MessageDescriptor]bool) (bool, error) {
+	switch {
+	case fd.IsList() && isProtoNestedField(fd):
+		return parseProtoEnvList(envKeyPrefix, path, msg, fd, env, visiting)
+	case !fd.IsList() && isProtoNestedField(fd):
+		has := msg.Has(fd)
+		sub := msg.NewField(fd).Message()
+		if has {
+			sub = msg.Mutable(fd).Message()
+		}
+		ok, err := parseProtoEnv(envKeyPrefix, path, sub, env, visiting)
+		if ok && !has {
+			msg.Set(fd, protoreflect.ValueOfMessage(sub))
+		}
+		return ok, err
+	default:
+		envValue := env.lookup(envKey)
+		if envValue == "" {
+			return false, nil
+		}
+		if err := setProtoField(msg, fd, envKey, envValue); err != nil {
+			return false, err
+		}
+		if env.record != nil {
+			env.record(path)
+		}
+		return true, nil
+	}
+}
+
+// parseProtoEnvList 和 parseEnvStructSlice 一样按 PREFIX_0_FIELD 的格式解析消息列表
+func parseProtoEnvList(envKeyPrefix, path string, msg protoreflect.Message, fd protoreflect.FieldDescriptor, env envParser, visiting map[protoreflect.MessageDescriptor]bool) (bool, error) {
+	current := msg.Get(fd).List()
+	list := msg.NewField(fd).List()
+
+	var isSetFields bool
+	for i := 0; ; i++ {
+		var item protoreflect.Message
+		if i < current.Len() {
+			item = current.Get(i).Message()
+		} else {
+			item = list.NewElement().Message()
+		}
+		ok, err := parseProtoEnv(fmt.Sprintf("%s_%d", envKeyPrefix, i), fmt.Sprintf("%s[%d]", path, i), item, env, visiting)
+		if err != nil {
+			return false, err
+		}
+		if !ok && i >= current.Len() {
+			break
+		}
+		if ok {
+			isSetFields = true
+		}
+		list.Append(protoreflect.ValueOfMessage(item))
+	}
+
+	if isSetFields {
+		msg.Set(fd, protoreflect.ValueOfList(list))
+	}
+	return isSetFields, nil
+}
+
+// setProtoField 将字符串解析后写入字段, repeated 和 map 字段整体替换
+func setProtoField(msg protoreflect.Message, fd protoreflect.FieldDescriptor, key string, s string) error {
+	switch {
+	case fd.IsList():
+		list := msg.NewField(fd).List()
+		for _, item := range strings.Split(s, ",") {
+			value, err := parseProtoValue(fd, key, strings.TrimSpace(item), list.NewElement)
+			if err != nil {
+				return err
+			}
+			list.Append(value)
+		}
+		msg.Set(fd, protoreflect.ValueOfList(list))
+	case fd.IsMap():
+		m := msg.NewField(fd).Map()
+		for _, pair := range strings.Split(s, ",") {
+			k, v, ok := strings.Cut(pair, "=")
+			if !ok {
+				return fmt.Errorf("invalid map value for %s: %s", key, s)
+			}
+			mapKey, err := parseProtoValue(fd.MapKey(), key, strings.TrimSpace(k), nil)
+			if err != nil {
+				return err
+			}
+			mapValue, err := parseProtoValue(fd.MapValue(), key, strings.TrimSpace(v), m.NewValue)
+			if err != nil {
+				return err
+			}
+			m.Set(mapKey.MapKey(), mapValue)
+		}
+		msg.Set(fd, protoreflect.ValueOfMap(m))
+	default:
+		value, err := parseProtoValue(fd, key, s, func() protoreflect.Value {
+			return msg.NewField(fd)
+		})
+		if err != nil {
+			return err
+		}
+		msg.Set(fd, value)
+	}
+	return nil
+}
+
+// parseProtoValue 解析单个值, 消息类型通过 newValue 创建
+func parseProtoValue(fd protoreflect.FieldDescriptor, key string, s string, newValue func() protoreflect.Value) (protoreflect.Value, error) {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return protoreflect.Value{}, fmt.Errorf("invalid bool value for %s: %s", key, s)
+		}
+		return protoreflect.ValueOfBool(b), nil
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		i64, err := strconv.ParseInt(s, 10, 32)
+		if err != nil {
+			return protoreflect.Value{}, fmt.Errorf("invalid integer value for %s: %s", key, s)
+		}
+		return protoreflect.ValueOfInt32(int32(i64)), nil
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		i64, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return protoreflect.Value{}, fmt.Errorf("invalid integer value for %s: %s", key, s)
+		}
+		return protoreflect.ValueOfInt64(i64), nil
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		u64, err := strconv.ParseUint(s, 10, 32)
+		if err != nil {
+			return protoreflect.Value{}, fmt.Errorf("invalid unsigned integer value for %s: %s", key, s)
+		}
+		return protoreflect.ValueOfUint32(uint32(u64)), nil
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		u64, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return protoreflect.Value{}, fmt.Errorf("invalid unsigned integer value for %s: %s", key, s)
+		}
+		return protoreflect.ValueOfUint64(u64), nil
+	case protoreflect.FloatKind:
+		f64, err := strconv.ParseFloat(s, 32)
+		if err != nil {
+			return protoreflect.Value{}, fmt.Errorf("invalid float value for %s: %s", key, s)
+		}
+		return protoreflect.ValueOfFloat32(float32(f64)), nil
+	case protoreflect.DoubleKind:
+		f64, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return protoreflect.Value{}, fmt.Errorf("invalid float value for %s: %s", key, s)
+		}
+		return protoreflect.ValueOfFloat64(f64), nil
+	case protoreflect.StringKind:
+		return protoreflect.ValueOfString(s), nil
+	case protoreflect.BytesKind:
+		return protoreflect.ValueOfBytes([]byte(s)), nil
+	case protoreflect.EnumKind:
+		if ev := fd.Enum().Values().ByName(protoreflect.Name(s)); ev != nil {
+			return protoreflect.ValueOfEnum(ev.Number()), nil
+		}
+		i64, err := strconv.ParseInt(s, 10, 32)
+		if err != nil {
+			return protoreflect.Value{}, fmt.Errorf("invalid enum value for %s: %s", key, s)
+		}
+		return protoreflect.ValueOfEnum(protoreflect.EnumNumber(i64)), nil
+	default:
+		if newValue == nil || !isProtoValueMessage(fd.Message()) {
+			return protoreflect.Value{}, fmt.Errorf("unsupported type %s for %s", fd.FullName(), key)
+		}
+		value := newValue()
+		if err := setProtoMessageValue(value.Message(), key, s); err != nil {
+			return protoreflect.Value{}, err
+		}
+		return value, nil
+	}
+}
+
+// setProtoMessageValue 解析 Duration/Timestamp/wrappers
+func setProtoMessageValue(msg protoreflect.Message, key string, s string) error {
+	fields := msg.Descriptor().Fields()
+	switch msg.Descriptor().FullName() {
+	case protoDurationName:
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return fmt.Errorf("invalid duration value for %s: %s", key, s)
+		}
+		msg.Set(fields.ByName("seconds"), protoreflect.ValueOfInt64(int64(d/time.Second)))
+		msg.Set(fields.ByName("nanos"), protoreflect.ValueOfInt32(int32(d%time.Second)))
+	case protoTimestampName:
+		t, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return fmt.Errorf("invalid timestamp value for %s: %s", key, s)
+		}
+		msg.Set(fields.ByName("seconds"), protoreflect.ValueOfInt64(t.Unix()))
+		msg.Set(fields.ByName("nanos"), protoreflect.ValueOfInt32(int32(t.Nanosecond())))
+	default:
+		fd := fields.ByName("value")
+		value, err := parseProtoValue(fd, key, s, nil)
+		if err != nil {
+			return err
+		}
+		msg.Set(fd, value)
+	}
+	return nil
+}
+
+// formatProtoValue 格式化字段值, 用于 flag 的默认值展示
+func formatProtoValue(fd protoreflect.FieldDescriptor, value protoreflect.Value) string {
+	switch {
+	case fd.IsList():
+		list := value.List()
+		items := make([]string, 0, list.Len())
+		for i := 0; i < list.Len(); i++ {
+			items = append(items, formatProtoScalar(fd, list.Get(i)))
+		}
+		return strings.Join(items, ",")
+	case fd.IsMap():
+		var items []string
+		value.Map().Range(func(k protoreflect.MapKey, v protoreflect.Value) bool {
+			items = append(items, k.String()+"="+formatProtoScalar(fd.MapValue(), v))
+			return true
+		})
+		return strings.Join(items, ",")
+	default:
+		return formatProtoScalar(fd, value)
+	}
+}
+
+func formatProtoScalar(fd protoreflect.FieldDescriptor, value protoreflect.Value) string {
+	switch fd.Kind() {
+	case protoreflect.EnumKind:
+		if ev := fd.Enum().Values().ByNumber(value.Enum()); ev != nil {
+			return string(ev.Name())
+		}
+		return strconv.Itoa(int(value.Enum()))
+	case protoreflect.BytesKind:
+		return string(value.Bytes())
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		msg := value.Message()
+		fields := msg.Descriptor().Fields()
+		switch msg.Descriptor().FullName() {
+		case protoDurationName:
+			seconds, nanos := msg.Get(fields.ByName("seconds")).Int(), msg.Get(fields.ByName("nanos")).Int()
+			return (time.Duration(seconds)*time.Second + time.Duration(nanos)).String()
+		case protoTimestampName:
+			seconds, nanos := msg.Get(fields.ByName("seconds")).Int(), msg.Get(fields.ByName("nanos")).Int()
+			return time.Unix(seconds, nanos).UTC().Format(time.RFC3339Nano)
+		default:
+			fd := fields.ByName("value")
+			if fd == nil {
+				return ""
+			}
+			return formatProtoScalar(fd, msg.Get(fd))
+		}
+	default:
+		return value.String()
+	}
+}
+
+var _ flag.Value = (*protoFlag)(nil)
+
+// protoFlag 按字段路径设置 proto 字段, 路径上未设置的消息只有在 flag 被设置时才会分配
+type protoFlag struct {
+	root protoreflect.Message
+	path []protoreflect.FieldDescriptor
+	name string
+}
+
+// fieldPath 返回 flag 对应的字段路径, prefix 为 root 在配置中的字段路径
+func (x *protoFlag) fieldPath(prefix string) string {
+	path := prefix
+	for _, fd := range x.path {
+		path = joinProtoPath(path, fd)
+	}
+	return path
+}
+
+func (x *protoFlag) String() string {
+	if x == nil || x.root == nil {
+		return ""
+	}
+	msg := x.root
+	for _, fd := range x.path[:len(x.path)-1] {
+		if !msg.Has(fd) {
+			return ""
+		}
+		msg = msg.Get(fd).Message()
+	}
+	fd := x.path[len(x.path)-1]
+	if !msg.Has(fd) {
+		return ""
+	}
+	return formatProtoValue(fd, msg.Get(fd))
+}
+
+func (x *protoFlag) Set(value string) error {
+	msg := x.root
+	for _, fd := range x.path[:len(x.path)-1] {
+		msg = msg.Mutable(fd).Message()
+	}
+	return setProtoField(msg, x.path[len(x.path)-1], x.name, value)
+}
+
+func (x *protoFlag) Type() string {
+	fd := x.path[len(x.path)-1]
+	if fd.Message() != nil {
+		return string(fd.Message().Name())
+	}
+	return fd.Kind().String()
+}
+
+func (x *protoFlag) IsBoolFlag() bool {
+	fd := x.path[len(x.path)-1]
+	return fd.Kind() == protoreflect.BoolKind && !fd.IsList()
+}
+
+// protoFlagField proto 消息中需要注册的 flag
+type protoFlagField struct {
+	value *protoFlag
+	short string
+	usage string
+}
+
+// protoFlagFields 展开 proto 消息中的 flag, 消息列表无法通过 flag 设置, 会被跳过
+// 自引用的消息只展开第一层, visiting 同 parseProtoEnv
+func protoFlagFields(prefix string, root protoreflect.Message, msg protoreflect.Message, path []protoreflect.FieldDescriptor, visiting map[protoreflect.MessageDescriptor]bool) []*protoFlagField {
+	md := msg.Descriptor()
+	if visiting[md] {
+		return nil
+	}
+	visiting[md] = true
+	defer delete(visiting, md)
+
+	var result []*protoFlagField
+	fields := md.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		fieldPath := append(append([]protoreflect.FieldDescriptor{}, path...), fd)
+
+		name, short := strings.ToLower(string(fd.Name())), ""
+		field, hasField := protoStructField(msg, fd)
+		if hasField {
+			if flagName, flagShot := parseFlagTag("", field); flagName != "" {
+				name, short = flagName, flagShot
+			}
+		}
+
+		switch {
+		case fd.IsList() && isProtoNestedField(fd):
+			continue
+		case isProtoNestedField(fd):
+			sub := msg.NewField(fd).Message()
+			if msg.Has(fd) {
+				sub = msg.Get(fd).Message()
+			}
+			result = append(result, protoFlagFields(prefix+name+".", root, sub, fieldPath, visiting)...)
+			continue
+		}
+
+		var usage string
+		if hasField {
+			usage = field.Tag.Get("usage")
+		}
+		result = append(result, &protoFlagField{
+			value: &protoFlag{root: root, path: fieldPath, name: prefix + name},
+			short: short,
+			usage: usage,
+		})
+	}
+	return result
+}
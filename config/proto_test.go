@@ -0,0 +1,103 @@
+package config
+
+import (
+	"flag"
+	"testing"
+	"time"
+
+	"github.com/opendevops-cn/codo-golang-sdk/config/testdata"
+	"github.com/spf13/pflag"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/known/apipb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/typepb"
+)
+
+func TestLoadConfigProtoEnv(t *testing.T) {
+	t.Setenv("CODO_APP_ENV", "DEV")
+	t.Setenv("CODO_APP_TIMEOUT", "1m30s")
+	t.Setenv("CODO_REDIS_PORT", "6380")
+	t.Setenv("CODO_PPROF_ADDR", "0.0.0.0:6061")
+
+	var cfg testdata.Bootstrap
+	if err := LoadEnv("TEST_PROTO", &cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.APP.ENV != testdata.AppConfig_DEV || cfg.APP.TIMEOUT.AsDuration() != time.Second*90 {
+		t.Errorf("app====%v", cfg.APP)
+	}
+	if cfg.REDIS.R_PORT != 6380 || cfg.PPROF.ADDR != "0.0.0.0:6061" {
+		t.Errorf("redis====%v, pprof====%v", cfg.REDIS, cfg.PPROF)
+	}
+	if cfg.DB != nil || cfg.OTEL != nil {
+		t.Errorf("unset messages should stay nil, db====%v, otel====%v", cfg.DB, cfg.OTEL)
+	}
+
+	t.Setenv("TEST_API_SYNTAX", "SYNTAX_PROTO3")
+	t.Setenv("TEST_API_METHODS_0_NAME", "Get")
+	t.Setenv("TEST_API_METHODS_0_REQUEST_STREAMING", "true")
+	t.Setenv("TEST_API_METHODS_1_NAME", "List")
+	var api apipb.Api
+	if err := LoadEnv("TEST_API", &api); err != nil {
+		t.Fatal(err)
+	}
+	if api.Syntax != typepb.Syntax_SYNTAX_PROTO3 || len(api.Methods) != 2 {
+		t.Fatalf("api====%v", &api)
+	}
+	if api.Methods[0].Name != "Get" || !api.Methods[0].RequestStreaming || api.Methods[1].Name != "List" {
+		t.Errorf("methods====%v", api.Methods)
+	}
+}
+
+func TestLoadConfigProtoFlag(t *testing.T) {
+	args := []string{"--app.name=from-flag", "--app.env=PRE", "--app.timeout=5s", "--redis.r_port=6381", "--pprof.enable"}
+
+	var stdFlag testdata.Bootstrap
+	if err := LoadFlag(flag.NewFlagSet("test", flag.ContinueOnError), args, &stdFlag); err != nil {
+		t.Fatal(err)
+	}
+	var pFlag testdata.Bootstrap
+	if err := LoadPFlag(pflag.NewFlagSet("ptest", pflag.ContinueOnError), args, &pFlag); err != nil {
+		t.Fatal(err)
+	}
+	for _, cfg := range []*testdata.Bootstrap{&stdFlag, &pFlag} {
+		if cfg.APP.NAME != "from-flag" || cfg.APP.ENV != testdata.AppConfig_PRE || cfg.APP.TIMEOUT.AsDuration() != time.Second*5 {
+			t.Errorf("app====%v", cfg.APP)
+		}
+		if cfg.REDIS.R_PORT != 6381 || !cfg.PPROF.ENABLE {
+			t.Errorf("redis====%v, pprof====%v", cfg.REDIS, cfg.PPROF)
+		}
+		if cfg.DB != nil {
+			t.Errorf("unset messages should stay nil, db====%v", cfg.DB)
+		}
+	}
+}
+
+func TestLoadConfigProtoRecursive(t *testing.T) {
+	t.Setenv("TEST_RECURSIVE_NAME", "Foo")
+	t.Setenv("TEST_RECURSIVE_STRING_VALUE", "bar")
+
+	// DescriptorProto.nested_type 和 Value.list_value.values 都引用了自身
+	var desc descriptorpb.DescriptorProto
+	if err := LoadEnv("TEST_RECURSIVE", &desc); err != nil {
+		t.Fatal(err)
+	}
+	if desc.GetName() != "Foo" {
+		t.Errorf("desc====%v", &desc)
+	}
+	var value structpb.Value
+	if err := LoadEnv("TEST_RECURSIVE", &value); err != nil {
+		t.Fatal(err)
+	}
+	if value.GetStringValue() != "bar" {
+		t.Errorf("value====%v", &value)
+	}
+
+	var flagDesc descriptorpb.DescriptorProto
+	if err := LoadFlag(flag.NewFlagSet("test", flag.ContinueOnError), []string{"--name=Foo", "--options.map_entry"}, &flagDesc); err != nil {
+		t.Fatal(err)
+	}
+	if flagDesc.GetName() != "Foo" || !flagDesc.GetOptions().GetMapEntry() {
+		t.Errorf("desc====%v", &flagDesc)
+	}
+}
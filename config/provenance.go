@@ -0,0 +1,346 @@
+package config
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// 字段来源, 配置源的来源为其 String() 的返回值, 例如 file:config.yaml
+const (
+	OriginDefault = "default"
+	OriginSource  = "source"
+	OriginEnv     = "env"
+	OriginFlag    = "flag"
+	OriginPFlag   = "pflag"
+)
+
+// Dump 支持的输出格式
+const (
+	DumpYAML = "yaml"
+	DumpJSON = "json"
+)
+
+const (
+	tagSecret      = "secret"
+	redactedSecret = "******"
+)
+
+// Provenance 记录每个字段最后一次被哪个来源设置, 由调用方持有, 通过 WithProvenance 传入
+// 没有提供按 dst 查询的 Explain(dst), 包级别按 dst 登记的记录会让加载过的 dst 永远无法被回收
+// 重复加载时替换为最后一次成功加载的结果, Watcher 中为当前 Load 返回的配置的来源, 可以并发读取
+type Provenance struct {
+	mu      sync.RWMutex
+	origins map[string]string
+}
+
+// NewProvenance 创建一个空的 Provenance
+func NewProvenance() *Provenance {
+	return &Provenance{}
+}
+
+// WithProvenance 加载时将字段来源记录到 p, 加载后通过 p.Explain 查询, 不使用时不做任何记录
+func WithProvenance(p *Provenance) ConfigOption {
+	return func(options *ConfigOptions) {
+		options.provenance = p
+	}
+}
+
+// Explain 返回每个字段的来源, key 为字段路径, 例如 mysql.host、servers[0].port
+// 还没有加载过时返回 nil, 没有被任何来源设置的字段不在结果中
+func (p *Provenance) Explain() map[string]string {
+	if p == nil {
+		return nil
+	}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.origins == nil {
+		return nil
+	}
+	result := make(map[string]string, len(p.origins))
+	for k, v := range p.origins {
+		result[k] = v
+	}
+	return result
+}
+
+func (p *Provenance) store(origins map[string]string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.origins = origins
+}
+
+// provenanceRecorder 在每一层加载给字段赋值时记录来源, 即使设置的值和上一层相同也归属于该层
+type provenanceRecorder struct {
+	target  *Provenance
+	origins map[string]string
+
+	sourceNames []string
+	sourcePaths []map[string]bool
+}
+
+// newProvenanceRecorder 未使用 WithProvenance 时返回 nil, nil 的 recorder 不做任何记录
+func newProvenanceRecorder(target *Provenance) *provenanceRecorder {
+	if target == nil {
+		return nil
+	}
+	return &provenanceRecorder{
+		target:  target,
+		origins: make(map[string]string),
+	}
+}
+
+// recordFunc 返回记录字段路径来源的回调, 传给 applyDefaults、parseEnv 等赋值的地方, recorder 为 nil 时返回 nil
+func (x *provenanceRecorder) recordFunc(origin string) func(path string) {
+	if x == nil {
+		return nil
+	}
+	return func(path string) {
+		x.origins[path] = origin
+	}
+}
+
+// addSource 记录配置源中出现过的字段路径, 需要在合并前调用
+func (x *provenanceRecorder) addSource(source Source, m map[string]interface{}) {
+	if x == nil {
+		return
+	}
+	paths := make(map[string]bool)
+	flattenSourcePaths("", m, paths)
+	x.sourceNames = append(x.sourceNames, sourceName(source))
+	x.sourcePaths = append(x.sourcePaths, paths)
+}
+
+// sourceName 配置源在 Explain 中的来源名称
+func sourceName(source Source) string {
+	if stringer, ok := source.(fmt.Stringer); ok {
+		return stringer.String()
+	}
+	return OriginSource
+}
+
+// recordSources 反序列化会给配置源中出现的每个字段赋值, 字段归属于最后一个包含该路径的配置源
+// reset 为 true 时 dst 在反序列化时被整体重置, 例如 proto.Message, 之前记录的来源全部失效
+func (x *provenanceRecorder) recordSources(dst interface{}, reset bool) {
+	if x == nil {
+		return
+	}
+	if reset {
+		x.origins = make(map[string]string)
+	}
+	walkConfig("", reflect.ValueOf(dst), func(path string, _ reflect.Value) {
+		lowerPath := strings.ToLower(path)
+		for i := len(x.sourcePaths) - 1; i >= 0; i-- {
+			if containsSourcePath(x.sourcePaths[i], lowerPath) {
+				x.origins[path] = x.sourceNames[i]
+				return
+			}
+		}
+	})
+	x.sourceNames, x.sourcePaths = nil, nil
+}
+
+func (x *provenanceRecorder) save() {
+	if x == nil {
+		return
+	}
+	x.target.store(x.origins)
+}
+
+// walkConfig 遍历配置的叶子节点, 结构体、结构体切片和值为结构体的 map 会继续展开
+// 接口、函数、chan 以及 json:"-" 的字段不属于配置, 会被跳过
+func walkConfig(path string, v reflect.Value, fn func(path string, value reflect.Value)) {
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() || !isDumpType(v.Type()) {
+		return
+	}
+
+	switch {
+	case v.Kind() == reflect.Struct && !isTextType(v.Type()):
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() || field.Tag.Get("json") == "-" {
+				continue
+			}
+			walkConfig(joinFieldPath(path, field), v.Field(i), fn)
+		}
+	case (v.Kind() == reflect.Slice || v.Kind() == reflect.Array) && isStructType(v.Type().Elem()):
+		for i := 0; i < v.Len(); i++ {
+			walkConfig(fmt.Sprintf("%s[%d]", path, i), v.Index(i), fn)
+		}
+	case v.Kind() == reflect.Map && isStructType(v.Type().Elem()):
+		iter := v.MapRange()
+		for iter.Next() {
+			walkConfig(fmt.Sprintf("%s.%v", path, iter.Key()), iter.Value(), fn)
+		}
+	default:
+		fn(path, v)
+	}
+}
+
+// flattenSourcePaths 展开配置源中出现过的路径, 值为该路径是否为标量
+func flattenSourcePaths(path string, v interface{}, out map[string]bool) {
+	if path != "" {
+		switch v.(type) {
+		case map[string]interface{}, []interface{}:
+			out[strings.ToLower(path)] = false
+		default:
+			out[strings.ToLower(path)] = true
+		}
+	}
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for k, item := range value {
+			key := k
+			if path != "" {
+				key = path + "." + k
+			}
+			flattenSourcePaths(key, item, out)
+		}
+	case []interface{}:
+		for i, item := range value {
+			flattenSourcePaths(fmt.Sprintf("%s[%d]", path, i), item, out)
+		}
+	}
+}
+
+// containsSourcePath 配置源中出现了 path, 或者 path 的上级是标量, 例如 timeout: 1s 对应的 timeout.seconds
+func containsSourcePath(paths map[string]bool, path string) bool {
+	if _, ok := paths[path]; ok {
+		return true
+	}
+	for i := len(path) - 1; i > 0; i-- {
+		if (path[i] == '.' || path[i] == '[') && paths[path[:i]] {
+			return true
+		}
+	}
+	return false
+}
+
+// Dump 输出 dst 当前的配置, secret:"true" 的字段输出为 ******
+// YAML 格式在行尾注释中标注字段来源, JSON 格式输出 {"config": ..., "origins": {...}}
+// p 为加载 dst 时 WithProvenance 传入的 Provenance, 为 nil 时不输出来源
+func Dump(dst interface{}, format string, p *Provenance) ([]byte, error) {
+	origins := p.Explain()
+	node := dumpNode("", reflect.ValueOf(dst), false, origins)
+
+	switch format {
+	case DumpYAML, "":
+		return yaml.Marshal(node)
+	case DumpJSON:
+		var config interface{}
+		if err := node.Decode(&config); err != nil {
+			return nil, err
+		}
+		if origins == nil {
+			origins = map[string]string{}
+		}
+		return json.MarshalIndent(map[string]interface{}{
+			"config":  config,
+			"origins": origins,
+		}, "", "  ")
+	default:
+		return nil, fmt.Errorf("unsupported dump format %s", format)
+	}
+}
+
+func dumpNode(path string, v reflect.Value, secret bool, origins map[string]string) *yaml.Node {
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}
+		}
+		v = v.Elem()
+	}
+
+	switch {
+	case v.Kind() == reflect.Struct && !isTextType(v.Type()):
+		node := &yaml.Node{Kind: yaml.MappingNode}
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() || field.Tag.Get("json") == "-" || !isDumpType(field.Type) {
+				continue
+			}
+			node.Content = append(node.Content,
+				&yaml.Node{Kind: yaml.ScalarNode, Value: fieldName(field)},
+				dumpNode(joinFieldPath(path, field), v.Field(i), secret || field.Tag.Get(tagSecret) == "true", origins),
+			)
+		}
+		return node
+	case (v.Kind() == reflect.Slice || v.Kind() == reflect.Array) && isStructType(v.Type().Elem()):
+		node := &yaml.Node{Kind: yaml.SequenceNode}
+		for i := 0; i < v.Len(); i++ {
+			node.Content = append(node.Content, dumpNode(fmt.Sprintf("%s[%d]", path, i), v.Index(i), secret, origins))
+		}
+		return node
+	case v.Kind() == reflect.Map && isStructType(v.Type().Elem()):
+		node := &yaml.Node{Kind: yaml.MappingNode}
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j])
+		})
+		for _, key := range keys {
+			name := fmt.Sprint(key)
+			node.Content = append(node.Content,
+				&yaml.Node{Kind: yaml.ScalarNode, Value: name},
+				dumpNode(path+"."+name, v.MapIndex(key), secret, origins),
+			)
+		}
+		return node
+	}
+
+	node := dumpLeaf(v, secret)
+	if origin, ok := origins[path]; ok {
+		node.LineComment = origin
+	}
+	return node
+}
+
+func dumpLeaf(v reflect.Value, secret bool) *yaml.Node {
+	if secret && !v.IsZero() {
+		return &yaml.Node{Kind: yaml.ScalarNode, Value: redactedSecret}
+	}
+	if v.Type() == durationType {
+		return &yaml.Node{Kind: yaml.ScalarNode, Value: time.Duration(v.Int()).String()}
+	}
+	value := v.Interface()
+	if v.CanAddr() {
+		value = v.Addr().Interface()
+	}
+	if m, ok := value.(encoding.TextMarshaler); ok {
+		if bs, err := m.MarshalText(); err == nil {
+			return &yaml.Node{Kind: yaml.ScalarNode, Value: string(bs)}
+		}
+	}
+	node := &yaml.Node{}
+	if err := node.Encode(v.Interface()); err != nil {
+		return &yaml.Node{Kind: yaml.ScalarNode, Value: fmt.Sprint(v.Interface())}
+	}
+	return node
+}
+
+// isDumpType 接口、函数、chan 类型的字段不属于配置
+func isDumpType(t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Interface, reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		return false
+	default:
+		return true
+	}
+}
@@ -0,0 +1,134 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/opendevops-cn/codo-golang-sdk/config/testdata"
+	"github.com/spf13/pflag"
+)
+
+func TestExplainAndDump(t *testing.T) {
+	type mysqlConfig struct {
+		Host string `json:"host" default:"127.0.0.1"`
+		Port uint32 `json:"port" default:"3306"`
+		User string `json:"user"`
+		Pass string `json:"pass" secret:"true"`
+	}
+	type testConfig struct {
+		Name  string      `json:"name" flag:"name"`
+		Level string      `json:"level" default:"info"`
+		MySQL mysqlConfig `json:"mysql"`
+	}
+
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	yamlContent := "name: from-yaml\nmysql:\n  host: 10.0.0.1\n  port: 3306\n  user: root\n  pass: p@ss\n"
+	if err := os.WriteFile(configPath, []byte(yamlContent), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv("TEST_EXPLAIN_MYSQL_HOST", "10.0.0.2")
+	// 和上一层相同的值同样归属于设置它的层
+	os.Setenv("TEST_EXPLAIN_MYSQL_USER", "root")
+
+	var (
+		dst        testConfig
+		provenance = NewProvenance()
+	)
+	err := LoadConfig(&dst,
+		WithYaml(configPath),
+		WithEnv("TEST_EXPLAIN"),
+		WithPFlag(pflag.NewFlagSet("test", pflag.ContinueOnError), []string{"--name=from-flag"}),
+		WithProvenance(provenance),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		"name":       OriginPFlag,
+		"level":      OriginDefault,
+		"mysql.host": OriginEnv,
+		"mysql.port": "file:" + configPath,
+		"mysql.user": OriginEnv,
+		"mysql.pass": "file:" + configPath,
+	}
+	if got := provenance.Explain(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got====%v\nwant====%v", got, want)
+	}
+
+	bs, err := Dump(&dst, DumpYAML, provenance)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := string(bs)
+	for _, line := range []string{"name: from-flag # pflag", "host: 10.0.0.2 # env", "pass: '******' # file:"} {
+		if !strings.Contains(out, line) {
+			t.Errorf("dump yaml missing %q:\n%s", line, out)
+		}
+	}
+	if strings.Contains(out, "p@ss") {
+		t.Errorf("secret leaked:\n%s", out)
+	}
+
+	bs, err = Dump(&dst, DumpJSON, provenance)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var result struct {
+		Config  map[string]interface{} `json:"config"`
+		Origins map[string]string      `json:"origins"`
+	}
+	if err := json.Unmarshal(bs, &result); err != nil {
+		t.Fatal(err)
+	}
+	if result.Config["mysql"].(map[string]interface{})["pass"] != redactedSecret || !reflect.DeepEqual(result.Origins, want) {
+		t.Errorf("dump json====%s", bs)
+	}
+
+	bs, err = Dump(&dst, DumpYAML, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(bs), "#") {
+		t.Errorf("dump without provenance====%s", bs)
+	}
+	if got := NewProvenance().Explain(); got != nil {
+		t.Errorf("got====%v", got)
+	}
+}
+
+func TestExplainProto(t *testing.T) {
+	// 和配置文件中的值相同
+	t.Setenv("CODO_REDIS_PORT", "6379")
+
+	var (
+		cfg        testdata.Bootstrap
+		provenance = NewProvenance()
+	)
+	err := LoadConfig(&cfg,
+		WithYaml("testdata/config.yaml"),
+		WithEnv("TEST_EXPLAIN_PROTO"),
+		WithPFlag(pflag.NewFlagSet("test", pflag.ContinueOnError), []string{"--app.name=from-flag"}),
+		WithProvenance(provenance),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := provenance.Explain()
+	want := map[string]string{
+		"APP.NAME":            OriginPFlag,
+		"APP.ENV":             "file:testdata/config.yaml",
+		"APP.TIMEOUT.seconds": "file:testdata/config.yaml",
+		"REDIS.R_PORT":        OriginEnv,
+	}
+	for path, origin := range want {
+		if got[path] != origin {
+			t.Errorf("%s got====%s, want====%s", path, got[path], origin)
+		}
+	}
+}
@@ -0,0 +1,256 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const jsonSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// JSONSchema 配置结构体对应的 JSON Schema, x-env/x-flag 为字段对应的环境变量和命令行参数
+type JSONSchema struct {
+	Schema               string                 `json:"$schema,omitempty"`
+	Type                 interface{}            `json:"type,omitempty"`
+	Description          string                 `json:"description,omitempty"`
+	Default              interface{}            `json:"default,omitempty"`
+	Enum                 []interface{}          `json:"enum,omitempty"`
+	Pattern              string                 `json:"pattern,omitempty"`
+	Minimum              *float64               `json:"minimum,omitempty"`
+	Maximum              *float64               `json:"maximum,omitempty"`
+	MinLength            *int                   `json:"minLength,omitempty"`
+	MaxLength            *int                   `json:"maxLength,omitempty"`
+	MinItems             *int                   `json:"minItems,omitempty"`
+	MaxItems             *int                   `json:"maxItems,omitempty"`
+	MinProperties        *int                   `json:"minProperties,omitempty"`
+	MaxProperties        *int                   `json:"maxProperties,omitempty"`
+	Properties           map[string]*JSONSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	Items                *JSONSchema            `json:"items,omitempty"`
+	AdditionalProperties *JSONSchema            `json:"additionalProperties,omitempty"`
+	Env                  string                 `json:"x-env,omitempty"`
+	Flag                 string                 `json:"x-flag,omitempty"`
+}
+
+// Schema 根据配置结构体生成 JSON Schema, 用于在发布前校验 YAML 配置
+// 字段描述来自 usage tag, 默认值、枚举、取值范围来自 default/oneof/min/max/regex tag,
+// 环境变量名按 NewEnvSource 或 WithEnv 指定的前缀生成, 默认为 CODO
+func Schema(dst interface{}, opts ...ConfigOption) ([]byte, error) {
+	c := defaultConfigOptions()
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	t := reflect.TypeOf(dst)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("invalid value: must be a struct or a pointer to struct")
+	}
+
+	var envPrefix string
+	if env := c.lastEnvSource(); env != nil {
+		envPrefix = env.prefix
+	} else if c.loadFromEnv {
+		envPrefix = c.envPrefix
+	}
+	schema, err := structSchema(t, envPrefix, "", true, map[reflect.Type]bool{})
+	if err != nil {
+		return nil, err
+	}
+	schema.Schema = jsonSchemaDraft
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+// structSchema 生成结构体的 schema, hasFlag 为 false 时(例如结构体切片的元素)不生成 x-flag
+// visiting 记录当前路径上的结构体类型, 防止自引用类型无限展开
+func structSchema(t reflect.Type, envPrefix, flagKeyPrefix string, hasFlag bool, visiting map[reflect.Type]bool) (*JSONSchema, error) {
+	schema := &JSONSchema{Type: "object"}
+	if visiting[t] {
+		return schema, nil
+	}
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	schema.Properties = make(map[string]*JSONSchema)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() || strings.Split(field.Tag.Get("json"), ",")[0] == "-" {
+			continue
+		}
+		name := fieldName(field)
+		property, err := fieldSchema(field, envPrefix, flagKeyPrefix, hasFlag, visiting)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		schema.Properties[name] = property
+		if field.Tag.Get(tagRequired) == "true" {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+	return schema, nil
+}
+
+func fieldSchema(field reflect.StructField, envPrefix, flagKeyPrefix string, hasFlag bool, visiting map[reflect.Type]bool) (*JSONSchema, error) {
+	t := field.Type
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	envKeyPrefix, envKey := parseEnvKey(envPrefix, field)
+
+	if isFlagStruct(t) {
+		schema, err := structSchema(t, envKeyPrefix, flagPrefix(flagKeyPrefix, field), hasFlag, visiting)
+		if err != nil {
+			return nil, err
+		}
+		schema.Description = field.Tag.Get("usage")
+		return schema, nil
+	}
+
+	schema := typeSchema(t)
+	schema.Description = field.Tag.Get("usage")
+	if elem := sliceElem(t); elem != nil && isFlagStruct(elem) {
+		items, err := structSchema(elem, envKeyPrefix+"_{N}", "", false, visiting)
+		if err != nil {
+			return nil, err
+		}
+		schema.Items = items
+	} else {
+		schema.Env = envKey
+	}
+	if hasFlag {
+		if flagName, _ := parseFlagTag(flagKeyPrefix, field); flagName != "" {
+			schema.Flag = "--" + flagName
+		}
+	}
+
+	if defaultValue, ok := field.Tag.Lookup(tagDefault); ok {
+		value, err := schemaValue(t, defaultValue)
+		if err != nil {
+			return nil, err
+		}
+		schema.Default = value
+	}
+	if oneof, ok := field.Tag.Lookup(tagOneof); ok {
+		for _, option := range strings.Fields(oneof) {
+			value, err := schemaValue(t, option)
+			if err != nil {
+				return nil, err
+			}
+			schema.Enum = append(schema.Enum, value)
+		}
+	}
+	if pattern, ok := field.Tag.Lookup(tagRegex); ok {
+		schema.Pattern = pattern
+	}
+	if err := schemaRange(schema, field, t); err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
+func sliceElem(t reflect.Type) reflect.Type {
+	if t.Kind() != reflect.Slice || isTextType(t) {
+		return nil
+	}
+	elem := t.Elem()
+	for elem.Kind() == reflect.Pointer {
+		elem = elem.Elem()
+	}
+	return elem
+}
+
+// typeSchema 叶子字段的类型, time.Duration 同时支持 "30s" 和纳秒整数
+func typeSchema(t reflect.Type) *JSONSchema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == durationType {
+		return &JSONSchema{Type: []string{"string", "integer"}}
+	}
+	if isTextType(t) {
+		return &JSONSchema{Type: "string"}
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return &JSONSchema{Type: "string"}
+	case reflect.Bool:
+		return &JSONSchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &JSONSchema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &JSONSchema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		return &JSONSchema{Type: "array", Items: typeSchema(t.Elem())}
+	case reflect.Map:
+		return &JSONSchema{Type: "object", AdditionalProperties: typeSchema(t.Elem())}
+	case reflect.Struct:
+		return &JSONSchema{Type: "object"}
+	default:
+		// interface{} 等类型不限制
+		return &JSONSchema{}
+	}
+}
+
+// schemaValue 将 tag 中的字符串按字段类型转换为 JSON 值, time.Duration 和文本类型保持字符串
+func schemaValue(t reflect.Type, s string) (interface{}, error) {
+	if t == durationType || isTextType(t) {
+		return s, nil
+	}
+	value := reflect.New(t).Elem()
+	if err := setValue(value, "tag", s); err != nil {
+		return nil, err
+	}
+	return value.Interface(), nil
+}
+
+// schemaRange 将 min/max tag 转换为数值范围或长度范围
+func schemaRange(schema *JSONSchema, field reflect.StructField, t reflect.Type) error {
+	for _, key := range []string{tagMin, tagMax} {
+		bound, ok := field.Tag.Lookup(key)
+		if !ok {
+			continue
+		}
+		switch t.Kind() {
+		case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+			n, err := strconv.Atoi(bound)
+			if err != nil {
+				return fmt.Errorf("invalid %s tag %q", key, bound)
+			}
+			limit := &n
+			switch {
+			case t.Kind() == reflect.String && key == tagMin:
+				schema.MinLength = limit
+			case t.Kind() == reflect.String:
+				schema.MaxLength = limit
+			case t.Kind() == reflect.Map && key == tagMin:
+				schema.MinProperties = limit
+			case t.Kind() == reflect.Map:
+				schema.MaxProperties = limit
+			case key == tagMin:
+				schema.MinItems = limit
+			default:
+				schema.MaxItems = limit
+			}
+		default:
+			// time.Duration 的范围写法为 "1s", 无法表达为 JSON Schema 的数值范围
+			if t == durationType {
+				continue
+			}
+			f, err := strconv.ParseFloat(bound, 64)
+			if err != nil {
+				return fmt.Errorf("invalid %s tag %q", key, bound)
+			}
+			if key == tagMin {
+				schema.Minimum = &f
+			} else {
+				schema.Maximum = &f
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package config
+
+import (
+	"encoding/json"
+	"flag"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+func TestNestedFlags(t *testing.T) {
+	type mysqlConfig struct {
+		Host string `json:"host" flag:"host|H" usage:"mysql host"`
+		Port int    `json:"port" flag:"port"`
+	}
+	type testConfig struct {
+		Name  string       `json:"name" flag:"name|n"`
+		MySQL *mysqlConfig `json:"mysql"`
+	}
+
+	args := []string{"-n=app", "--mysql.host=10.0.0.1", "--mysql.port=3306"}
+	var stdFlag testConfig
+	if err := LoadFlag(flag.NewFlagSet("test", flag.ContinueOnError), args, &stdFlag); err != nil {
+		t.Fatal(err)
+	}
+	var pFlag testConfig
+	if err := LoadPFlag(pflag.NewFlagSet("ptest", pflag.ContinueOnError), args, &pFlag); err != nil {
+		t.Fatal(err)
+	}
+	for _, dst := range []testConfig{stdFlag, pFlag} {
+		if dst.Name != "app" || dst.MySQL == nil || dst.MySQL.Host != "10.0.0.1" || dst.MySQL.Port != 3306 {
+			t.Errorf("flag====%+v, mysql====%+v", dst, dst.MySQL)
+		}
+	}
+
+	var short testConfig
+	if err := LoadFlag(flag.NewFlagSet("short", flag.ContinueOnError), []string{"-H=10.0.0.2"}, &short); err != nil {
+		t.Fatal(err)
+	}
+	if short.MySQL == nil || short.MySQL.Host != "10.0.0.2" {
+		t.Errorf("short flag====%+v", short.MySQL)
+	}
+}
+
+func TestSchema(t *testing.T) {
+	type serverConfig struct {
+		Host string `json:"host" required:"true"`
+	}
+	type mysqlConfig struct {
+		Host string `json:"host" flag:"host" default:"127.0.0.1" usage:"mysql host"`
+		Port int    `json:"port" default:"3306" min:"1" max:"65535"`
+	}
+	type testConfig struct {
+		Level   string         `json:"level" default:"INFO" oneof:"DEBUG INFO" env:"LOG_LEVEL"`
+		Timeout time.Duration  `json:"timeout" default:"3s"`
+		Tags    []string       `json:"tags" default:"a,b" max:"3"`
+		MySQL   mysqlConfig    `json:"mysql"`
+		Servers []serverConfig `json:"servers"`
+		Ignored string         `json:"-"`
+	}
+
+	bs, err := Schema(&testConfig{}, WithEnv("APP"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]interface{}
+	if err := json.Unmarshal(bs, &got); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]interface{}{
+		"$schema": jsonSchemaDraft,
+		"type":    "object",
+		"properties": map[string]interface{}{
+			"level": map[string]interface{}{
+				"type": "string", "default": "INFO", "enum": []interface{}{"DEBUG", "INFO"}, "x-env": "LOG_LEVEL",
+			},
+			"timeout": map[string]interface{}{
+				"type": []interface{}{"string", "integer"}, "default": "3s", "x-env": "APP_TIMEOUT",
+			},
+			"tags": map[string]interface{}{
+				"type": "array", "items": map[string]interface{}{"type": "string"},
+				"default": []interface{}{"a", "b"}, "maxItems": float64(3), "x-env": "APP_TAGS",
+			},
+			"mysql": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"host": map[string]interface{}{
+						"type": "string", "default": "127.0.0.1", "description": "mysql host",
+						"x-env": "APP_MYSQL_HOST", "x-flag": "--mysql.host",
+					},
+					"port": map[string]interface{}{
+						"type": "integer", "default": float64(3306), "minimum": float64(1), "maximum": float64(65535),
+						"x-env": "APP_MYSQL_PORT",
+					},
+				},
+			},
+			"servers": map[string]interface{}{
+				"type": "array",
+				"items": map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"host": map[string]interface{}{"type": "string", "x-env": "APP_SERVERS_{N}_HOST"},
+					},
+					"required": []interface{}{"host"},
+				},
+			},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("schema====%s", bs)
+	}
+}
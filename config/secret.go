@@ -0,0 +1,221 @@
+package config
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/opendevops-cn/codo-golang-sdk/consts"
+)
+
+// 字符串配置支持的密文和引用写法
+//
+//	${env:NAME}                 读取环境变量 NAME, 可以嵌在字符串中
+//	${file:/run/secrets/db}     读取文件内容并去掉末尾换行, 可以嵌在字符串中
+//	$${env:NAME}                包含引用的字符串中 $$ 表示 $, 用于写不需要解析的 ${
+//	enc:AES256-GCM:<base64>     使用密钥解密, 必须是完整的值, 可以用 tools/confcrypt 生成
+//
+// 远程配置源(文件以外的配置源, 例如 etcd、k2、HTTPSource)中的引用默认不解析,
+// 避免能修改远程配置的人读取服务所在机器上的文件和环境变量, 见 WithSecretResolution
+const secretEncPrefix = "enc:AES256-GCM:"
+
+// SecretKeyEnv 默认的解密密钥环境变量
+var SecretKeyEnv = strings.ToUpper(consts.AES_CRYPTO_KEY)
+
+var secretRefRegexp = regexp.MustCompile(`\$\$|\$\{(env|file):([^}]+)\}`)
+
+// WithSecretKey 设置解密 enc: 配置使用的密钥, 默认读取环境变量 AES_CRYPTO_KEY
+func WithSecretKey(key string) ConfigOption {
+	return func(options *ConfigOptions) {
+		options.secretKey = key
+	}
+}
+
+// WithSecretResolution remote 为 true 时远程配置源中的 ${env:}/${file:} 引用同样会被解析
+// 默认只解析文件、环境变量和命令行参数中的引用, enc: 密文不受影响, 总是会被解密
+func WithSecretResolution(remote bool) ConfigOption {
+	return func(options *ConfigOptions) {
+		options.resolveRemoteSecrets = remote
+	}
+}
+
+// isLocalSource 文件配置源的内容由部署方控制, 其他配置源都视为远程配置源
+func isLocalSource(source Source) bool {
+	_, ok := source.(*fileSource)
+	return ok
+}
+
+// escapeSecretRefs 将远程配置源中包含 ${ 的字符串的 $ 转义为 $$, 解析时还原为原来的值
+func escapeSecretRefs(v interface{}) interface{} {
+	switch value := v.(type) {
+	case string:
+		if strings.Contains(value, "${") {
+			return strings.ReplaceAll(value, "$", "$$")
+		}
+		return value
+	case map[string]interface{}:
+		for k, item := range value {
+			value[k] = escapeSecretRefs(item)
+		}
+		return value
+	case []interface{}:
+		for i, item := range value {
+			value[i] = escapeSecretRefs(item)
+		}
+		return value
+	default:
+		return v
+	}
+}
+
+// ResolveSecrets 解析 dst 中所有字符串字段的 ${env:}/${file:} 引用和 enc: 密文
+// key 为空时读取环境变量 AES_CRYPTO_KEY
+func ResolveSecrets(dst interface{}, key string) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return fmt.Errorf("invalid value: must be a non-nil pointer")
+	}
+	if key == "" {
+		key = os.Getenv(SecretKeyEnv)
+	}
+	return resolveSecrets("", v.Elem(), key)
+}
+
+func resolveSecrets(path string, v reflect.Value, key string) error {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return nil
+		}
+		return resolveSecrets(path, v.Elem(), key)
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			if err := resolveSecrets(joinFieldPath(path, field), v.Field(i), key); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := resolveSecrets(fmt.Sprintf("%s[%d]", path, i), v.Index(i), key); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		if v.Type().Elem().Kind() != reflect.String {
+			return nil
+		}
+		iter := v.MapRange()
+		for iter.Next() {
+			resolved, err := resolveSecret(iter.Value().String(), key)
+			if err != nil {
+				return fmt.Errorf("%s[%v]: %w", path, iter.Key(), err)
+			}
+			v.SetMapIndex(iter.Key(), reflect.ValueOf(resolved).Convert(v.Type().Elem()))
+		}
+	case reflect.String:
+		if !v.CanSet() {
+			return nil
+		}
+		resolved, err := resolveSecret(v.String(), key)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		v.SetString(resolved)
+	}
+	return nil
+}
+
+func resolveSecret(value string, key string) (string, error) {
+	if strings.HasPrefix(value, secretEncPrefix) {
+		return DecryptSecret(key, value)
+	}
+	if !strings.Contains(value, "${") {
+		return value, nil
+	}
+
+	var resolveErr error
+	resolved := secretRefRegexp.ReplaceAllStringFunc(value, func(ref string) string {
+		match := secretRefRegexp.FindStringSubmatch(ref)
+		switch match[1] {
+		case "":
+			return "$"
+		case "env":
+			envValue, ok := os.LookupEnv(match[2])
+			if !ok {
+				resolveErr = fmt.Errorf("env %s is not set", match[2])
+			}
+			return envValue
+		default:
+			bs, err := os.ReadFile(match[2])
+			if err != nil {
+				resolveErr = err
+			}
+			return strings.TrimRight(string(bs), "\r\n")
+		}
+	})
+	if resolveErr != nil {
+		return "", resolveErr
+	}
+	return resolved, nil
+}
+
+// EncryptSecret 使用 AES-256-GCM 加密, 返回 enc:AES256-GCM:<base64> 格式的密文
+// key 可以是任意长度的字符串, 实际密钥为 key 的 sha256
+func EncryptSecret(key string, plaintext string) (string, error) {
+	gcm, err := newSecretCipher(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := gcm.Seal(nonce, nonce, []byte(plaintext), nil)
+	return secretEncPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// DecryptSecret 解密 EncryptSecret 生成的密文
+func DecryptSecret(key string, value string) (string, error) {
+	gcm, err := newSecretCipher(key)
+	if err != nil {
+		return "", err
+	}
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, secretEncPrefix))
+	if err != nil {
+		return "", fmt.Errorf("invalid encrypted value: %w", err)
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return "", errors.New("invalid encrypted value: too short")
+	}
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", fmt.Errorf("decrypt failed: %w", err)
+	}
+	return string(plaintext), nil
+}
+
+func newSecretCipher(key string) (cipher.AEAD, error) {
+	if key == "" {
+		return nil, fmt.Errorf("secret key is empty, use WithSecretKey or env %s", SecretKeyEnv)
+	}
+	sum := sha256.Sum256([]byte(key))
+	block, err := aes.NewCipher(sum[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,125 @@
+package config
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadConfigSecrets(t *testing.T) {
+	type mysqlConfig struct {
+		User string `json:"user"`
+		Pass string `json:"pass"`
+		DSN  string `json:"dsn"`
+	}
+	type testConfig struct {
+		MySQL  mysqlConfig       `json:"mysql"`
+		Token  string            `json:"token"`
+		Tokens []string          `json:"tokens"`
+		Extra  map[string]string `json:"extra"`
+	}
+
+	const key = "test-secret-key"
+	encrypted, err := EncryptSecret(key, "p@ss")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	secretPath := filepath.Join(dir, "token")
+	if err := os.WriteFile(secretPath, []byte("file-token\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	configPath := filepath.Join(dir, "config.yaml")
+	yamlContent := "mysql:\n" +
+		"  user: ${env:TEST_SECRET_USER}\n" +
+		"  dsn: ${env:TEST_SECRET_USER}@tcp(127.0.0.1)\n" +
+		"token: ${file:" + secretPath + "}\n" +
+		"tokens: [\"" + encrypted + "\"]\n" +
+		"extra:\n  pass: " + encrypted + "\n"
+	if err := os.WriteFile(configPath, []byte(yamlContent), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	os.Setenv("TEST_SECRET_USER", "root")
+	os.Setenv("TEST_SECRETS_MYSQL_PASS", encrypted)
+
+	var dst testConfig
+	err = LoadConfig(&dst, WithYaml(configPath), WithEnv("TEST_SECRETS"), WithSecretKey(key))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := testConfig{
+		MySQL:  mysqlConfig{User: "root", Pass: "p@ss", DSN: "root@tcp(127.0.0.1)"},
+		Token:  "file-token",
+		Tokens: []string{"p@ss"},
+		Extra:  map[string]string{"pass": "p@ss"},
+	}
+	if !reflect.DeepEqual(dst, want) {
+		t.Errorf("dst====%+v\nwant====%+v", dst, want)
+	}
+
+	var wrongKey testConfig
+	if err := LoadConfig(&wrongKey, WithYaml(configPath), WithEnv("TEST_SECRETS"), WithSecretKey("wrong")); err == nil {
+		t.Errorf("decrypt with wrong key should fail")
+	}
+}
+
+func TestLoadConfigRemoteSecretRefs(t *testing.T) {
+	type testConfig struct {
+		Token string   `json:"token"`
+		Tags  []string `json:"tags"`
+		Pass  string   `json:"pass"`
+		Local string   `json:"local"`
+	}
+
+	dir := t.TempDir()
+	secretPath := filepath.Join(dir, "token")
+	if err := os.WriteFile(secretPath, []byte("file-token\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	configPath := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(configPath, []byte("local: ${file:"+secretPath+"}\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	const key = "test-secret-key"
+	encrypted, err := EncryptSecret(key, "p@ss")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TEST_REMOTE_SECRET", "env-value")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprintf(w, "token: ${file:%s}\ntags: [\"${env:TEST_REMOTE_SECRET}\", \"a$$b${\"]\npass: %s\n", secretPath, encrypted)
+	}))
+	defer server.Close()
+
+	// 远程配置源中的引用原样保留, enc: 密文仍然会被解密
+	var dst testConfig
+	err = LoadConfig(&dst, WithYaml(configPath), WithSource(NewHTTPSource(server.URL)), WithSecretKey(key))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := testConfig{
+		Token: "${file:" + secretPath + "}",
+		Tags:  []string{"${env:TEST_REMOTE_SECRET}", "a$$b${"},
+		Pass:  "p@ss",
+		Local: "file-token",
+	}
+	if !reflect.DeepEqual(dst, want) {
+		t.Errorf("dst====%+v\nwant====%+v", dst, want)
+	}
+
+	dst = testConfig{}
+	err = LoadConfig(&dst, WithSource(NewHTTPSource(server.URL)), WithSecretKey(key), WithSecretResolution(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dst.Token != "file-token" || dst.Tags[0] != "env-value" {
+		t.Errorf("dst====%+v", dst)
+	}
+}
@@ -0,0 +1,366 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"gopkg.in/yaml.v3"
+)
+
+// Source 配置源, 返回的 map 会按 WithSource 的顺序深度合并, 后加载的覆盖先加载的
+// 实现 fmt.Stringer 时, 其返回值作为 Explain 中的来源名称
+type Source interface {
+	Load(ctx context.Context) (map[string]interface{}, error)
+}
+
+// WatchableSource 支持监听变化的配置源
+type WatchableSource interface {
+	Source
+	// Watch 阻塞监听配置源, 每次变化时调用 notify, ctx 取消后返回
+	Watch(ctx context.Context, notify func()) error
+}
+
+// WithSource 添加配置源, 可以多次调用, 和 WithYaml 一起按添加顺序合并
+func WithSource(sources ...Source) ConfigOption {
+	return func(options *ConfigOptions) {
+		options.sources = append(options.sources, sources...)
+	}
+}
+
+// WithContext 设置加载配置源使用的 context
+func WithContext(ctx context.Context) ConfigOption {
+	return func(options *ConfigOptions) {
+		options.ctx = ctx
+	}
+}
+
+// LoadSources 按顺序加载并合并配置源, 再反序列化到 dst
+func LoadSources(ctx context.Context, dst interface{}, sources ...Source) error {
+	_, err := loadSources(ctx, dst, sources, nil, loadOptions{})
+	return err
+}
+
+// loadSources 返回合并后的配置, 用于判断哪些字段被配置源设置过
+func loadSources(ctx context.Context, dst interface{}, sources []Source, recorder *provenanceRecorder, opts loadOptions) (map[string]interface{}, error) {
+	t := reflect.TypeOf(dst)
+	merged := make(map[string]interface{})
+	for _, source := range sources {
+		m, err := loadLayers(ctx, source, t)
+		if err != nil {
+			return nil, err
+		}
+		if opts.escapeRemote && !isLocalSource(source) {
+			escapeSecretRefs(m)
+		}
+		recorder.addSource(source, m)
+		mergeMap(merged, m, t)
+	}
+
+	var data interface{} = merged
+	if _, ok := dst.(proto.Message); !ok {
+		// 远程配置源的值通常都是字符串, 按目标字段类型转换一次
+		data = coerceValue(merged, t)
+	}
+	bs, err := Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	if msg, ok := dst.(proto.Message); ok && opts.overlay {
+		src := msg.ProtoReflect().New()
+		if err := Unmarshal(bs, src.Interface()); err != nil {
+			return nil, err
+		}
+		overlayProto(msg.ProtoReflect(), src, merged)
+		return merged, nil
+	}
+	return merged, Unmarshal(bs, dst)
+}
+
+// merge tag 控制多个配置源/文件之间切片的合并方式, 默认后面的覆盖前面的
+//
+//	Hosts []string `json:"hosts" merge:"append"`
+const (
+	tagMerge    = "merge"
+	mergeAppend = "append"
+)
+
+// layeredSource 由多层配置组成的配置源, 例如多个 YAML 文件, 每层按目标类型依次合并
+type layeredSource interface {
+	loadLayers(ctx context.Context) ([]map[string]interface{}, error)
+}
+
+// loadLayers 加载配置源, 多层配置源按 t 合并为一层
+func loadLayers(ctx context.Context, source Source, t reflect.Type) (map[string]interface{}, error) {
+	ls, ok := source.(layeredSource)
+	if !ok {
+		return source.Load(ctx)
+	}
+	layers, err := ls.loadLayers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	merged := make(map[string]interface{})
+	for _, layer := range layers {
+		mergeMap(merged, layer, t)
+	}
+	return merged, nil
+}
+
+// mergeMap 将 src 深度合并到 dst, 两边都是 map 时递归合并, 否则 src 覆盖 dst
+// t 为 dst 对应的类型, 用于查找字段的 merge tag, 标记了 merge:"append" 的切片追加而不是覆盖
+// t 为 nil 时所有切片都覆盖
+func mergeMap(dst, src map[string]interface{}, t reflect.Type) {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	for k, v := range src {
+		var (
+			fieldType reflect.Type
+			appendTag bool
+		)
+		if t != nil {
+			switch t.Kind() {
+			case reflect.Struct:
+				if field, ok := lookupField(t, k); ok {
+					fieldType = field.Type
+					appendTag = field.Tag.Get(tagMerge) == mergeAppend
+				}
+			case reflect.Map:
+				fieldType = t.Elem()
+			}
+		}
+
+		switch value := v.(type) {
+		case map[string]interface{}:
+			if dstMap, ok := dst[k].(map[string]interface{}); ok {
+				mergeMap(dstMap, value, fieldType)
+				continue
+			}
+		case []interface{}:
+			if dstSlice, ok := dst[k].([]interface{}); ok && appendTag {
+				items := make([]interface{}, 0, len(dstSlice)+len(value))
+				dst[k] = append(append(items, dstSlice...), value...)
+				continue
+			}
+		}
+		dst[k] = v
+	}
+}
+
+// coerceValue 将字符串转换为目标字段需要的数值/布尔类型, 无法转换时原样返回, 交给反序列化报错
+func coerceValue(v interface{}, t reflect.Type) interface{} {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch value := v.(type) {
+	case map[string]interface{}:
+		switch t.Kind() {
+		case reflect.Struct:
+			for k, item := range value {
+				if field, ok := lookupField(t, k); ok {
+					value[k] = coerceValue(item, field.Type)
+				}
+			}
+		case reflect.Map:
+			for k, item := range value {
+				value[k] = coerceValue(item, t.Elem())
+			}
+		}
+		return value
+	case []interface{}:
+		if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+			for i, item := range value {
+				value[i] = coerceValue(item, t.Elem())
+			}
+		}
+		return value
+	case string:
+		if t == durationType {
+			if d, err := time.ParseDuration(value); err == nil {
+				return int64(d)
+			}
+			return value
+		}
+		switch t.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if i64, err := strconv.ParseInt(value, 10, 64); err == nil {
+				return i64
+			}
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if u64, err := strconv.ParseUint(value, 10, 64); err == nil {
+				return u64
+			}
+		case reflect.Float32, reflect.Float64:
+			if f64, err := strconv.ParseFloat(value, 64); err == nil {
+				return f64
+			}
+		case reflect.Bool:
+			if b, err := strconv.ParseBool(value); err == nil {
+				return b
+			}
+		case reflect.Slice:
+			if t.Elem().Kind() != reflect.Uint8 {
+				items := strings.Split(value, ",")
+				result := make([]interface{}, 0, len(items))
+				for _, item := range items {
+					result = append(result, coerceValue(strings.TrimSpace(item), t.Elem()))
+				}
+				return result
+			}
+		}
+		return value
+	default:
+		return v
+	}
+}
+
+// lookupField 和 encoding/json 一样, 按 json tag 或字段名不区分大小写匹配
+func lookupField(t reflect.Type, key string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		if strings.EqualFold(name, key) {
+			return field, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+func decodeYaml(r io.Reader) (map[string]interface{}, error) {
+	var dst map[string]interface{}
+	err := yaml.NewDecoder(r).Decode(&dst)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	if dst == nil {
+		dst = make(map[string]interface{})
+	}
+	return dst, nil
+}
+
+type HTTPSourceOption func(*HTTPSource)
+
+// WithHTTPSourceClient 设置请求使用的 http.Client
+func WithHTTPSourceClient(client *http.Client) HTTPSourceOption {
+	return func(x *HTTPSource) {
+		x.client = client
+	}
+}
+
+// WithHTTPSourceHeader 设置请求头, 例如鉴权信息
+func WithHTTPSourceHeader(key, value string) HTTPSourceOption {
+	return func(x *HTTPSource) {
+		x.header.Add(key, value)
+	}
+}
+
+// WithHTTPSourcePollInterval 设置 Watch 轮询间隔, 默认 30s
+func WithHTTPSourcePollInterval(interval time.Duration) HTTPSourceOption {
+	return func(x *HTTPSource) {
+		x.interval = interval
+	}
+}
+
+var _ WatchableSource = (*HTTPSource)(nil)
+
+// HTTPSource 从 HTTP 接口加载 YAML/JSON 格式的配置
+type HTTPSource struct {
+	url      string
+	client   *http.Client
+	header   http.Header
+	interval time.Duration
+
+	mu   sync.Mutex
+	last [sha256.Size]byte
+}
+
+func NewHTTPSource(url string, opts ...HTTPSourceOption) *HTTPSource {
+	x := &HTTPSource{
+		url:      url,
+		client:   &http.Client{Timeout: time.Second * 10},
+		header:   make(http.Header),
+		interval: time.Second * 30,
+	}
+	for _, opt := range opts {
+		opt(x)
+	}
+	return x
+}
+
+func (x *HTTPSource) Load(ctx context.Context) (map[string]interface{}, error) {
+	bs, err := x.fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	x.mu.Lock()
+	x.last = sha256.Sum256(bs)
+	x.mu.Unlock()
+	return decodeYaml(bytes.NewReader(bs))
+}
+
+func (x *HTTPSource) String() string {
+	return "http:" + x.url
+}
+
+// Watch 轮询配置, 和最近一次 Load 的内容不同时通知
+func (x *HTTPSource) Watch(ctx context.Context, notify func()) error {
+	ticker := time.NewTicker(x.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			bs, err := x.fetch(ctx)
+			if err != nil {
+				continue
+			}
+			x.mu.Lock()
+			changed := sha256.Sum256(bs) != x.last
+			x.mu.Unlock()
+			if changed {
+				notify()
+			}
+		}
+	}
+}
+
+func (x *HTTPSource) fetch(ctx context.Context) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, x.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("build request err: %w", err)
+	}
+	for k, v := range x.header {
+		req.Header[k] = v
+	}
+	resp, err := x.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("do request err: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("response status code %d", resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
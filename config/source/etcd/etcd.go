@@ -0,0 +1,128 @@
+package etcd
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"sync/atomic"
+
+	"github.com/opendevops-cn/codo-golang-sdk/config"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"gopkg.in/yaml.v3"
+)
+
+var _ config.WatchableSource = (*Source)(nil)
+
+// Client etcd 客户端, *clientv3.Client 满足该接口
+type Client interface {
+	clientv3.KV
+	clientv3.Watcher
+}
+
+// Source 从 etcd 的 key 前缀加载配置
+//
+// prefix 本身对应的 value 作为完整的 YAML/JSON 文档加载,
+// prefix 下的子 key 按 "/" 拆分为嵌套路径, 例如 prefix=/app, /app/mysql/host=127.0.0.1 对应 mysql.host,
+// 子 key 的 value 以 { 或 [ 开头时按 YAML/JSON 解析, 否则作为字符串
+type Source struct {
+	client Client
+	prefix string
+	// revision 最近一次 Load 时的版本, Watch 从下一个版本开始监听, 避免漏掉中间的变更
+	revision atomic.Int64
+}
+
+func New(client Client, prefix string) *Source {
+	return &Source{
+		client: client,
+		prefix: prefix,
+	}
+}
+
+func (x *Source) String() string {
+	return "etcd:" + x.prefix
+}
+
+func (x *Source) Load(ctx context.Context) (map[string]interface{}, error) {
+	resp, err := x.client.Get(ctx, x.prefix, clientv3.WithPrefix(), clientv3.WithSort(clientv3.SortByKey, clientv3.SortAscend))
+	if err != nil {
+		return nil, err
+	}
+	if resp.Header != nil {
+		x.revision.Store(resp.Header.Revision)
+	}
+
+	dst := make(map[string]interface{})
+	for _, kv := range resp.Kvs {
+		key := strings.TrimPrefix(string(kv.Key), x.prefix)
+		// 跳过 /app2 这类只是字符串前缀相同的 key
+		if key != "" && !strings.HasPrefix(key, "/") && !strings.HasSuffix(x.prefix, "/") {
+			continue
+		}
+		key = strings.Trim(key, "/")
+		if key == "" {
+			var doc map[string]interface{}
+			if err := yaml.Unmarshal(kv.Value, &doc); err != nil {
+				return nil, err
+			}
+			for k, v := range doc {
+				dst[k] = v
+			}
+			continue
+		}
+
+		value, err := decodeValue(kv.Value)
+		if err != nil {
+			return nil, err
+		}
+		setPath(dst, strings.Split(key, "/"), value)
+	}
+	return dst, nil
+}
+
+func (x *Source) Watch(ctx context.Context, notify func()) error {
+	opts := []clientv3.OpOption{clientv3.WithPrefix(), clientv3.WithKeysOnly()}
+	if revision := x.revision.Load(); revision > 0 {
+		opts = append(opts, clientv3.WithRev(revision+1))
+	}
+	watchChan := x.client.Watch(ctx, x.prefix, opts...)
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case resp, ok := <-watchChan:
+			if !ok {
+				return ctx.Err()
+			}
+			if err := resp.Err(); err != nil {
+				return err
+			}
+			if len(resp.Events) > 0 {
+				notify()
+			}
+		}
+	}
+}
+
+func decodeValue(bs []byte) (interface{}, error) {
+	trimmed := bytes.TrimSpace(bs)
+	if len(trimmed) == 0 || (trimmed[0] != '{' && trimmed[0] != '[') {
+		return string(bs), nil
+	}
+	var value interface{}
+	if err := yaml.Unmarshal(trimmed, &value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+func setPath(dst map[string]interface{}, path []string, value interface{}) {
+	for _, key := range path[:len(path)-1] {
+		next, ok := dst[key].(map[string]interface{})
+		if !ok {
+			next = make(map[string]interface{})
+			dst[key] = next
+		}
+		dst = next
+	}
+	dst[path[len(path)-1]] = value
+}
@@ -0,0 +1,126 @@
+package etcd
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/opendevops-cn/codo-golang-sdk/config"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/server/v3/embed"
+)
+
+// startEtcd 启动单节点的内嵌 etcd, 测试结束后关闭
+func startEtcd(t *testing.T) *clientv3.Client {
+	t.Helper()
+
+	cfg := embed.NewConfig()
+	cfg.Dir = t.TempDir()
+	cfg.LogLevel = "error"
+	clientURL, peerURL := freeURL(t), freeURL(t)
+	cfg.LCUrls, cfg.ACUrls = []url.URL{clientURL}, []url.URL{clientURL}
+	cfg.LPUrls, cfg.APUrls = []url.URL{peerURL}, []url.URL{peerURL}
+	cfg.InitialCluster = cfg.InitialClusterFromName(cfg.Name)
+
+	server, err := embed.StartEtcd(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(server.Close)
+	select {
+	case <-server.Server.ReadyNotify():
+	case <-time.After(time.Second * 10):
+		t.Fatal("etcd not ready")
+	}
+
+	client, err := clientv3.New(clientv3.Config{
+		Endpoints:   []string{clientURL.String()},
+		DialTimeout: time.Second * 3,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		client.Close()
+	})
+	return client
+}
+
+func freeURL(t *testing.T) url.URL {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	return url.URL{Scheme: "http", Host: listener.Addr().String()}
+}
+
+func TestSource(t *testing.T) {
+	type mysqlConfig struct {
+		Host string `json:"host"`
+		Port uint32 `json:"port"`
+	}
+	type testConfig struct {
+		Name  string      `json:"name"`
+		Debug bool        `json:"debug"`
+		Tags  []string    `json:"tags"`
+		MySQL mysqlConfig `json:"mysql"`
+	}
+
+	client := startEtcd(t)
+	ctx := context.Background()
+	for k, v := range map[string]string{
+		"/app":            "name: app\nmysql:\n  host: 127.0.0.1\n  port: 3306\n",
+		"/app/debug":      "true",
+		"/app/mysql/host": "10.0.0.1",
+		"/app/tags":       `["a", "b"]`,
+		"/app2/name":      "other",
+	} {
+		if _, err := client.Put(ctx, k, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	w, err := config.Watch[testConfig](
+		config.WithEnv("TEST_ETCD_SOURCE"),
+		config.WithSource(New(client, "/app")),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	want := &testConfig{
+		Name:  "app",
+		Debug: true,
+		Tags:  []string{"a", "b"},
+		MySQL: mysqlConfig{Host: "10.0.0.1", Port: 3306},
+	}
+	if got := w.Load(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("got====%+v\nwant====%+v", got, want)
+	}
+
+	changed := make(chan *testConfig, 1)
+	w.OnChange(func(_, newValue *testConfig) {
+		select {
+		case changed <- newValue:
+		default:
+		}
+	})
+	if _, err := client.Put(ctx, "/app/mysql/port", "3307"); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case got := <-changed:
+		if got.MySQL.Port != 3307 {
+			t.Errorf("got====%+v", got)
+		}
+	case <-time.After(time.Second * 5):
+		t.Fatal("etcd change not detected")
+	}
+}
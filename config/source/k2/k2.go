@@ -0,0 +1,114 @@
+package k2
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/opendevops-cn/codo-golang-sdk/config"
+	codok2 "github.com/opendevops-cn/codo-golang-sdk/k2"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	_ config.WatchableSource = (*Source)(nil)
+
+	_ Getter = (*codok2.NoAuthConfig)(nil)
+	_ Getter = (*codok2.AuthConfig)(nil)
+)
+
+// Getter 配置获取接口, k2.NoAuthConfig 和 k2.AuthConfig 满足该接口
+type Getter interface {
+	GetConfig(ctx context.Context) (map[string]string, error)
+}
+
+type Option func(*Source)
+
+// WithPollInterval 设置 Watch 轮询间隔, 默认 30s
+func WithPollInterval(interval time.Duration) Option {
+	return func(x *Source) {
+		x.interval = interval
+	}
+}
+
+// Source 从 k2 配置中心加载配置
+// 返回数据中的 key 作为顶层配置项, value 以 { 或 [ 开头时按 YAML/JSON 解析, 否则作为字符串
+type Source struct {
+	getter   Getter
+	interval time.Duration
+
+	mu   sync.Mutex
+	last map[string]string
+}
+
+func New(getter Getter, opts ...Option) *Source {
+	x := &Source{
+		getter:   getter,
+		interval: time.Second * 30,
+	}
+	for _, opt := range opts {
+		opt(x)
+	}
+	return x
+}
+
+func (x *Source) Load(ctx context.Context) (map[string]interface{}, error) {
+	data, err := x.getter.GetConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	x.mu.Lock()
+	x.last = data
+	x.mu.Unlock()
+
+	dst := make(map[string]interface{}, len(data))
+	for k, v := range data {
+		value, err := decodeValue(v)
+		if err != nil {
+			return nil, err
+		}
+		dst[k] = value
+	}
+	return dst, nil
+}
+
+func (x *Source) String() string {
+	return "k2"
+}
+
+// Watch 轮询配置, 和最近一次 Load 的结果不同时通知
+func (x *Source) Watch(ctx context.Context, notify func()) error {
+	ticker := time.NewTicker(x.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			data, err := x.getter.GetConfig(ctx)
+			if err != nil {
+				continue
+			}
+			x.mu.Lock()
+			changed := !reflect.DeepEqual(data, x.last)
+			x.mu.Unlock()
+			if changed {
+				notify()
+			}
+		}
+	}
+}
+
+func decodeValue(s string) (interface{}, error) {
+	trimmed := bytes.TrimSpace([]byte(s))
+	if len(trimmed) == 0 || (trimmed[0] != '{' && trimmed[0] != '[') {
+		return s, nil
+	}
+	var value interface{}
+	if err := yaml.Unmarshal(trimmed, &value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
@@ -0,0 +1,78 @@
+package k2
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/opendevops-cn/codo-golang-sdk/client/xhttp"
+	"github.com/opendevops-cn/codo-golang-sdk/config"
+	codok2 "github.com/opendevops-cn/codo-golang-sdk/k2"
+)
+
+func TestSource(t *testing.T) {
+	type mysqlConfig struct {
+		Host string `json:"host"`
+		Port uint32 `json:"port"`
+	}
+	type testConfig struct {
+		AppName string      `json:"app_name"`
+		Debug   bool        `json:"debug"`
+		MySQL   mysqlConfig `json:"mysql"`
+	}
+
+	var version atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(&codok2.Response{
+			Code: 0,
+			Data: map[string]string{
+				"app_name": "codo",
+				"debug":    "true",
+				"mysql":    `{"host": "10.0.0.1", "port": ` + []string{"3306", "3307"}[version.Load()] + `}`,
+			},
+		})
+	}))
+	defer server.Close()
+
+	client, err := xhttp.NewClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := config.Watch[testConfig](
+		config.WithEnv("TEST_K2_SOURCE"),
+		config.WithSource(New(codok2.NewNoAuthConfig(server.URL, client), WithPollInterval(time.Millisecond*10))),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	want := &testConfig{
+		AppName: "codo",
+		Debug:   true,
+		MySQL:   mysqlConfig{Host: "10.0.0.1", Port: 3306},
+	}
+	if got := w.Load(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("got====%+v\nwant====%+v", got, want)
+	}
+
+	changed := make(chan *testConfig, 1)
+	w.OnChange(func(_, newValue *testConfig) {
+		changed <- newValue
+	})
+	version.Store(1)
+
+	select {
+	case got := <-changed:
+		if got.MySQL.Port != 3307 {
+			t.Errorf("got====%+v", got)
+		}
+	case <-time.After(time.Second * 3):
+		t.Fatal("k2 change not detected")
+	}
+}
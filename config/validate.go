@@ -0,0 +1,375 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 支持的校验/默认值 tag
+//
+//	default:"8080"           字段为零值时使用的默认值, slice 使用逗号分隔
+//	required:"true"          字段不能为零值
+//	min:"1" max:"65535"      数值的取值范围, 字符串/slice/map 则为长度范围, time.Duration 支持 "1s" 写法
+//	oneof:"DEBUG INFO WARN"  取值只能是空格分隔的其中之一
+//	regex:"^[a-z]+$"         字符串必须匹配正则
+//
+// 未设置 required 的字段为零值时, 视为未配置, 跳过 min/max/oneof/regex 校验
+const (
+	tagDefault  = "default"
+	tagRequired = "required"
+	tagMin      = "min"
+	tagMax      = "max"
+	tagOneof    = "oneof"
+	tagRegex    = "regex"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// FieldError 单个字段的校验错误
+type FieldError struct {
+	// Path 字段路径, 例如 mysql.port
+	Path    string
+	Message string
+}
+
+func (e *FieldError) Error() string {
+	return e.Path + ": " + e.Message
+}
+
+// ValidationErrors 汇总所有字段的校验错误
+type ValidationErrors []*FieldError
+
+func (e ValidationErrors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, fe := range e {
+		msgs = append(msgs, fe.Error())
+	}
+	return "invalid config: " + strings.Join(msgs, "; ")
+}
+
+// ApplyDefaults 为零值字段填充 default tag 指定的默认值
+// 需要在加载配置之前调用, 之后显式配置的零值(false/0/"")不会再被默认值覆盖
+func ApplyDefaults(dst interface{}) error {
+	return applyDefaultsTo(dst, nil, nil)
+}
+
+// applyDefaultsTo present 和 record 见 applyDefaults
+func applyDefaultsTo(dst interface{}, present map[string]interface{}, record func(path string)) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return fmt.Errorf("invalid value: must be a non-nil pointer")
+	}
+	_, err := applyDefaults("", v.Elem(), map[reflect.Type]bool{}, present, record)
+	return err
+}
+
+// visiting 记录当前路径上的结构体类型, 防止自引用类型无限展开
+// present 为 nil 时零值字段视为未配置, 否则只有 present 中不存在的字段视为未配置,
+// 用于反序列化时被整体重置的 proto.Message, present 为合并后的配置源
+// record 不为 nil 时记录被设置了默认值的字段路径
+func applyDefaults(path string, v reflect.Value, visiting map[reflect.Type]bool, present map[string]interface{}, record func(path string)) (bool, error) {
+	if v.Kind() != reflect.Struct {
+		return false, nil
+	}
+	t := v.Type()
+	if visiting[t] {
+		return false, nil
+	}
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	var isSetFields bool
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		value := v.Field(i)
+		if !value.CanSet() {
+			continue
+		}
+		fieldPath := joinFieldPath(path, field)
+
+		var (
+			item      interface{}
+			isPresent bool
+		)
+		if present != nil {
+			item, isPresent = lookupKey(present, field)
+		}
+
+		valueElement := value
+		if value.Kind() == reflect.Pointer {
+			if value.IsNil() {
+				valueElement = reflect.New(value.Type().Elem()).Elem()
+			} else {
+				valueElement = value.Elem()
+			}
+		}
+
+		if valueElement.Kind() == reflect.Struct && !isTextType(valueElement.Type()) {
+			var fieldPresent map[string]interface{}
+			if present != nil {
+				if fieldPresent, _ = item.(map[string]interface{}); fieldPresent == nil {
+					if isPresent {
+						continue
+					}
+					fieldPresent = map[string]interface{}{}
+				}
+			}
+			ok, err := applyDefaults(fieldPath, valueElement, visiting, fieldPresent, record)
+			if err != nil {
+				return false, err
+			}
+			if ok {
+				if value.Kind() == reflect.Pointer {
+					value.Set(valueElement.Addr())
+				}
+				isSetFields = true
+			}
+			continue
+		}
+
+		defaultValue, ok := field.Tag.Lookup(tagDefault)
+		if !ok || isPresent || (present == nil && !value.IsZero()) {
+			continue
+		}
+		if err := setValue(valueElement, fieldPath, defaultValue); err != nil {
+			return false, err
+		}
+		if value.Kind() == reflect.Pointer {
+			value.Set(valueElement.Addr())
+		}
+		if record != nil {
+			record(fieldPath)
+		}
+		isSetFields = true
+	}
+	return isSetFields, nil
+}
+
+// Validate 按 required/min/max/oneof/regex tag 校验配置, 返回所有不合法的字段
+func Validate(dst interface{}) error {
+	v := reflect.ValueOf(dst)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return fmt.Errorf("invalid value: must be a non-nil pointer")
+		}
+		v = v.Elem()
+	}
+
+	var errs ValidationErrors
+	validateStruct("", v, &errs)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func validateStruct(path string, v reflect.Value, errs *ValidationErrors) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		validateField(joinFieldPath(path, field), field, v.Field(i), errs)
+	}
+}
+
+func validateField(path string, field reflect.StructField, value reflect.Value, errs *ValidationErrors) {
+	required := field.Tag.Get(tagRequired) == "true"
+
+	// 嵌套结构体即使是零值也要展开, 否则其中 required 的字段不会被校验
+	// nil 指针视为没有配置这一段, 只检查自身的 required
+	element := value
+	if element.Kind() == reflect.Pointer && !element.IsNil() {
+		element = element.Elem()
+	}
+	if element.Kind() == reflect.Struct && !isTextType(element.Type()) {
+		if required && value.IsZero() {
+			*errs = append(*errs, &FieldError{Path: path, Message: "is required"})
+		}
+		validateStruct(path, element, errs)
+		return
+	}
+
+	if value.IsZero() {
+		if required {
+			*errs = append(*errs, &FieldError{Path: path, Message: "is required"})
+		}
+		return
+	}
+	element = value
+	if element.Kind() == reflect.Pointer {
+		element = element.Elem()
+	}
+
+	switch {
+	case element.Kind() == reflect.Slice && isStructType(element.Type().Elem()):
+		for i := 0; i < element.Len(); i++ {
+			item := element.Index(i)
+			if item.Kind() == reflect.Pointer {
+				if item.IsNil() {
+					continue
+				}
+				item = item.Elem()
+			}
+			validateStruct(fmt.Sprintf("%s[%d]", path, i), item, errs)
+		}
+	}
+
+	if msg := checkRange(field, element); msg != "" {
+		*errs = append(*errs, &FieldError{Path: path, Message: msg})
+	}
+	if msg := checkOneof(field, element); msg != "" {
+		*errs = append(*errs, &FieldError{Path: path, Message: msg})
+	}
+	if msg := checkRegex(field, element); msg != "" {
+		*errs = append(*errs, &FieldError{Path: path, Message: msg})
+	}
+}
+
+func checkRange(field reflect.StructField, value reflect.Value) string {
+	minTag, hasMin := field.Tag.Lookup(tagMin)
+	maxTag, hasMax := field.Tag.Lookup(tagMax)
+	if !hasMin && !hasMax {
+		return ""
+	}
+
+	var (
+		current  float64
+		parse    = func(s string) (float64, error) { return strconv.ParseFloat(s, 64) }
+		unitDesc string
+	)
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		current = float64(value.Int())
+		if value.Type() == durationType {
+			parse = func(s string) (float64, error) {
+				d, err := time.ParseDuration(s)
+				return float64(d), err
+			}
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		current = float64(value.Uint())
+	case reflect.Float32, reflect.Float64:
+		current = value.Float()
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		current = float64(value.Len())
+		unitDesc = "length "
+	default:
+		return fmt.Sprintf("min/max is not supported for %s", value.Type())
+	}
+
+	lower, upper := minTag, maxTag
+	if hasMin {
+		bound, err := parse(minTag)
+		if err != nil {
+			return fmt.Sprintf("invalid min tag %q", minTag)
+		}
+		if current < bound {
+			return rangeMessage(unitDesc, lower, upper, hasMin, hasMax)
+		}
+	}
+	if hasMax {
+		bound, err := parse(maxTag)
+		if err != nil {
+			return fmt.Sprintf("invalid max tag %q", maxTag)
+		}
+		if current > bound {
+			return rangeMessage(unitDesc, lower, upper, hasMin, hasMax)
+		}
+	}
+	return ""
+}
+
+func rangeMessage(unitDesc, lower, upper string, hasMin, hasMax bool) string {
+	switch {
+	case hasMin && hasMax:
+		return fmt.Sprintf("%smust be %s..%s", unitDesc, lower, upper)
+	case hasMin:
+		return fmt.Sprintf("%smust be >= %s", unitDesc, lower)
+	default:
+		return fmt.Sprintf("%smust be <= %s", unitDesc, upper)
+	}
+}
+
+func checkOneof(field reflect.StructField, value reflect.Value) string {
+	oneof, ok := field.Tag.Lookup(tagOneof)
+	if !ok {
+		return ""
+	}
+	options := strings.Fields(oneof)
+	current := fmt.Sprint(value.Interface())
+	for _, option := range options {
+		if current == option {
+			return ""
+		}
+	}
+	return fmt.Sprintf("must be one of [%s]", strings.Join(options, " "))
+}
+
+func checkRegex(field reflect.StructField, value reflect.Value) string {
+	pattern, ok := field.Tag.Lookup(tagRegex)
+	if !ok {
+		return ""
+	}
+	if value.Kind() != reflect.String {
+		return fmt.Sprintf("regex is not supported for %s", value.Type())
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return fmt.Sprintf("invalid regex tag %q", pattern)
+	}
+	if !re.MatchString(value.String()) {
+		return fmt.Sprintf("must match %s", pattern)
+	}
+	return ""
+}
+
+// joinFieldPath 按 json > yaml > 字段名 的优先级拼接字段路径
+func joinFieldPath(path string, field reflect.StructField) string {
+	name := fieldName(field)
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// fieldName 字段在配置中的名称, 优先级为 json > yaml > 字段名
+func fieldName(field reflect.StructField) string {
+	for _, key := range []string{"json", "yaml"} {
+		tagName, _, _ := strings.Cut(field.Tag.Get(key), ",")
+		if tagName != "" && tagName != "-" {
+			return tagName
+		}
+	}
+	return field.Name
+}
+
+// lookupKey 查找字段在配置 map 中的值, 同时匹配 proto 字段的 json 名称, 忽略大小写
+func lookupKey(m map[string]interface{}, field reflect.StructField) (interface{}, bool) {
+	names := []string{fieldName(field)}
+	for _, opt := range strings.Split(field.Tag.Get("protobuf"), ",") {
+		if name, ok := strings.CutPrefix(opt, "json="); ok {
+			names = append(names, name)
+		}
+	}
+	for k, v := range m {
+		for _, name := range names {
+			if strings.EqualFold(k, name) {
+				return v, true
+			}
+		}
+	}
+	return nil, false
+}
+
+func isStructType(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct
+}
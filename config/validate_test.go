@@ -0,0 +1,129 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLoadConfigDefaults(t *testing.T) {
+	type mysqlConfig struct {
+		Host    string        `json:"host" default:"127.0.0.1"`
+		Port    uint32        `json:"port" default:"3306"`
+		Timeout time.Duration `json:"timeout" default:"3000000000"`
+		Tags    []string      `json:"tags" default:"a,b"`
+	}
+	type testConfig struct {
+		Level string       `json:"level" default:"INFO"`
+		MySQL *mysqlConfig `json:"mysql"`
+	}
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("mysql:\n  host: 10.0.0.1\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var dst testConfig
+	if err := LoadConfig(&dst, WithEnv("TEST_DEFAULTS"), WithYaml(path)); err != nil {
+		t.Fatal(err)
+	}
+	want := testConfig{
+		Level: "INFO",
+		MySQL: &mysqlConfig{
+			Host:    "10.0.0.1",
+			Port:    3306,
+			Timeout: time.Second * 3,
+			Tags:    []string{"a", "b"},
+		},
+	}
+	if !reflect.DeepEqual(dst, want) {
+		t.Errorf("dst====%+v\nwant====%+v", dst, want)
+	}
+}
+
+func TestLoadConfigValidate(t *testing.T) {
+	type server struct {
+		Host string `json:"host" required:"true"`
+	}
+	type mysqlConfig struct {
+		Host string `json:"host" required:"true"`
+		Port uint32 `json:"port" min:"1" max:"65535"`
+	}
+	type testConfig struct {
+		Level   string       `json:"level" oneof:"DEBUG INFO"`
+		Name    string       `json:"name" regex:"^[a-z]+$"`
+		Tags    []string     `json:"tags" max:"1"`
+		MySQL   *mysqlConfig `json:"mysql"`
+		Servers []server     `json:"servers"`
+	}
+
+	os.Setenv("TEST_VALIDATE_LEVEL", "TRACE")
+	os.Setenv("TEST_VALIDATE_NAME", "Abc")
+	os.Setenv("TEST_VALIDATE_TAGS", "a,b")
+	os.Setenv("TEST_VALIDATE_MYSQL_PORT", "70000")
+
+	dst := testConfig{Servers: []server{{Host: "ok"}, {}}}
+	err := LoadConfig(&dst, WithEnv("TEST_VALIDATE"))
+
+	var errs ValidationErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("err === %v", err)
+	}
+	got := make([]string, 0, len(errs))
+	for _, fe := range errs {
+		got = append(got, fe.Error())
+	}
+	want := []string{
+		"level: must be one of [DEBUG INFO]",
+		"name: must match ^[a-z]+$",
+		"tags: length must be <= 1",
+		"mysql.host: is required",
+		"mysql.port: must be 1..65535",
+		"servers[1].host: is required",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got====%q\nwant====%q", got, want)
+	}
+}
+
+func TestLoadConfigDefaultsKeepExplicitZero(t *testing.T) {
+	type testConfig struct {
+		Enabled bool   `json:"enabled" default:"true"`
+		Port    int    `json:"port" default:"8080"`
+		Level   string `json:"level" default:"INFO"`
+		Name    string `json:"name" default:"app"`
+	}
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("enabled: false\nport: 0\nlevel: \"\"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var dst testConfig
+	if err := LoadConfig(&dst, WithYaml(path)); err != nil {
+		t.Fatal(err)
+	}
+	want := testConfig{Name: "app"}
+	if !reflect.DeepEqual(dst, want) {
+		t.Errorf("dst====%+v\nwant====%+v", dst, want)
+	}
+}
+
+func TestValidateZeroNestedStruct(t *testing.T) {
+	type mysqlConfig struct {
+		Host string `json:"host" required:"true"`
+	}
+	type testConfig struct {
+		MySQL mysqlConfig  `json:"mysql"`
+		Redis *mysqlConfig `json:"redis"`
+	}
+
+	err := Validate(&testConfig{})
+	var errs ValidationErrors
+	if !errors.As(err, &errs) || len(errs) != 1 || errs[0].Error() != "mysql.host: is required" {
+		t.Errorf("err === %v", err)
+	}
+}
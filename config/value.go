@@ -0,0 +1,190 @@
+package config
+
+import (
+	"encoding"
+	"flag"
+	"fmt"
+	"reflect"
+)
+
+var (
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	flagValueType       = reflect.TypeOf((*flag.Value)(nil)).Elem()
+)
+
+// isTextType 判断类型(忽略指针)是否实现了 encoding.TextUnmarshaler 或 flag.Value, 这类类型作为整体从字符串解析
+func isTextType(t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Interface {
+		return false
+	}
+	ptr := reflect.PointerTo(t)
+	return ptr.Implements(textUnmarshalerType) || ptr.Implements(flagValueType)
+}
+
+// isValueType 判断类型能否由 setValue 从字符串解析
+func isValueType(t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if isTextType(t) || t == durationType {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	case reflect.Slice:
+		return isValueType(t.Elem())
+	case reflect.Map:
+		return isValueType(t.Key()) && isValueType(t.Elem())
+	default:
+		return false
+	}
+}
+
+// isFlagStruct 判断字段是否需要展开注册 flag, 即(指向)结构体且不能整体从字符串解析
+func isFlagStruct(t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct && !isTextType(t)
+}
+
+// isReflectFlag 判断字段是否需要通过 reflectFlag 注册, 内置类型及其切片仍然使用 FlagSet 自带的方法
+func isReflectFlag(t reflect.Type) bool {
+	if isTextType(t) || t.PkgPath() != "" {
+		return true
+	}
+	if t.Kind() == reflect.Slice {
+		t = t.Elem()
+		if t.PkgPath() != "" {
+			return true
+		}
+	}
+	switch t.Kind() {
+	case reflect.String, reflect.Int, reflect.Float64, reflect.Int64, reflect.Uint, reflect.Uint64, reflect.Bool:
+		return false
+	default:
+		return true
+	}
+}
+
+var _ flag.Value = (*reflectFlag)(nil)
+
+// reflectFlag 使用 setValue 解析的 flag.Value, 同时满足 pflag.Value
+type reflectFlag struct {
+	value reflect.Value
+	name  string
+}
+
+func (x *reflectFlag) String() string {
+	if x == nil || !x.value.IsValid() {
+		return ""
+	}
+	v := x.value
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	if v.CanAddr() {
+		if m, ok := v.Addr().Interface().(encoding.TextMarshaler); ok {
+			bs, _ := m.MarshalText()
+			return string(bs)
+		}
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+func (x *reflectFlag) Set(value string) error {
+	return setValue(x.value, x.name, value)
+}
+
+func (x *reflectFlag) Type() string {
+	return x.value.Type().String()
+}
+
+// IsBoolFlag 允许 bool 指针等类型只写 -name 不带值
+func (x *reflectFlag) IsBoolFlag() bool {
+	t := x.value.Type()
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Bool
+}
+
+// flagBinder 处理 nil 结构体指针中的 flag
+// 注册时 flag 绑定到临时分配的结构体, 解析后只有设置了其中的 flag 才赋值回原字段, 否则保持 nil
+type flagBinder struct {
+	pending []func()
+	commits map[string][]func()
+	// aliases 标准库 flag 的短名称, [短名称, 名称]
+	aliases [][2]string
+
+	// paths flag 对应的字段路径, 被设置时通过 record 记录, 见 WithProvenance
+	paths  map[string]string
+	record func(path string)
+}
+
+func newFlagBinder(record func(path string)) *flagBinder {
+	return &flagBinder{
+		commits: make(map[string][]func()),
+		paths:   make(map[string]string),
+		record:  record,
+	}
+}
+
+// enter 返回字段对应的结构体指针, 处理完该结构体后需要调用 leave
+func (x *flagBinder) enter(value reflect.Value) (reflect.Value, func()) {
+	var depth int
+	for value.Kind() == reflect.Pointer {
+		if !value.IsNil() {
+			value = value.Elem()
+			continue
+		}
+		field, elem := value, reflect.New(value.Type().Elem())
+		x.pending = append(x.pending, func() {
+			if field.IsNil() {
+				field.Set(elem)
+			}
+		})
+		depth++
+		value = elem.Elem()
+	}
+	return value.Addr(), func() {
+		x.pending = x.pending[:len(x.pending)-depth]
+	}
+}
+
+// bind 记录 flag 对应的字段路径和所在的临时结构体
+func (x *flagBinder) bind(name, path string) {
+	x.paths[name] = path
+	if len(x.pending) > 0 {
+		x.commits[name] = append([]func(){}, x.pending...)
+	}
+}
+
+// commit 在 flag 被设置后将其所在的临时结构体赋值回原字段
+func (x *flagBinder) commit(name string) {
+	for _, fn := range x.commits[name] {
+		fn()
+	}
+	if path, ok := x.paths[name]; ok && x.record != nil {
+		x.record(path)
+	}
+}
+
+// alias 记录标准库 flag 的短名称, 短名称被设置时同样需要赋值回原字段
+func (x *flagBinder) alias(short, name string) {
+	x.aliases = append(x.aliases, [2]string{short, name})
+	x.paths[short] = x.paths[name]
+	if commits, ok := x.commits[name]; ok {
+		x.commits[short] = commits
+	}
+}
@@ -0,0 +1,281 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const defaultWatchInterval = time.Second * 3
+
+// WithWatchInterval 设置 Watch 轮询配置文件的间隔, 默认 3s
+func WithWatchInterval(interval time.Duration) ConfigOption {
+	return func(options *ConfigOptions) {
+		options.watchInterval = interval
+	}
+}
+
+// WithWatchErrorHandler 设置 Watch 重新加载失败时的回调, 默认打印日志并保留旧配置
+func WithWatchErrorHandler(handler func(err error)) ConfigOption {
+	return func(options *ConfigOptions) {
+		options.watchErrorHandler = handler
+	}
+}
+
+// Watcher 监听配置文件变化并热加载配置
+// 每次变化都会按 yaml > env > flag 的完整链路加载到一份新的 T 中, 再原子替换,
+// 读取方通过 Load 拿到的永远是一份完整的配置, 拿到的配置只读, 不要修改
+type Watcher[T any] struct {
+	options ConfigOptions
+	value   atomic.Pointer[T]
+
+	mu     sync.Mutex
+	digest []byte
+
+	callbackMu sync.Mutex
+	callbacks  []func(oldValue, newValue *T)
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// Watch 加载配置并监听 WithYaml 指定的文件和 WithSource 中的 WatchableSource, 内容变化时重新加载
+func Watch[T any](opts ...ConfigOption) (*Watcher[T], error) {
+	c := defaultConfigOptions()
+	for _, opt := range opts {
+		opt(&c)
+	}
+	if !c.hasWatchable() {
+		return nil, errors.New("config watch: no watchable source, use WithYaml or WithSource")
+	}
+	if c.watchInterval <= 0 {
+		c.watchInterval = defaultWatchInterval
+	}
+
+	digest, err := c.filesDigest()
+	if err != nil {
+		return nil, err
+	}
+	c.snapshotAppFlags()
+	dst := new(T)
+	if err := loadConfig(dst, c); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(c.ctx)
+	w := &Watcher[T]{
+		options: c,
+		digest:  digest,
+		cancel:  cancel,
+		done:    make(chan struct{}),
+	}
+	w.value.Store(dst)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		w.run(ctx)
+	}()
+	for _, source := range c.sources {
+		ws, ok := source.(WatchableSource)
+		if !ok {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.watchSource(ctx, ws)
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(w.done)
+	}()
+	return w, nil
+}
+
+// Load 返回当前生效的配置
+func (w *Watcher[T]) Load() *T {
+	return w.value.Load()
+}
+
+// OnChange 注册配置变更回调, 回调在新配置生效后按注册顺序同步执行
+// 回调中不要调用 Reload, 否则会死锁
+func (w *Watcher[T]) OnChange(fn func(oldValue, newValue *T)) {
+	w.callbackMu.Lock()
+	defer w.callbackMu.Unlock()
+	w.callbacks = append(w.callbacks, fn)
+}
+
+// Reload 立即重新加载配置, 不论文件是否变化
+func (w *Watcher[T]) Reload() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	digest, err := w.options.filesDigest()
+	if err != nil {
+		return err
+	}
+	return w.reload(digest)
+}
+
+// Close 停止监听
+func (w *Watcher[T]) Close() error {
+	w.cancel()
+	<-w.done
+	return nil
+}
+
+func (w *Watcher[T]) run(ctx context.Context) {
+	ticker := time.NewTicker(w.options.watchInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := w.check(); err != nil {
+				w.handleError(err)
+			}
+		}
+	}
+}
+
+func (w *Watcher[T]) watchSource(ctx context.Context, source WatchableSource) {
+	for {
+		err := source.Watch(ctx, func() {
+			if err := w.Reload(); err != nil {
+				w.handleError(err)
+			}
+		})
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			w.handleError(err)
+		}
+		// 监听异常退出后等待一个周期再重新监听
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(w.options.watchInterval):
+		}
+	}
+}
+
+func (w *Watcher[T]) check() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	digest, err := w.options.filesDigest()
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(digest, w.digest) {
+		return nil
+	}
+	return w.reload(digest)
+}
+
+// reload 需要持有 w.mu
+func (w *Watcher[T]) reload(digest []byte) error {
+	options := w.options.forkFlagSets()
+	if options.provenance != nil {
+		// 加载失败时保留旧配置, 所以先记录到新的 Provenance, 生效后再替换
+		options.provenance = NewProvenance()
+	}
+	dst := new(T)
+	if err := loadConfig(dst, options); err != nil {
+		return err
+	}
+	w.digest = digest
+
+	if options.provenance != nil {
+		w.options.provenance.store(options.provenance.Explain())
+	}
+	oldValue := w.value.Swap(dst)
+
+	w.callbackMu.Lock()
+	callbacks := w.callbacks
+	w.callbackMu.Unlock()
+	for _, fn := range callbacks {
+		fn(oldValue, dst)
+	}
+	return nil
+}
+
+func (w *Watcher[T]) handleError(err error) {
+	if w.options.watchErrorHandler != nil {
+		w.options.watchErrorHandler(err)
+		return
+	}
+	log.Printf("config watch: reload failed, keep previous config: %v", err)
+}
+
+// snapshotAppFlags 记录加载前 FlagSet 中业务自己注册的 flag, 见 flagSource.snapshot
+func (c *ConfigOptions) snapshotAppFlags() {
+	for _, source := range c.layers() {
+		switch source := source.(type) {
+		case *flagSource:
+			source.snapshot()
+		case *pflagSource:
+			source.snapshot()
+		}
+	}
+}
+
+// forkFlagSets 返回使用全新 FlagSet 的配置副本, 见 flagSource.fork
+func (c ConfigOptions) forkFlagSets() ConfigOptions {
+	if c.flag != nil {
+		c.flag = c.flag.fork()
+	}
+	if c.pflag != nil {
+		c.pflag = c.pflag.fork()
+	}
+	sources := make([]Source, 0, len(c.sources))
+	for _, source := range c.sources {
+		switch source := source.(type) {
+		case *flagSource:
+			sources = append(sources, source.fork())
+		case *pflagSource:
+			sources = append(sources, source.fork())
+		default:
+			sources = append(sources, source)
+		}
+	}
+	c.sources = sources
+	return c
+}
+
+func (c ConfigOptions) hasWatchable() bool {
+	for _, source := range c.sources {
+		switch source.(type) {
+		case *fileSource, WatchableSource:
+			return true
+		}
+	}
+	return false
+}
+
+// filesDigest 计算所有文件配置源的摘要, 用于轮询判断文件是否变化
+func (c ConfigOptions) filesDigest() ([]byte, error) {
+	h := sha256.New()
+	for _, source := range c.sources {
+		fs, ok := source.(*fileSource)
+		if !ok {
+			continue
+		}
+		digest, err := fs.digest()
+		if err != nil {
+			return nil, err
+		}
+		h.Write(digest)
+	}
+	return h.Sum(nil), nil
+}
@@ -0,0 +1,228 @@
+package config
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatch(t *testing.T) {
+	type appConfig struct {
+		Name string `json:"name"`
+		Port int    `json:"port"`
+	}
+
+	path := filepath.Join(t.TempDir(), "app.yaml")
+	if err := os.WriteFile(path, []byte("name: v1\nport: 8000\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := Watch[appConfig](
+		WithYaml(path),
+		WithEnv("TEST_WATCH"),
+		WithWatchInterval(time.Millisecond*10),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	if got := w.Load(); got.Name != "v1" || got.Port != 8000 {
+		t.Fatalf("initial config === %+v", got)
+	}
+
+	changed := make(chan [2]*appConfig, 1)
+	w.OnChange(func(oldValue, newValue *appConfig) {
+		changed <- [2]*appConfig{oldValue, newValue}
+	})
+
+	if err := os.WriteFile(path, []byte("name: v2\nport: 9000\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case values := <-changed:
+		if values[0].Name != "v1" || values[1].Name != "v2" || values[1].Port != 9000 {
+			t.Errorf("old====%+v\nnew====%+v", values[0], values[1])
+		}
+	case <-time.After(time.Second * 3):
+		t.Fatal("config change not detected")
+	}
+
+	if got := w.Load(); got.Name != "v2" {
+		t.Errorf("current config === %+v", got)
+	}
+}
+
+func TestWatchKeepPreviousOnError(t *testing.T) {
+	type appConfig struct {
+		Port int `json:"port"`
+	}
+
+	path := filepath.Join(t.TempDir(), "app.yaml")
+	if err := os.WriteFile(path, []byte("port: 8000\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	errCh := make(chan error, 1)
+	w, err := Watch[appConfig](
+		WithYaml(path),
+		WithWatchInterval(time.Millisecond*10),
+		WithWatchErrorHandler(func(err error) {
+			select {
+			case errCh <- err:
+			default:
+			}
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	if err := os.WriteFile(path, []byte("port: [not-a-number\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-errCh:
+	case <-time.After(time.Second * 3):
+		t.Fatal("reload error not reported")
+	}
+	if got := w.Load(); got.Port != 8000 {
+		t.Errorf("current config === %+v", got)
+	}
+}
+
+func TestWatchReloadKeepsAppFlags(t *testing.T) {
+	type appConfig struct {
+		Name string `json:"name"`
+		Port int    `json:"port" flag:"port"`
+	}
+
+	path := filepath.Join(t.TempDir(), "app.yaml")
+	if err := os.WriteFile(path, []byte("name: v1\nport: 8000\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	flagSet := flag.NewFlagSet("app", flag.ContinueOnError)
+	conf := flagSet.String("conf", "", "config file")
+	w, err := Watch[appConfig](
+		WithYaml(path),
+		WithFlag(flagSet, []string{"-conf", path, "-port", "9000"}),
+		WithWatchInterval(time.Hour),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	if err := os.WriteFile(path, []byte("name: v2\nport: 8000\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if got := w.Load(); got.Name != "v2" || got.Port != 9000 || *conf != path {
+		t.Errorf("config====%+v conf====%s", got, *conf)
+	}
+}
+
+func TestWatchProvenance(t *testing.T) {
+	type appConfig struct {
+		Name string `json:"name" default:"app"`
+		Port int    `json:"port"`
+	}
+
+	path := filepath.Join(t.TempDir(), "app.yaml")
+	if err := os.WriteFile(path, []byte("port: 8000\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	provenance := NewProvenance()
+	w, err := Watch[appConfig](
+		WithYaml(path),
+		WithWatchInterval(time.Hour),
+		WithProvenance(provenance),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	origin := "file:" + path
+	if got := provenance.Explain(); got["name"] != OriginDefault || got["port"] != origin {
+		t.Fatalf("initial origins====%v", got)
+	}
+
+	if err := os.WriteFile(path, []byte("name: app\nport: 8000\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if got := provenance.Explain(); got["name"] != origin {
+		t.Errorf("reloaded origins====%v", got)
+	}
+
+	if err := os.WriteFile(path, []byte("port: [not-a-number\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Reload(); err == nil {
+		t.Fatal("reload should fail")
+	}
+	if got := provenance.Explain(); got["name"] != origin || got["port"] != origin {
+		t.Errorf("origins after failed reload====%v", got)
+	}
+}
+
+func TestWatchReloadDoesNotWriteAppFlags(t *testing.T) {
+	type appConfig struct {
+		Name string `json:"name"`
+	}
+
+	path := filepath.Join(t.TempDir(), "app.yaml")
+	if err := os.WriteFile(path, []byte("name: v1\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	flagSet := flag.NewFlagSet("app", flag.ContinueOnError)
+	conf := flagSet.String("conf", "", "config file")
+	verbose := flagSet.Bool("v", false, "verbose")
+	w, err := Watch[appConfig](
+		WithYaml(path),
+		WithFlag(flagSet, []string{"-conf", path, "-v"}),
+		WithWatchInterval(time.Hour),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	// 重新加载在 Watch 的 goroutine 中执行, 不能写入业务正在读取的 flag 变量, 使用 -race 运行
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 20; i++ {
+			if err := w.Reload(); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		_ = *conf
+		_ = *verbose
+	}
+	<-done
+
+	*conf = "changed"
+	if err := w.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if *conf != "changed" || !*verbose {
+		t.Errorf("conf====%s verbose====%v", *conf, *verbose)
+	}
+}
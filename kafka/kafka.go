@@ -3,14 +3,13 @@ package kafka
 import (
 	"context"
 	"fmt"
-	"os"
 	"runtime/debug"
-	"strconv"
 	"strings"
 	"time"
 
 	"github.com/IBM/sarama"
 	"github.com/dnwe/otelsarama"
+	"github.com/opendevops-cn/codo-golang-sdk/config"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/metric"
 	"go.opentelemetry.io/otel/propagation"
@@ -23,31 +22,31 @@ type KafkaConfigOption func(c *KafkaConfig)
 // KafkaConfig 包含 Kafka 连接配置
 type KafkaConfig struct {
 	// Kafka Broker 地址列表
-	BootstrapServers []string `json:"bootstrap_servers" yaml:"bootstrap_servers" env:"DEFAULT_KAFKA_BOOTSTRAP_SERVERS"`
+	BootstrapServers []string `json:"bootstrap_servers" yaml:"bootstrap_servers" env:"DEFAULT_KAFKA_BOOTSTRAP_SERVERS" default:"localhost:9092" required:"true"`
 
 	// Kafka Consumer Group Id
-	GroupId string `json:"group_id" yaml:"group_id" env:"DEFAULT_KAFKA_GROUP_ID"`
+	GroupId string `json:"group_id" yaml:"group_id" env:"DEFAULT_KAFKA_GROUP_ID" default:"default-group"`
 
 	// SASL 用户名（如果需要使用 SASL 认证）
 	SASLUsername string `json:"sasl_username" yaml:"sasl_username" env:"DEFAULT_KAFKA_SASL_USERNAME"`
 
 	// SASL 密码（如果需要使用 SASL 认证）
-	SASLPassword string `json:"sasl_password" yaml:"sasl_password" env:"DEFAULT_KAFKA_SASL_PASSWORD"`
+	SASLPassword string `json:"sasl_password" yaml:"sasl_password" env:"DEFAULT_KAFKA_SASL_PASSWORD" secret:"true"`
 
 	// SASL 认证机制（如 PLAIN）
-	SASLMechanism string `json:"sasl_mechanism" yaml:"sasl_mechanism" env:"DEFAULT_KAFKA_SASL_MECHANISM"`
+	SASLMechanism string `json:"sasl_mechanism" yaml:"sasl_mechanism" env:"DEFAULT_KAFKA_SASL_MECHANISM" oneof:"PLAIN SCRAM-SHA-256 SCRAM-SHA-512 GSSAPI OAUTHBEARER"`
 
 	// Kafka 最大请求空闲时间（单位：秒）
-	MaxOpenRequests uint32 `json:"max_open_requests" yaml:"max_open_requests" env:"DEFAULT_KAFKA_MAX_OPEN_REQUESTS"`
+	MaxOpenRequests uint32 `json:"max_open_requests" yaml:"max_open_requests" env:"DEFAULT_KAFKA_MAX_OPEN_REQUESTS" default:"10"`
 
 	// DialTimeout   最大连接超时时间（单位：秒）
-	DialTimeout uint32 `json:"dial_read_timeout" yaml:"dial_read_timeout" env:"DEFAULT_KAFKA_DIAL_READ_TIMEOUT"`
+	DialTimeout uint32 `json:"dial_read_timeout" yaml:"dial_read_timeout" env:"DEFAULT_KAFKA_DIAL_READ_TIMEOUT" default:"10"`
 
 	// ReadTmout 最大读取超时时间（单位：秒）
-	ReadTimeout uint32 `json:"read_timeout" yaml:"read_timeout" env:"DEFAULT_KAFKA_READ_TIMEOUT"`
+	ReadTimeout uint32 `json:"read_timeout" yaml:"read_timeout" env:"DEFAULT_KAFKA_READ_TIMEOUT" default:"10"`
 
 	// WriteTimeout 最大写入超时时间（单位：秒）
-	WriteTimeout uint32 `json:"write_timeout" yaml:"write_timeout" env:"DEFAULT_KAFKA_WRITE_TIMEOUT"`
+	WriteTimeout uint32 `json:"write_timeout" yaml:"write_timeout" env:"DEFAULT_KAFKA_WRITE_TIMEOUT" default:"10"`
 
 	// OpenTelemetry 跟踪提供者
 	TracerProvider trace.TracerProvider `json:"-"`
@@ -59,43 +58,19 @@ type KafkaConfig struct {
 	Propagator propagation.TextMapPropagator `json:"-"`
 }
 
-func defaultConfig() KafkaConfig {
-	emptyOr := func(str string, defaultVal string) string {
-		str = strings.TrimSpace(str)
-		if str == "" {
-			return defaultVal
-		}
-		return str
-	}
-	bootstrapServers := emptyOr(os.Getenv("DEFAULT_KAFKA_BOOTSTRAP_SERVERS"), "localhost:9092")
-	groupId := emptyOr(os.Getenv("DEFAULT_KAFKA_GROUP_ID"), "default-group")
-	saslUserName := emptyOr(os.Getenv("DEFAULT_KAFKA_SASL_USERNAME"), "")
-	saslPassword := emptyOr(os.Getenv("DEFAULT_KAFKA_SASL_PASSWORD"), "")
-	saslMechanism := emptyOr(os.Getenv("DEFAULT_KAFKA_SASL_MECHANISM"), "")
-	dialTimeout := emptyOr(os.Getenv("DEFAULT_KAFKA_DIAL_READ_TIMEOUT"), "10")
-	readTimeout := emptyOr(os.Getenv("DEFAULT_KAFKA_READ_TIMEOUT"), "10")
-	writeTimeout := emptyOr(os.Getenv("DEFAULT_KAFKA_WRITE_TIMEOUT"), "10")
-	maxOpenRequests := emptyOr(os.Getenv("DEFAULT_KAFKA_MAX_OPEN_REQUESTS"), "10")
-	bootstrapServersList := strings.Split(bootstrapServers, ",")
-	i64dialTimeout, _ := strconv.Atoi(dialTimeout)
-	i64reeadTimeout, _ := strconv.Atoi(readTimeout)
-	i64writeTimeout, _ := strconv.Atoi(writeTimeout)
-	i64maxOpenRequests, _ := strconv.Atoi(maxOpenRequests)
-
-	return KafkaConfig{
-		BootstrapServers: bootstrapServersList,
-		GroupId:          groupId,
-		SASLUsername:     saslUserName,
-		SASLPassword:     saslPassword,
-		SASLMechanism:    saslMechanism,
-		DialTimeout:      uint32(i64dialTimeout),
-		ReadTimeout:      uint32(i64reeadTimeout),
-		WriteTimeout:     uint32(i64writeTimeout),
-		MaxOpenRequests:  uint32(i64maxOpenRequests),
-		TracerProvider:   otel.GetTracerProvider(),
-		MeterProvider:    otel.GetMeterProvider(),
-		Propagator:       otel.GetTextMapPropagator(),
+func defaultConfig() (KafkaConfig, error) {
+	cfg := KafkaConfig{
+		TracerProvider: otel.GetTracerProvider(),
+		MeterProvider:  otel.GetMeterProvider(),
+		Propagator:     otel.GetTextMapPropagator(),
+	}
+	if err := config.ApplyDefaults(&cfg); err != nil {
+		return cfg, err
 	}
+	if err := config.LoadEnv("", &cfg); err != nil {
+		return cfg, err
+	}
+	return cfg, nil
 }
 
 // WithBrokerAddrs 配置 Kafka BootstrapServer 地址列表
@@ -156,34 +131,43 @@ func WithDialTimeout(t uint32) KafkaConfigOption {
 }
 
 func NewProducer(opts ...KafkaConfigOption) (sarama.AsyncProducer, func(), error) {
-	kafkaConf := defaultConfig()
+	kafkaConf, err := defaultConfig()
+	if err != nil {
+		return nil, nil, err
+	}
 	for _, opt := range opts {
 		opt(&kafkaConf)
 	}
-	config := sarama.NewConfig()
-	config.Version = sarama.V2_5_0_0
+	if err := config.ResolveSecrets(&kafkaConf, ""); err != nil {
+		return nil, nil, err
+	}
+	if err := config.Validate(&kafkaConf); err != nil {
+		return nil, nil, err
+	}
+	saramaConf := sarama.NewConfig()
+	saramaConf.Version = sarama.V2_5_0_0
 	// So we can know the partition and offset of messages.
-	config.Producer.Return.Successes = true
+	saramaConf.Producer.Return.Successes = true
 
 	// 配置 SASL 认证
 	if kafkaConf.SASLUsername != "" && kafkaConf.SASLPassword != "" {
-		config.Net.SASL.Enable = true
-		config.Net.SASL.User = kafkaConf.SASLUsername
-		config.Net.SASL.Password = kafkaConf.SASLPassword
-		config.Net.SASL.Mechanism = sarama.SASLMechanism(kafkaConf.SASLMechanism)
+		saramaConf.Net.SASL.Enable = true
+		saramaConf.Net.SASL.User = kafkaConf.SASLUsername
+		saramaConf.Net.SASL.Password = kafkaConf.SASLPassword
+		saramaConf.Net.SASL.Mechanism = sarama.SASLMechanism(kafkaConf.SASLMechanism)
 	}
-	config.Net.MaxOpenRequests = int(kafkaConf.MaxOpenRequests)
-	config.Net.DialTimeout = time.Second * time.Duration(kafkaConf.DialTimeout)
-	config.Net.ReadTimeout = time.Second * time.Duration(kafkaConf.ReadTimeout)
-	config.Net.WriteTimeout = time.Second * time.Duration(kafkaConf.WriteTimeout)
+	saramaConf.Net.MaxOpenRequests = int(kafkaConf.MaxOpenRequests)
+	saramaConf.Net.DialTimeout = time.Second * time.Duration(kafkaConf.DialTimeout)
+	saramaConf.Net.ReadTimeout = time.Second * time.Duration(kafkaConf.ReadTimeout)
+	saramaConf.Net.WriteTimeout = time.Second * time.Duration(kafkaConf.WriteTimeout)
 
-	producer, err := sarama.NewAsyncProducer(kafkaConf.BootstrapServers, config)
+	producer, err := sarama.NewAsyncProducer(kafkaConf.BootstrapServers, saramaConf)
 	if err != nil {
 		return nil, nil, fmt.Errorf("starting Sarama producer: %w", err)
 	}
 
 	// Wrap instrumentation
-	producer = otelsarama.WrapAsyncProducer(config, producer,
+	producer = otelsarama.WrapAsyncProducer(saramaConf, producer,
 		otelsarama.WithTracerProvider(kafkaConf.TracerProvider),
 		otelsarama.WithPropagators(kafkaConf.Propagator),
 	)
@@ -223,27 +207,36 @@ func NewProducer(opts ...KafkaConfigOption) (sarama.AsyncProducer, func(), error
 }
 
 func NewConsumerGroup(opts ...KafkaConfigOption) (sarama.ConsumerGroup, func(), error) {
-	kafkaConf := defaultConfig()
+	kafkaConf, err := defaultConfig()
+	if err != nil {
+		return nil, nil, err
+	}
 	for _, opt := range opts {
 		opt(&kafkaConf)
 	}
-	config := sarama.NewConfig()
-	config.Version = sarama.V2_5_0_0
-	config.Consumer.Offsets.Initial = sarama.OffsetOldest
+	if err := config.ResolveSecrets(&kafkaConf, ""); err != nil {
+		return nil, nil, err
+	}
+	if err := config.Validate(&kafkaConf); err != nil {
+		return nil, nil, err
+	}
+	saramaConf := sarama.NewConfig()
+	saramaConf.Version = sarama.V2_5_0_0
+	saramaConf.Consumer.Offsets.Initial = sarama.OffsetOldest
 
 	// 配置 SASL 认证
 	if kafkaConf.SASLUsername != "" && kafkaConf.SASLPassword != "" {
-		config.Net.SASL.Enable = true
-		config.Net.SASL.User = kafkaConf.SASLUsername
-		config.Net.SASL.Password = kafkaConf.SASLPassword
-		config.Net.SASL.Mechanism = sarama.SASLMechanism(kafkaConf.SASLMechanism)
+		saramaConf.Net.SASL.Enable = true
+		saramaConf.Net.SASL.User = kafkaConf.SASLUsername
+		saramaConf.Net.SASL.Password = kafkaConf.SASLPassword
+		saramaConf.Net.SASL.Mechanism = sarama.SASLMechanism(kafkaConf.SASLMechanism)
 	}
-	config.Net.MaxOpenRequests = int(kafkaConf.MaxOpenRequests)
-	config.Net.DialTimeout = time.Second * time.Duration(kafkaConf.DialTimeout)
-	config.Net.ReadTimeout = time.Second * time.Duration(kafkaConf.ReadTimeout)
-	config.Net.WriteTimeout = time.Second * time.Duration(kafkaConf.WriteTimeout)
+	saramaConf.Net.MaxOpenRequests = int(kafkaConf.MaxOpenRequests)
+	saramaConf.Net.DialTimeout = time.Second * time.Duration(kafkaConf.DialTimeout)
+	saramaConf.Net.ReadTimeout = time.Second * time.Duration(kafkaConf.ReadTimeout)
+	saramaConf.Net.WriteTimeout = time.Second * time.Duration(kafkaConf.WriteTimeout)
 
-	consumerGroup, err := sarama.NewConsumerGroup(kafkaConf.BootstrapServers, kafkaConf.GroupId, config)
+	consumerGroup, err := sarama.NewConsumerGroup(kafkaConf.BootstrapServers, kafkaConf.GroupId, saramaConf)
 	if err != nil {
 		return nil, nil, fmt.Errorf("starting consumer group: %w\n Stack trace: %s", err, string(debug.Stack()))
 	}
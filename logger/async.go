@@ -0,0 +1,276 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.uber.org/zap/zapcore"
+)
+
+// OverflowPolicy decides what the async writer does when its buffer is full.
+type OverflowPolicy string
+
+const (
+	// OverflowBlock blocks the caller until the buffer has room.
+	OverflowBlock = OverflowPolicy("block")
+	// OverflowDropNewest drops the log being written.
+	OverflowDropNewest = OverflowPolicy("drop_newest")
+	// OverflowDropDebugFirst evicts the oldest buffered log of the lowest level if it is lower than
+	// the log being written, debug logs go first, otherwise the log being written is dropped.
+	OverflowDropDebugFirst = OverflowPolicy("drop_debug_first")
+)
+
+const (
+	// DefaultAsyncDroppedName is the counter of logs dropped by the async writer.
+	DefaultAsyncDroppedName = "log_dropped_total"
+	// DefaultAsyncQueueDepthName is the gauge of logs buffered by the async writer.
+	DefaultAsyncQueueDepthName = "log_queue_depth"
+)
+
+// AsyncConfig makes NewLogger write through a bounded buffer in background.
+// Async writing is disabled when BufferSize is 0.
+type AsyncConfig struct {
+	// BufferSize is the number of logs the buffer holds.
+	BufferSize int `json:"bufferSize" yaml:"bufferSize" env:"DEFAULT_LOG_ASYNC_BUFFER_SIZE"`
+	// Overflow defaults to OverflowBlock.
+	Overflow OverflowPolicy `json:"overflow" yaml:"overflow" env:"DEFAULT_LOG_ASYNC_OVERFLOW"`
+	// FlushInterval is the interval to sync the underlying writers, defaults to 1 second.
+	FlushInterval time.Duration `json:"flushInterval" yaml:"flushInterval" env:"DEFAULT_LOG_ASYNC_FLUSH_INTERVAL"`
+}
+
+// WithAsync sets the async writing of NewLogger.
+func WithAsync(c AsyncConfig) LogConfigOption {
+	return func(lc *LogConfig) {
+		lc.Async = c
+	}
+}
+
+// WithMeterProvider sets the meter provider of the async writer metrics.
+func WithMeterProvider(mp metric.MeterProvider) LogConfigOption {
+	return func(lc *LogConfig) {
+		lc.MeterProvider = mp
+	}
+}
+
+var errAsyncClosed = errors.New("logger: async writer closed")
+
+type asyncEntry struct {
+	level zapcore.Level
+	data  []byte
+}
+
+var _ levelWriteSyncer = (*asyncWriter)(nil)
+
+// asyncWriter buffers encoded logs in a ring and writes them to w in one goroutine,
+// so w is never written or synced concurrently.
+type asyncWriter struct {
+	w        levelWriteSyncer
+	policy   OverflowPolicy
+	interval time.Duration
+	dropped  metric.Int64Counter
+	attrs    []attribute.KeyValue
+
+	mu      sync.Mutex
+	notFull *sync.Cond
+	ring    []asyncEntry
+	head    int
+	size    int
+	closed  bool
+
+	notify  chan struct{}
+	syncReq chan chan error
+	closing chan struct{}
+	done    chan struct{}
+	gauge   metric.Registration
+}
+
+// newAsyncWriter starts the writer goroutine of w, attrs are added to its metrics.
+func newAsyncWriter(w levelWriteSyncer, c AsyncConfig, mp metric.MeterProvider, attrs ...attribute.KeyValue) (*asyncWriter, error) {
+	if c.Overflow == "" {
+		c.Overflow = OverflowBlock
+	}
+	if c.FlushInterval <= 0 {
+		c.FlushInterval = time.Second
+	}
+	a := &asyncWriter{
+		w:        w,
+		policy:   c.Overflow,
+		interval: c.FlushInterval,
+		attrs:    attrs,
+		ring:     make([]asyncEntry, c.BufferSize),
+		notify:   make(chan struct{}, 1),
+		syncReq:  make(chan chan error),
+		closing:  make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	a.notFull = sync.NewCond(&a.mu)
+
+	meter := mp.Meter("codo/logger")
+	var err error
+	a.dropped, err = meter.Int64Counter(DefaultAsyncDroppedName, metric.WithUnit("{log}"))
+	if err != nil {
+		return nil, err
+	}
+	depth, err := meter.Int64ObservableGauge(DefaultAsyncQueueDepthName, metric.WithUnit("{log}"))
+	if err != nil {
+		return nil, err
+	}
+	a.gauge, err = meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		o.ObserveInt64(depth, int64(a.depth()), metric.WithAttributes(a.attrs...))
+		return nil
+	}, depth)
+	if err != nil {
+		return nil, err
+	}
+
+	go a.run()
+	return a, nil
+}
+
+func (a *asyncWriter) depth() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.size
+}
+
+// WriteLevel copies p into the buffer, applying the overflow policy when it is full.
+func (a *asyncWriter) WriteLevel(level zapcore.Level, p []byte) error {
+	a.mu.Lock()
+	for !a.closed && a.size == len(a.ring) {
+		switch a.policy {
+		case OverflowDropNewest:
+			a.mu.Unlock()
+			a.drop(level)
+			return nil
+		case OverflowDropDebugFirst:
+			evicted, ok := a.evictLocked(level)
+			if !ok {
+				a.mu.Unlock()
+				a.drop(level)
+				return nil
+			}
+			a.drop(evicted)
+		default:
+			a.notFull.Wait()
+		}
+	}
+	if a.closed {
+		a.mu.Unlock()
+		// the writer goroutine has stopped, write synchronously
+		<-a.done
+		return a.w.WriteLevel(level, p)
+	}
+	a.ring[(a.head+a.size)%len(a.ring)] = asyncEntry{level: level, data: append([]byte(nil), p...)}
+	a.size++
+	a.mu.Unlock()
+
+	select {
+	case a.notify <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// evictLocked removes the oldest buffered log of the lowest level if it is lower than level.
+func (a *asyncWriter) evictLocked(level zapcore.Level) (zapcore.Level, bool) {
+	victim := -1
+	for i := 0; i < a.size; i++ {
+		entry := a.ring[(a.head+i)%len(a.ring)]
+		if entry.level < level && (victim < 0 || entry.level < a.ring[(a.head+victim)%len(a.ring)].level) {
+			victim = i
+		}
+	}
+	if victim < 0 {
+		return level, false
+	}
+	evicted := a.ring[(a.head+victim)%len(a.ring)].level
+	for i := victim; i < a.size-1; i++ {
+		a.ring[(a.head+i)%len(a.ring)] = a.ring[(a.head+i+1)%len(a.ring)]
+	}
+	a.size--
+	a.ring[(a.head+a.size)%len(a.ring)] = asyncEntry{}
+	return evicted, true
+}
+
+func (a *asyncWriter) drop(level zapcore.Level) {
+	attrs := append([]attribute.KeyValue{attribute.String(LevelKey, level.CapitalString())}, a.attrs...)
+	a.dropped.Add(context.Background(), 1, metric.WithAttributes(attrs...))
+}
+
+func (a *asyncWriter) run() {
+	defer close(a.done)
+	ticker := time.NewTicker(a.interval)
+	defer ticker.Stop()
+
+	var batch []asyncEntry
+	for {
+		select {
+		case <-a.notify:
+			batch = a.flush(batch)
+		case <-ticker.C:
+			batch = a.flush(batch)
+			_ = a.w.Sync()
+		case ch := <-a.syncReq:
+			batch = a.flush(batch)
+			ch <- a.w.Sync()
+		case <-a.closing:
+			a.flush(batch)
+			_ = a.w.Sync()
+			return
+		}
+	}
+}
+
+// flush writes all buffered logs, batch is reused between calls.
+func (a *asyncWriter) flush(batch []asyncEntry) []asyncEntry {
+	for {
+		a.mu.Lock()
+		batch = batch[:0]
+		for a.size > 0 {
+			batch = append(batch, a.ring[a.head])
+			a.ring[a.head] = asyncEntry{}
+			a.head = (a.head + 1) % len(a.ring)
+			a.size--
+		}
+		a.notFull.Broadcast()
+		a.mu.Unlock()
+
+		if len(batch) == 0 {
+			return batch
+		}
+		for _, entry := range batch {
+			_ = a.w.WriteLevel(entry.level, entry.data)
+		}
+	}
+}
+
+// Sync writes all buffered logs and syncs the underlying writers.
+func (a *asyncWriter) Sync() error {
+	ch := make(chan error, 1)
+	select {
+	case a.syncReq <- ch:
+		return <-ch
+	case <-a.done:
+		return a.w.Sync()
+	}
+}
+
+// Close flushes buffered logs and stops the writer goroutine, later logs are written synchronously.
+func (a *asyncWriter) Close() error {
+	a.mu.Lock()
+	if a.closed {
+		a.mu.Unlock()
+		return errAsyncClosed
+	}
+	a.closed = true
+	a.notFull.Broadcast()
+	a.mu.Unlock()
+
+	close(a.closing)
+	<-a.done
+	return a.gauge.Unregister()
+}
@@ -0,0 +1,124 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"go.opentelemetry.io/otel/metric/noop"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.uber.org/zap/zapcore"
+)
+
+// gateWriter blocks writes until gate is closed.
+type gateWriter struct {
+	gate chan struct{}
+	mu   sync.Mutex
+	buf  bytes.Buffer
+}
+
+func (w *gateWriter) Write(p []byte) (int, error) {
+	<-w.gate
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Write(p)
+}
+
+func (w *gateWriter) Sync() error { return nil }
+
+func (w *gateWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.String()
+}
+
+func TestAsyncWriterDropDebugFirst(t *testing.T) {
+	// no writer goroutine, so the ring is only filled
+	a := &asyncWriter{policy: OverflowDropDebugFirst, ring: make([]asyncEntry, 3)}
+	a.notFull = sync.NewCond(&a.mu)
+	a.dropped, _ = noop.NewMeterProvider().Meter("").Int64Counter("")
+
+	_ = a.WriteLevel(zapcore.DebugLevel, []byte("d1"))
+	_ = a.WriteLevel(zapcore.InfoLevel, []byte("i1"))
+	_ = a.WriteLevel(zapcore.DebugLevel, []byte("d2"))
+	_ = a.WriteLevel(zapcore.WarnLevel, []byte("w1"))
+	_ = a.WriteLevel(zapcore.InfoLevel, []byte("i2"))
+	_ = a.WriteLevel(zapcore.DebugLevel, []byte("d3"))
+
+	var got []string
+	for i := 0; i < a.size; i++ {
+		got = append(got, string(a.ring[(a.head+i)%len(a.ring)].data))
+	}
+	if strings.Join(got, ",") != "i1,w1,i2" {
+		t.Errorf("buffered====%v", got)
+	}
+}
+
+func TestAsyncWriterDropNewest(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	w := &gateWriter{gate: make(chan struct{})}
+	a, err := newAsyncWriter(levelWriter{w}, AsyncConfig{BufferSize: 2, Overflow: OverflowDropNewest}, sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		_ = a.WriteLevel(zapcore.InfoLevel, []byte("line\n"))
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatal(err)
+	}
+	var dropped, depth int64
+	for _, m := range rm.ScopeMetrics[0].Metrics {
+		switch data := m.Data.(type) {
+		case metricdata.Sum[int64]:
+			if m.Name == DefaultAsyncDroppedName {
+				dropped = data.DataPoints[0].Value
+			}
+		case metricdata.Gauge[int64]:
+			if m.Name == DefaultAsyncQueueDepthName {
+				depth = data.DataPoints[0].Value
+			}
+		}
+	}
+	// at most one line is taken by the blocked writer goroutine
+	if dropped < 7 || depth != 2 {
+		t.Errorf("dropped====%d depth====%d", dropped, depth)
+	}
+
+	close(w.gate)
+	if err := a.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(w.String(), "line\n"); int64(n)+dropped != 10 {
+		t.Errorf("written====%d dropped====%d", n, dropped)
+	}
+	// logs after Close are written synchronously
+	_ = a.WriteLevel(zapcore.InfoLevel, []byte("after\n"))
+	if !strings.HasSuffix(w.String(), "after\n") {
+		t.Errorf("after close====%q", w.String())
+	}
+}
+
+func TestAsyncWriterSync(t *testing.T) {
+	w := &gateWriter{gate: make(chan struct{})}
+	close(w.gate)
+	a, err := newAsyncWriter(levelWriter{w}, AsyncConfig{BufferSize: 4}, noop.NewMeterProvider())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+	for i := 0; i < 100; i++ {
+		_ = a.WriteLevel(zapcore.InfoLevel, []byte("line\n"))
+	}
+	if err := a.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(w.String(), "line\n"); n != 100 {
+		t.Errorf("written====%d", n)
+	}
+}
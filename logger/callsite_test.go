@@ -0,0 +1,56 @@
+package logger_test
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+	"testing"
+
+	"github.com/opendevops-cn/codo-golang-sdk/logger"
+)
+
+// countLogger counts the logs passed through. The tests of call sites are outside package logger,
+// because frames of package logger are never call sites.
+type countLogger struct {
+	n atomic.Int32
+}
+
+func (l *countLogger) Log(context.Context, logger.Level, ...interface{}) error {
+	l.n.Add(1)
+	return nil
+}
+
+func TestRateLimitCallSite(t *testing.T) {
+	ctx := context.Background()
+	rec := &countLogger{}
+	log := logger.NewRateLimitLogger(rec, logger.RateLimitConfig{Rate: 1, Burst: 1})
+	for i := 0; i < 3; i++ {
+		_ = log.Log(ctx, logger.LevelInfo, logger.DefaultMessageKey, "a")
+	}
+	_ = log.Log(ctx, logger.LevelInfo, logger.DefaultMessageKey, "b")
+	if got := rec.n.Load(); got != 2 {
+		t.Fatalf("direct====%d", got)
+	}
+
+	// frames of log/slog are skipped, each slog call is its own call site
+	slogger := slog.New(logger.NewSlogHandler(log))
+	for i := 0; i < 3; i++ {
+		slogger.Info("c")
+	}
+	slogger.Info("d")
+	if got := rec.n.Load(); got != 4 {
+		t.Fatalf("slog====%d", got)
+	}
+
+	// CallerSkip attributes logs of a wrapper to the callers of the wrapper
+	wrapped := &countLogger{}
+	skipped := logger.NewRateLimitLogger(wrapped, logger.RateLimitConfig{Rate: 1, Burst: 1, CallerSkip: 1})
+	logVia := func(msg string) {
+		_ = skipped.Log(ctx, logger.LevelInfo, logger.DefaultMessageKey, msg)
+	}
+	logVia("e")
+	logVia("f")
+	if got := wrapped.n.Load(); got != 2 {
+		t.Fatalf("caller skip====%d", got)
+	}
+}
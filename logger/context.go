@@ -0,0 +1,90 @@
+package logger
+
+import (
+	"context"
+)
+
+// Valuer is a value evaluated with ctx when the log is printed, e.g. the request path or current time.
+type Valuer func(ctx context.Context) interface{}
+
+type fieldsKey struct{}
+
+// NewContext returns a copy of ctx carrying keyvals, they are printed by every log with the context.
+// Fields are appended to the ones already in ctx, so middlewares can add request-bound fields step by step.
+func NewContext(ctx context.Context, keyvals ...interface{}) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if (len(keyvals) & 1) == 1 {
+		keyvals = append(keyvals, "KEYVALS UNPAIRED")
+	}
+	fields := FieldsFromContext(ctx)
+	merged := make([]interface{}, 0, len(fields)+len(keyvals))
+	merged = append(merged, fields...)
+	merged = append(merged, keyvals...)
+	return context.WithValue(ctx, fieldsKey{}, merged)
+}
+
+// FieldsFromContext returns the keyvals attached by NewContext.
+func FieldsFromContext(ctx context.Context) []interface{} {
+	if ctx == nil {
+		return nil
+	}
+	fields, _ := ctx.Value(fieldsKey{}).([]interface{})
+	return fields
+}
+
+// withLogger prints prefix before keyvals of every log.
+type withLogger struct {
+	logger Logger
+	prefix []interface{}
+}
+
+// With returns a logger printing keyvals before the keyvals of every log, Valuer in keyvals is evaluated per log.
+func With(l Logger, keyvals ...interface{}) Logger {
+	if w, ok := l.(*withLogger); ok {
+		prefix := make([]interface{}, 0, len(w.prefix)+len(keyvals))
+		prefix = append(prefix, w.prefix...)
+		prefix = append(prefix, keyvals...)
+		return &withLogger{logger: w.logger, prefix: prefix}
+	}
+	return &withLogger{logger: l, prefix: keyvals}
+}
+
+// Log print the kv pairs log with the prefix.
+func (l *withLogger) Log(ctx context.Context, level Level, keyvals ...interface{}) error {
+	kvs := make([]interface{}, 0, len(l.prefix)+len(keyvals))
+	kvs = append(kvs, l.prefix...)
+	kvs = append(kvs, keyvals...)
+	return l.logger.Log(ctx, level, kvs...)
+}
+
+// bindContext prepends the fields of ctx to keyvals and evaluates Valuers.
+// The returned ctx carries no fields, so loggers wrapped by the caller do not print them twice.
+func bindContext(ctx context.Context, keyvals []interface{}) (context.Context, []interface{}) {
+	fields := FieldsFromContext(ctx)
+	if len(fields) == 0 && !containsValuer(keyvals) {
+		return ctx, keyvals
+	}
+	kvs := make([]interface{}, 0, len(fields)+len(keyvals))
+	kvs = append(kvs, fields...)
+	kvs = append(kvs, keyvals...)
+	for i := 1; i < len(kvs); i += 2 {
+		if v, ok := kvs[i].(Valuer); ok {
+			kvs[i] = v(ctx)
+		}
+	}
+	if len(fields) > 0 {
+		ctx = context.WithValue(ctx, fieldsKey{}, []interface{}(nil))
+	}
+	return ctx, kvs
+}
+
+func containsValuer(keyvals []interface{}) bool {
+	for i := 1; i < len(keyvals); i += 2 {
+		if _, ok := keyvals[i].(Valuer); ok {
+			return true
+		}
+	}
+	return false
+}
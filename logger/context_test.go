@@ -0,0 +1,34 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+func TestContextFields(t *testing.T) {
+	var buf bytes.Buffer
+	log := With(NewStdLogger(&buf), "service", "api")
+	log = With(log, "path", Valuer(func(ctx context.Context) interface{} {
+		return "/v1/users"
+	}))
+
+	ctx := NewContext(context.Background(), "user_id", 1)
+	ctx = NewContext(ctx, "tenant", "codo")
+	_ = log.Log(ctx, LevelInfo, DefaultMessageKey, "hello")
+
+	want := "INFO user_id=1 tenant=codo service=api path=/v1/users msg=hello\n"
+	if buf.String() != want {
+		t.Errorf("log====%q", buf.String())
+	}
+}
+
+func TestContextFieldsHelper(t *testing.T) {
+	var buf bytes.Buffer
+	h := NewHelper(NewStdLogger(&buf))
+	h.Info(NewContext(context.Background(), "request_id", "r1"), "done")
+
+	if want := "INFO request_id=r1 msg=done\n"; buf.String() != want {
+		t.Errorf("log====%q", buf.String())
+	}
+}
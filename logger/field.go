@@ -0,0 +1,80 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// StackSuffix is appended to the key of an error field to carry its stack trace, e.g. error.stack.
+const StackSuffix = ".stack"
+
+// stackTracer is implemented by errors created by github.com/pkg/errors.
+type stackTracer interface {
+	StackTrace() errors.StackTrace
+}
+
+// appendZapFields converts keyvals to typed zap fields, unpaired keyvals are handled like stdLogger.
+func appendZapFields(fields []zap.Field, keyvals []interface{}) []zap.Field {
+	if (len(keyvals) & 1) == 1 {
+		keyvals = append(keyvals, "KEYVALS UNPAIRED")
+	}
+	for i := 0; i < len(keyvals); i += 2 {
+		fields = appendZapField(fields, fmt.Sprint(keyvals[i]), keyvals[i+1])
+	}
+	return fields
+}
+
+// appendZapField keeps the type of value so numbers, durations and objects are not stringified.
+func appendZapField(fields []zap.Field, key string, value interface{}) []zap.Field {
+	switch v := value.(type) {
+	case error:
+		if isNil(v) {
+			return append(fields, zap.String(key, "<nil>"))
+		}
+		fields = append(fields, zap.String(key, v.Error()))
+		if stack, ok := errorStack(v); ok {
+			fields = append(fields, zap.String(key+StackSuffix, stack))
+		}
+		return fields
+	case proto.Message:
+		bs, err := protojson.Marshal(v)
+		if err != nil {
+			return append(fields, zap.String(key, fmt.Sprint(v)))
+		}
+		return append(fields, zap.Reflect(key, json.RawMessage(bs)))
+	case json.Marshaler:
+		return append(fields, zap.Reflect(key, v))
+	default:
+		return append(fields, zap.Any(key, v))
+	}
+}
+
+// isNil reports whether value is nil or a typed nil, e.g. a nil *MyError stored in an error,
+// whose Error method usually panics.
+func isNil(value interface{}) bool {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Invalid:
+		return true
+	case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan, reflect.Interface:
+		return v.IsNil()
+	default:
+		return false
+	}
+}
+
+// errorStack formats the stack trace of the first error in the chain carrying one,
+// so errors wrapped with fmt.Errorf("%w") keep the stack of github.com/pkg/errors.
+func errorStack(err error) (string, bool) {
+	var st stackTracer
+	if !errors.As(err, &st) || isNil(st) {
+		return "", false
+	}
+	return fmt.Sprintf("%+v", st.StackTrace()), true
+}
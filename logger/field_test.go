@@ -0,0 +1,77 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	pkgerrors "github.com/pkg/errors"
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestAppendZapFields(t *testing.T) {
+	enc := zapcore.NewMapObjectEncoder()
+	fields := appendZapFields(nil, []interface{}{
+		"int", 1,
+		"float", 1.5,
+		"bool", true,
+		"duration", time.Second,
+		"error", errors.New("plain"),
+		"wrapped", pkgerrors.New("with stack"),
+		"proto", wrapperspb.String("v"),
+		"odd",
+	})
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+
+	if enc.Fields["int"] != int64(1) || enc.Fields["float"] != 1.5 || enc.Fields["bool"] != true {
+		t.Errorf("scalar fields====%v", enc.Fields)
+	}
+	if enc.Fields["duration"] != time.Second {
+		t.Errorf("duration====%v", enc.Fields["duration"])
+	}
+	if enc.Fields["error"] != "plain" {
+		t.Errorf("error====%v", enc.Fields["error"])
+	}
+	if _, ok := enc.Fields["error"+StackSuffix]; ok {
+		t.Error("plain error should not have stack")
+	}
+	if stack, _ := enc.Fields["wrapped"+StackSuffix].(string); !strings.Contains(stack, "TestAppendZapFields") {
+		t.Errorf("stack====%v", enc.Fields["wrapped"+StackSuffix])
+	}
+	if _, ok := enc.Fields["proto"].(string); ok {
+		t.Errorf("proto should be an object, got %v", enc.Fields["proto"])
+	}
+	if enc.Fields["odd"] != "KEYVALS UNPAIRED" {
+		t.Errorf("odd====%v", enc.Fields["odd"])
+	}
+}
+
+type nilError struct{ msg string }
+
+func (e *nilError) Error() string { return e.msg }
+
+func TestAppendZapFieldsErrors(t *testing.T) {
+	var typedNil *nilError
+	enc := zapcore.NewMapObjectEncoder()
+	for _, f := range appendZapFields(nil, []interface{}{
+		"typed_nil", error(typedNil),
+		"wrapped", fmt.Errorf("query: %w", pkgerrors.New("with stack")),
+	}) {
+		f.AddTo(enc)
+	}
+
+	if enc.Fields["typed_nil"] != "<nil>" {
+		t.Errorf("typed nil====%v", enc.Fields["typed_nil"])
+	}
+	if enc.Fields["wrapped"] != "query: with stack" {
+		t.Errorf("wrapped====%v", enc.Fields["wrapped"])
+	}
+	if stack, _ := enc.Fields["wrapped"+StackSuffix].(string); !strings.Contains(stack, "TestAppendZapFieldsErrors") {
+		t.Errorf("stack====%v", enc.Fields["wrapped"+StackSuffix])
+	}
+}
@@ -27,6 +27,10 @@ func (a *loggerAppliance) SetLogger(in Logger) {
 	a.Logger = in
 }
 
+func (a *loggerAppliance) configuredLevel() Level {
+	return configuredLevel(a.Logger)
+}
+
 // SetLogger should be called before any other log call.
 // And it is NOT THREAD SAFE.
 func SetLogger(logger Logger) {
@@ -0,0 +1,74 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// LevelHandler returns an http.Handler to list and change logger levels at runtime,
+// it is usually mounted at /debug/loglevel.
+//
+//	GET    /debug/loglevel                            list all loggers
+//	GET    /debug/loglevel?name=kafka                 show one logger
+//	PUT    /debug/loglevel?name=kafka&level=DEBUG     set level, optional ttl=10m reverts it after 10 minutes
+//	DELETE /debug/loglevel?name=kafka                 reset to inherit from parent
+//
+// Omitting name means the root logger.
+func LevelHandler() http.Handler {
+	return http.HandlerFunc(serveLevel)
+}
+
+func serveLevel(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	name := query.Get("name")
+
+	switch r.Method {
+	case http.MethodGet:
+		if !query.Has("name") {
+			writeLevelJSON(w, http.StatusOK, Levels())
+			return
+		}
+	case http.MethodPut, http.MethodPost:
+		level, ok := lookupLevel(query.Get("level"))
+		if !ok {
+			writeLevelError(w, http.StatusBadRequest, fmt.Sprintf("invalid level %q", query.Get("level")))
+			return
+		}
+		var ttl time.Duration
+		if s := query.Get("ttl"); s != "" {
+			var err error
+			ttl, err = time.ParseDuration(s)
+			if err != nil || ttl <= 0 {
+				writeLevelError(w, http.StatusBadRequest, fmt.Sprintf("invalid ttl %q", s))
+				return
+			}
+		}
+		SetLevelWithTTL(name, level, ttl)
+	case http.MethodDelete:
+		ResetLevel(name)
+	default:
+		w.Header().Set("Allow", "GET, PUT, POST, DELETE")
+		writeLevelError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	writeLevelJSON(w, http.StatusOK, levels.info(name, configuredLevel(global)))
+}
+
+// lookupLevel is ParseLevel without falling back to INFO on unknown input.
+func lookupLevel(s string) (Level, bool) {
+	level := ParseLevel(s)
+	return level, strings.EqualFold(level.String(), s)
+}
+
+func writeLevelJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeLevelError(w http.ResponseWriter, code int, msg string) {
+	writeLevelJSON(w, code, map[string]string{"error": msg})
+}
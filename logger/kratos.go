@@ -0,0 +1,26 @@
+package logger
+
+import (
+	"context"
+
+	klog "github.com/go-kratos/kratos/v2/log"
+)
+
+var _ klog.Logger = (*kratosLogger)(nil)
+
+// kratosLogger is a kratos log.Logger logging through a Logger.
+type kratosLogger struct {
+	logger Logger
+}
+
+// NewKratosLogger returns a kratos log.Logger logging through l, e.g. for the etcd Registry.
+// Kratos does not pass ctx to loggers, use kratos log.With with tracing.TraceID() and
+// tracing.SpanID() valuers to keep trace ids.
+func NewKratosLogger(l Logger) klog.Logger {
+	return &kratosLogger{logger: l}
+}
+
+// Log print the kv pairs log, kratos levels have the same values as Level.
+func (l *kratosLogger) Log(level klog.Level, keyvals ...interface{}) error {
+	return l.logger.Log(context.Background(), Level(level), keyvals...)
+}
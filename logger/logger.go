@@ -2,17 +2,19 @@ package logger
 
 import (
 	"context"
-	"fmt"
 	"log"
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/opendevops-cn/codo-golang-sdk/redact"
 	"go.opentelemetry.io/otel"
+	otellog "go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/metric"
 	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
-	"gopkg.in/natefinch/lumberjack.v2"
 )
 
 // DefaultLogger is default logger.
@@ -51,8 +53,30 @@ type LogConfig struct {
 	// is to retain all old log files (though MaxAge may still cause them to get
 	// deleted.)
 	MaxBackups int `json:"maxBackups" yaml:"maxBackups" env:"DEFAULT_LOG_MAX_BACKUPS"`
+	// Rotation rotates the file hourly or daily, Filepath must then contain strftime verbs, see RotateConfig.
+	Rotation RotateInterval `json:"rotation" yaml:"rotation" env:"DEFAULT_LOG_ROTATION"`
+	// MaxTotalSize is the maximum size in megabytes of all log files.
+	MaxTotalSize int `json:"maxTotalSize" yaml:"maxTotalSize" env:"DEFAULT_LOG_MAX_TOTAL_SIZE"`
+	// Compression of rotated files, gzip, zstd or none.
+	Compression Compression `json:"compression" yaml:"compression" env:"DEFAULT_LOG_COMPRESSION"`
+	// Symlink points to the current log file.
+	Symlink string `json:"symlink" yaml:"symlink" env:"DEFAULT_LOG_SYMLINK"`
+
+	// 日志采样、按调用位置限流和去重, 零值时不启用
+	Sampling  SamplingConfig  `json:"sampling" yaml:"sampling"`
+	RateLimit RateLimitConfig `json:"rateLimit" yaml:"rateLimit"`
+	Dedup     DedupConfig     `json:"dedup" yaml:"dedup"`
+	// Sinks 日志输出, 为空时输出到 stdout 和 Filepath(不为空时), 见 SinkConfig
+	Sinks []SinkConfig `json:"sinks" yaml:"sinks"`
+	// 异步写日志, 见 AsyncConfig
+	Async AsyncConfig `json:"async" yaml:"async"`
+	// Redactor 不为空时遮盖日志中的敏感数据, 见 WithRedactor
+	Redactor *redact.Redactor `json:"-"`
 
 	TraceProvider trace.TracerProvider `json:"-"`
+	MeterProvider metric.MeterProvider `json:"-"`
+	// LoggerProvider 不为空时日志同时通过 OpenTelemetry Logs 输出, 见 WithLoggerProvider
+	LoggerProvider otellog.LoggerProvider `json:"-"`
 }
 
 func defaultLogConfig() *LogConfig {
@@ -69,19 +93,66 @@ func defaultLogConfig() *LogConfig {
 	maxSize := emptyOr(os.Getenv("DEFAULT_LOG_MAX_SIZE"), "0")
 	maxAge := emptyOr(os.Getenv("DEFAULT_LOG_MAX_AGE"), "0")
 	maxBackups := emptyOr(os.Getenv("DEFAULT_LOG_MAX_BACKUPS"), "0")
+	rotation := emptyOr(os.Getenv("DEFAULT_LOG_ROTATION"), "")
+	maxTotalSize := emptyOr(os.Getenv("DEFAULT_LOG_MAX_TOTAL_SIZE"), "0")
+	compression := emptyOr(os.Getenv("DEFAULT_LOG_COMPRESSION"), "")
+	symlink := emptyOr(os.Getenv("DEFAULT_LOG_SYMLINK"), "")
+	samplingTick := emptyOr(os.Getenv("DEFAULT_LOG_SAMPLING_TICK"), "0")
+	samplingFirst := emptyOr(os.Getenv("DEFAULT_LOG_SAMPLING_FIRST"), "0")
+	samplingThereafter := emptyOr(os.Getenv("DEFAULT_LOG_SAMPLING_THEREAFTER"), "0")
+	rateLimit := emptyOr(os.Getenv("DEFAULT_LOG_RATE_LIMIT"), "0")
+	rateBurst := emptyOr(os.Getenv("DEFAULT_LOG_RATE_BURST"), "0")
+	dedupWindow := emptyOr(os.Getenv("DEFAULT_LOG_DEDUP_WINDOW"), "0")
+	dedupMaxKeys := emptyOr(os.Getenv("DEFAULT_LOG_DEDUP_MAX_KEYS"), "0")
+	asyncBufferSize := emptyOr(os.Getenv("DEFAULT_LOG_ASYNC_BUFFER_SIZE"), "0")
+	asyncOverflow := emptyOr(os.Getenv("DEFAULT_LOG_ASYNC_OVERFLOW"), string(OverflowBlock))
+	asyncFlushInterval := emptyOr(os.Getenv("DEFAULT_LOG_ASYNC_FLUSH_INTERVAL"), "1s")
 
 	i64MaxSize, _ := strconv.Atoi(maxSize)
 	i64MaxAge, _ := strconv.Atoi(maxAge)
 	i64MaxBackups, _ := strconv.Atoi(maxBackups)
+	i64MaxTotalSize, _ := strconv.Atoi(maxTotalSize)
+	durSamplingTick, _ := time.ParseDuration(samplingTick)
+	intSamplingFirst, _ := strconv.Atoi(samplingFirst)
+	intSamplingThereafter, _ := strconv.Atoi(samplingThereafter)
+	f64RateLimit, _ := strconv.ParseFloat(rateLimit, 64)
+	intRateBurst, _ := strconv.Atoi(rateBurst)
+	durDedupWindow, _ := time.ParseDuration(dedupWindow)
+	intDedupMaxKeys, _ := strconv.Atoi(dedupMaxKeys)
+	intAsyncBufferSize, _ := strconv.Atoi(asyncBufferSize)
+	durAsyncFlushInterval, _ := time.ParseDuration(asyncFlushInterval)
 
 	return &LogConfig{
-		Level:         level,
-		Encoding:      LogEncoding(logEncoding),
-		Filepath:      logFilepath,
-		MaxSize:       i64MaxSize,
-		MaxAge:        i64MaxAge,
-		MaxBackups:    i64MaxBackups,
+		Level:        level,
+		Encoding:     LogEncoding(logEncoding),
+		Filepath:     logFilepath,
+		MaxSize:      i64MaxSize,
+		MaxAge:       i64MaxAge,
+		MaxBackups:   i64MaxBackups,
+		Rotation:     RotateInterval(rotation),
+		MaxTotalSize: i64MaxTotalSize,
+		Compression:  Compression(compression),
+		Symlink:      symlink,
+		Sampling: SamplingConfig{
+			Tick:       durSamplingTick,
+			First:      intSamplingFirst,
+			Thereafter: intSamplingThereafter,
+		},
+		RateLimit: RateLimitConfig{
+			Rate:  f64RateLimit,
+			Burst: intRateBurst,
+		},
+		Dedup: DedupConfig{
+			Window:  durDedupWindow,
+			MaxKeys: intDedupMaxKeys,
+		},
+		Async: AsyncConfig{
+			BufferSize:    intAsyncBufferSize,
+			Overflow:      OverflowPolicy(asyncOverflow),
+			FlushInterval: durAsyncFlushInterval,
+		},
 		TraceProvider: otel.GetTracerProvider(),
+		MeterProvider: otel.GetMeterProvider(),
 	}
 }
 
@@ -97,51 +168,28 @@ func NewLogger(opts ...LogConfigOption) (Logger, error) {
 		opt(c)
 	}
 
-	logfile := c.Filepath
+	// 每个 logger 使用自己配置的级别, 运行时通过 SetLevel 设置的级别优先, 见 Named
+	configured := ParseLevel(c.Level)
 
-	// 命令行输出一份
-	syncers := []zapcore.WriteSyncer{
-		zapcore.AddSync(os.Stdout),
-	}
-	if logfile != "" {
-		// 配置lumberjack日志轮转
-		w := zapcore.AddSync(&lumberjack.Logger{
-			Filename:   logfile,
-			MaxSize:    c.MaxSize,
-			MaxBackups: c.MaxBackups,
-			MaxAge:     c.MaxAge,
-			Compress:   true,
-		})
-
-		// 创建自定义的WriteSyncer，结合控制台输出和文件输出
-		syncers = append(syncers, w)
+	// 每个 sink 一个 core, 各自的级别和编码
+	cores, closers, err := newSinkCores(c)
+	if err != nil {
+		return nil, err
 	}
 
-	// 编码配置
-	encoderConfig := zap.NewProductionEncoderConfig()
-	var encoder zapcore.Encoder
-	switch c.Encoding {
-	case LogEncodingConsole:
-		encoderConfig.EncodeTime = zapcore.RFC3339TimeEncoder
-		encoder = zapcore.NewConsoleEncoder(encoderConfig)
-	case LogEncodingJSON:
-		encoder = zapcore.NewJSONEncoder(encoderConfig)
-	default:
-		encoderConfig.EncodeTime = zapcore.RFC3339TimeEncoder
-		encoder = zapcore.NewConsoleEncoder(encoderConfig)
-	}
-
-	// 使用自定义的WriteSyncer构建core
-	core := zapcore.NewCore(
-		encoder,
-		zapcore.NewMultiWriteSyncer(syncers...),
-		convZapLevel(ParseLevel(c.Level)),
-	)
-
 	// 使用core创建logger
-	logger := zap.New(core)
+	logger := zap.New(zapcore.NewTee(cores...))
 
-	return LogWrapper(func(ctx context.Context, level Level, keyvals ...interface{}) error {
+	var otelLog Logger
+	if c.LoggerProvider != nil {
+		// 级别已经在外层判断过
+		otelLog = &otelLogger{logger: c.LoggerProvider.Logger(OTelScopeName), leveled: true}
+	}
+
+	var out Logger = LogWrapper(func(ctx context.Context, level Level, keyvals ...interface{}) error {
+		if otelLog != nil {
+			_ = otelLog.Log(ctx, level, keyvals...)
+		}
 		span := trace.SpanContextFromContext(ctx)
 		zapLevel := convZapLevel(level)
 		fields := []zap.Field{
@@ -149,12 +197,51 @@ func NewLogger(opts ...LogConfigOption) (Logger, error) {
 			zap.String("span_id", span.SpanID().String()),
 		}
 
-		for i := 0; i < len(keyvals); i += 2 {
-			fields = append(fields, zap.String(fmt.Sprintf("%v", keyvals[i]), fmt.Sprintf("%v", keyvals[i+1])))
-		}
+		fields = appendZapFields(fields, keyvals)
 		logger.Log(zapLevel, "", fields...)
 		return nil
-	}), nil
+	})
+	out = wrapLimits(out, c)
+	if c.Redactor != nil {
+		out = NewRedactLogger(out, c.Redactor)
+	}
+
+	return &zapLogger{
+		Logger: LogWrapper(func(ctx context.Context, level Level, keyvals ...interface{}) error {
+			if !levels.enabled(loggerName(ctx), level, configured) {
+				return nil
+			}
+			// 先展开 ctx 中的字段, 去重时不同请求的日志不会被合并
+			ctx, keyvals = bindContext(ctx, keyvals)
+			return out.Log(ctx, level, keyvals...)
+		}),
+		level:   configured,
+		zap:     logger,
+		closers: closers,
+	}, nil
+}
+
+// zapLogger is returned by NewLogger.
+type zapLogger struct {
+	Logger
+	// level is LogConfig.Level, levels set at runtime by SetLevel take precedence.
+	level   Level
+	zap     *zap.Logger
+	closers []func() error
+}
+
+func (l *zapLogger) configuredLevel() Level {
+	return l.level
+}
+
+// Sync flushes buffered logs, call it before the process exits.
+func (l *zapLogger) Sync() error {
+	return l.zap.Sync()
+}
+
+// Close flushes buffered logs and releases the sinks, e.g. files, syslog connections and kafka producers.
+func (l *zapLogger) Close() error {
+	return closeAll(l.closers)
 }
 
 func convZapLevel(level Level) zapcore.Level {
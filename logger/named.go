@@ -0,0 +1,304 @@
+package logger
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// NameKey is the key of logger name in keyvals.
+const NameKey = "logger"
+
+// RootName is the name of the root logger, named loggers without explicit level inherit from it.
+const RootName = ""
+
+// levels is the registry of levels set at runtime by SetLevel, loggers without one use their configured level.
+var levels = newLevelRegistry()
+
+type loggerNameKey struct{}
+
+// namedLogger logs through the global logger with its own runtime-adjustable level,
+// the level is applied by the global logger with the name carried by ctx.
+type namedLogger struct {
+	name string
+}
+
+// Named returns a child logger of the global logger.
+// Names are hierarchical with dots, "kafka.consumer" inherits the level of "kafka" unless it is set explicitly.
+func Named(name string) Logger {
+	levels.register(name)
+	return &namedLogger{name: name}
+}
+
+// Log print the kv pairs log through the global logger with the logger name.
+func (l *namedLogger) Log(ctx context.Context, level Level, keyvals ...interface{}) error {
+	ctx = context.WithValue(ctx, loggerNameKey{}, l.name)
+	return global.Log(ctx, level, append([]interface{}{NameKey, l.name}, keyvals...)...)
+}
+
+func (l *namedLogger) configuredLevel() Level {
+	return configuredLevel(global)
+}
+
+// leveled is implemented by loggers with a configured level, e.g. the loggers of NewLogger.
+type leveled interface {
+	configuredLevel() Level
+}
+
+// configuredLevel returns the level configured for l, LevelDebug if l has none.
+func configuredLevel(l Logger) Level {
+	if l, ok := l.(leveled); ok {
+		return l.configuredLevel()
+	}
+	return LevelDebug
+}
+
+// loggerName returns the logger name carried by ctx, RootName if none.
+func loggerName(ctx context.Context) string {
+	if ctx == nil {
+		return RootName
+	}
+	name, _ := ctx.Value(loggerNameKey{}).(string)
+	return name
+}
+
+// SetLevel sets the level of the named logger, use RootName for the root logger.
+func SetLevel(name string, level Level) {
+	levels.set(name, level, 0)
+}
+
+// SetLevelWithTTL sets the level of the named logger and reverts it after ttl.
+// Setting a level again before reverting keeps the original level to revert to.
+func SetLevelWithTTL(name string, level Level, ttl time.Duration) {
+	levels.set(name, level, ttl)
+}
+
+// ResetLevel removes the explicit level of the named logger, it inherits from its parent again.
+// Without any level set at runtime, each logger uses the level configured by NewLogger.
+func ResetLevel(name string) {
+	levels.reset(name)
+}
+
+// GetLevel returns the effective level of the named logger, the configured level of the global logger
+// if no level is set at runtime on it or its parents.
+func GetLevel(name string) Level {
+	return levels.effective(name, configuredLevel(global))
+}
+
+// LevelState is the level state of a named logger.
+type LevelState struct {
+	Name string `json:"name"`
+	// Level is the effective level.
+	Level string `json:"level"`
+	// Explicit reports whether the level is set on this logger rather than inherited.
+	Explicit bool `json:"explicit"`
+	// RevertAt is the time the level reverts, nil if it does not expire.
+	RevertAt *time.Time `json:"revert_at,omitempty"`
+}
+
+// Levels returns the level state of all known loggers, sorted by name.
+func Levels() []LevelState {
+	return levels.list(configuredLevel(global))
+}
+
+// noOverride is the cached level of names without a level set at runtime on them or their parents.
+const noOverride = math.MinInt32
+
+type levelEntry struct {
+	// override caches the level set at runtime on this name or its closest parent, noOverride if none,
+	// it is recomputed on every change so log calls only load it.
+	override atomic.Int32
+
+	level    Level
+	explicit bool
+
+	// revertLevel and revertExplicit are restored when timer fires.
+	timer          *time.Timer
+	revertAt       time.Time
+	revertLevel    Level
+	revertExplicit bool
+}
+
+func (e *levelEntry) revertAtPtr() *time.Time {
+	if e.revertAt.IsZero() {
+		return nil
+	}
+	t := e.revertAt
+	return &t
+}
+
+type levelRegistry struct {
+	mu sync.Mutex
+	// entries is replaced rather than modified when a name is added, so it is read without mu.
+	entries atomic.Pointer[map[string]*levelEntry]
+}
+
+func newLevelRegistry() *levelRegistry {
+	r := &levelRegistry{}
+	root := &levelEntry{}
+	root.override.Store(noOverride)
+	r.entries.Store(&map[string]*levelEntry{RootName: root})
+	return r
+}
+
+func (r *levelRegistry) register(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entryLocked(name)
+}
+
+// entryLocked returns the entry of name, adding it if needed.
+func (r *levelRegistry) entryLocked(name string) *levelEntry {
+	entries := *r.entries.Load()
+	if entry, ok := entries[name]; ok {
+		return entry
+	}
+	entry := &levelEntry{}
+	entry.override.Store(r.overrideLocked(entries, name))
+	added := make(map[string]*levelEntry, len(entries)+1)
+	for k, v := range entries {
+		added[k] = v
+	}
+	added[name] = entry
+	r.entries.Store(&added)
+	return entry
+}
+
+// overrideLocked walks up "a.b.c" -> "a.b" -> "a" -> root until an explicit level is found.
+func (r *levelRegistry) overrideLocked(entries map[string]*levelEntry, name string) int32 {
+	for {
+		if entry, ok := entries[name]; ok && entry.explicit {
+			return int32(entry.level)
+		}
+		if name == RootName {
+			return noOverride
+		}
+		name = parentName(name)
+	}
+}
+
+// recomputeLocked refreshes the cached override of every name after a level changes.
+func (r *levelRegistry) recomputeLocked() {
+	entries := *r.entries.Load()
+	for name, entry := range entries {
+		entry.override.Store(r.overrideLocked(entries, name))
+	}
+}
+
+func parentName(name string) string {
+	if i := strings.LastIndexByte(name, '.'); i >= 0 {
+		return name[:i]
+	}
+	return RootName
+}
+
+// override returns the level set at runtime on name or its closest parent.
+func (r *levelRegistry) override(name string) (Level, bool) {
+	entries := *r.entries.Load()
+	entry, ok := entries[name]
+	for !ok {
+		// names are registered by Named, unknown ones inherit from their closest known parent
+		name = parentName(name)
+		entry, ok = entries[name]
+	}
+	v := entry.override.Load()
+	return Level(v), v != noOverride
+}
+
+// enabled reports whether level is enabled for name, configured is used if no level is set at runtime.
+func (r *levelRegistry) enabled(name string, level, configured Level) bool {
+	if override, ok := r.override(name); ok {
+		return level >= override
+	}
+	return level >= configured
+}
+
+func (r *levelRegistry) effective(name string, configured Level) Level {
+	if override, ok := r.override(name); ok {
+		return override
+	}
+	return configured
+}
+
+func (r *levelRegistry) set(name string, level Level, ttl time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry := r.entryLocked(name)
+	if entry.timer != nil {
+		entry.timer.Stop()
+		entry.timer = nil
+	} else if ttl > 0 {
+		entry.revertLevel, entry.revertExplicit = entry.level, entry.explicit
+	}
+	entry.level, entry.explicit = level, true
+	entry.revertAt = time.Time{}
+
+	if ttl > 0 {
+		entry.revertAt = time.Now().Add(ttl)
+		var timer *time.Timer
+		timer = time.AfterFunc(ttl, func() {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			// replaced by a newer level
+			if entry.timer != timer {
+				return
+			}
+			entry.level, entry.explicit = entry.revertLevel, entry.revertExplicit
+			entry.timer, entry.revertAt = nil, time.Time{}
+			r.recomputeLocked()
+		})
+		entry.timer = timer
+	}
+	r.recomputeLocked()
+}
+
+func (r *levelRegistry) reset(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry, ok := (*r.entries.Load())[name]
+	if !ok {
+		return
+	}
+	if entry.timer != nil {
+		entry.timer.Stop()
+		entry.timer = nil
+	}
+	entry.revertAt = time.Time{}
+	entry.explicit = false
+	r.recomputeLocked()
+}
+
+func (r *levelRegistry) list(configured Level) []LevelState {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entries := *r.entries.Load()
+	result := make([]LevelState, 0, len(entries))
+	for name, entry := range entries {
+		result = append(result, r.stateLocked(name, entry, configured))
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+func (r *levelRegistry) info(name string, configured Level) LevelState {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.stateLocked(name, (*r.entries.Load())[name], configured)
+}
+
+func (r *levelRegistry) stateLocked(name string, entry *levelEntry, configured Level) LevelState {
+	state := LevelState{Name: name, Level: r.effective(name, configured).String()}
+	if entry != nil {
+		state.Explicit, state.RevertAt = entry.explicit, entry.revertAtPtr()
+	}
+	return state
+}
@@ -0,0 +1,143 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNamedLevels(t *testing.T) {
+	var buf bytes.Buffer
+	SetLogger(NewStdLogger(&buf))
+	defer SetLogger(DefaultLogger)
+	defer ResetLevel(RootName)
+
+	SetLevel(RootName, LevelInfo)
+	consumer := NewHelper(Named("test.consumer"))
+	consumer.Debug(context.Background(), "hidden")
+	if buf.Len() != 0 {
+		t.Fatalf("debug should be filtered by root level, got %q", buf.String())
+	}
+
+	SetLevelWithTTL("test", LevelDebug, time.Millisecond*50)
+	consumer.Debug(context.Background(), "shown")
+	if got := buf.String(); !strings.Contains(got, "logger=test.consumer") || !strings.Contains(got, "msg=shown") {
+		t.Errorf("debug should be enabled by parent level, got %q", got)
+	}
+
+	time.Sleep(time.Millisecond * 100)
+	if level := GetLevel("test.consumer"); level != LevelInfo {
+		t.Errorf("level should revert after ttl, got %s", level)
+	}
+}
+
+func TestNewLoggerLevels(t *testing.T) {
+	defer ResetLevel(RootName)
+	newLogger := func(level string) (Logger, *recordWriter) {
+		t.Helper()
+		w := &recordWriter{}
+		log, err := NewLogger(
+			func(c *LogConfig) { c.Level = level },
+			WithSinks(SinkConfig{Type: "record", Writer: w}),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return log, w
+	}
+	count := func(w *recordWriter) int {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		return len(w.logs)
+	}
+
+	// 每个 logger 使用自己配置的级别, 后创建的不会影响先创建的
+	info, infoW := newLogger("INFO")
+	errorLog, errorW := newLogger("ERROR")
+	ctx := context.Background()
+	for _, log := range []Logger{info, errorLog} {
+		_ = log.Log(ctx, LevelDebug, DefaultMessageKey, "debug")
+		_ = log.Log(ctx, LevelWarn, DefaultMessageKey, "warn")
+	}
+	if count(infoW) != 1 || count(errorW) != 0 {
+		t.Fatalf("configured====%d %d", count(infoW), count(errorW))
+	}
+
+	// 运行时设置的级别优先于配置的级别, 重置后恢复
+	SetLevel(RootName, LevelDebug)
+	_ = errorLog.Log(ctx, LevelDebug, DefaultMessageKey, "debug")
+	if count(errorW) != 1 || GetLevel(RootName) != LevelDebug {
+		t.Errorf("runtime====%d %s", count(errorW), GetLevel(RootName))
+	}
+	ResetLevel(RootName)
+	_ = errorLog.Log(ctx, LevelDebug, DefaultMessageKey, "debug")
+	_ = info.Log(ctx, LevelInfo, DefaultMessageKey, "info")
+	if count(errorW) != 1 || count(infoW) != 2 {
+		t.Errorf("reset====%d %d", count(errorW), count(infoW))
+	}
+
+	// Named 通过全局 logger 输出, 没有运行时级别时使用全局 logger 配置的级别, 只判断一次
+	SetLogger(info)
+	defer SetLogger(DefaultLogger)
+	consumer := Named("levels.consumer")
+	_ = consumer.Log(ctx, LevelDebug, DefaultMessageKey, "debug")
+	_ = consumer.Log(ctx, LevelInfo, DefaultMessageKey, "info")
+	if count(infoW) != 3 || GetLevel("levels.consumer") != LevelInfo {
+		t.Errorf("named====%d %s", count(infoW), GetLevel("levels.consumer"))
+	}
+	SetLevelWithTTL("levels", LevelError, time.Minute)
+	defer ResetLevel("levels")
+	_ = consumer.Log(ctx, LevelWarn, DefaultMessageKey, "warn")
+	if count(infoW) != 3 || GetLevel("levels.consumer") != LevelError {
+		t.Errorf("named runtime====%d %s", count(infoW), GetLevel("levels.consumer"))
+	}
+}
+
+func TestLevelHandler(t *testing.T) {
+	defer ResetLevel("handler")
+	Named("handler")
+
+	handler := LevelHandler()
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/debug/loglevel?name=handler&level=warn&ttl=1m", nil))
+	var state LevelState
+	if err := json.Unmarshal(rec.Body.Bytes(), &state); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Code != http.StatusOK || state.Level != "WARN" || !state.Explicit || state.RevertAt == nil {
+		t.Errorf("put====%d %s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/debug/loglevel?name=handler&level=verbose", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("invalid level====%d %s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/loglevel", nil))
+	var states []LevelState
+	if err := json.Unmarshal(rec.Body.Bytes(), &states); err != nil {
+		t.Fatal(err)
+	}
+	var found bool
+	for _, s := range states {
+		if s.Name == "handler" && s.Level == "WARN" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("list====%s", rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/debug/loglevel?name=handler", nil))
+	if GetLevel("handler") != GetLevel(RootName) {
+		t.Errorf("delete====%s", rec.Body.String())
+	}
+}
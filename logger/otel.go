@@ -0,0 +1,167 @@
+package logger
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	otellog "go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/opendevops-cn/codo-golang-sdk/xnet/xip"
+)
+
+// OTelScopeName is the instrumentation scope name of records emitted by the OpenTelemetry bridge.
+const OTelScopeName = "github.com/opendevops-cn/codo-golang-sdk/logger"
+
+// WithLoggerProvider makes NewLogger emit every record through the OpenTelemetry Logs API as well.
+func WithLoggerProvider(provider otellog.LoggerProvider) LogConfigOption {
+	return func(c *LogConfig) {
+		c.LoggerProvider = provider
+	}
+}
+
+type otelLogger struct {
+	logger otellog.Logger
+	// leveled is true when the level is already checked by the caller, e.g. the logger of NewLogger.
+	leveled bool
+}
+
+// NewOTelLogger returns a logger emitting records through the OpenTelemetry Logs API.
+// The message key becomes the record body, other keyvals become attributes,
+// trace and span id are taken from ctx by the provider.
+func NewOTelLogger(provider otellog.LoggerProvider) Logger {
+	return &otelLogger{logger: provider.Logger(OTelScopeName)}
+}
+
+// Log emits the kv pairs as an OpenTelemetry log record if level is enabled.
+func (l *otelLogger) Log(ctx context.Context, level Level, keyvals ...interface{}) error {
+	if !l.leveled && !levels.enabled(loggerName(ctx), level, LevelDebug) {
+		return nil
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, keyvals = bindContext(ctx, keyvals)
+	if (len(keyvals) & 1) == 1 {
+		keyvals = append(keyvals, "KEYVALS UNPAIRED")
+	}
+
+	now := time.Now()
+	var record otellog.Record
+	record.SetTimestamp(now)
+	record.SetObservedTimestamp(now)
+	record.SetSeverity(convOTelSeverity(level))
+	record.SetSeverityText(level.String())
+	for i := 0; i < len(keyvals); i += 2 {
+		key := fmt.Sprint(keyvals[i])
+		if key == DefaultMessageKey {
+			record.SetBody(otellog.StringValue(fmt.Sprint(keyvals[i+1])))
+			continue
+		}
+		record.AddAttributes(otelAttributes(key, keyvals[i+1])...)
+	}
+	l.logger.Emit(ctx, record)
+	return nil
+}
+
+func convOTelSeverity(level Level) otellog.Severity {
+	switch level {
+	case LevelDebug:
+		return otellog.SeverityDebug
+	case LevelInfo:
+		return otellog.SeverityInfo
+	case LevelWarn:
+		return otellog.SeverityWarn
+	case LevelError:
+		return otellog.SeverityError
+	case LevelFatal:
+		return otellog.SeverityFatal
+	default:
+		return otellog.SeverityUndefined
+	}
+}
+
+// otelAttributes keeps the type of value like appendZapField, errors carry their stack in key.stack.
+func otelAttributes(key string, value interface{}) []otellog.KeyValue {
+	switch v := value.(type) {
+	case nil:
+		return []otellog.KeyValue{{Key: key}}
+	case error:
+		if isNil(v) {
+			return []otellog.KeyValue{{Key: key}}
+		}
+		attrs := []otellog.KeyValue{otellog.String(key, v.Error())}
+		if stack, ok := errorStack(v); ok {
+			attrs = append(attrs, otellog.String(key+StackSuffix, stack))
+		}
+		return attrs
+	case proto.Message:
+		bs, err := protojson.Marshal(v)
+		if err != nil {
+			return []otellog.KeyValue{otellog.String(key, fmt.Sprint(v))}
+		}
+		return []otellog.KeyValue{otellog.String(key, string(bs))}
+	case json.Marshaler:
+		bs, err := v.MarshalJSON()
+		if err != nil {
+			return []otellog.KeyValue{otellog.String(key, fmt.Sprint(v))}
+		}
+		return []otellog.KeyValue{otellog.String(key, string(bs))}
+	case string:
+		return []otellog.KeyValue{otellog.String(key, v)}
+	case bool:
+		return []otellog.KeyValue{otellog.Bool(key, v)}
+	case int:
+		return []otellog.KeyValue{otellog.Int(key, v)}
+	case int8:
+		return []otellog.KeyValue{otellog.Int64(key, int64(v))}
+	case int16:
+		return []otellog.KeyValue{otellog.Int64(key, int64(v))}
+	case int32:
+		return []otellog.KeyValue{otellog.Int64(key, int64(v))}
+	case int64:
+		return []otellog.KeyValue{otellog.Int64(key, v)}
+	case uint8:
+		return []otellog.KeyValue{otellog.Int64(key, int64(v))}
+	case uint16:
+		return []otellog.KeyValue{otellog.Int64(key, int64(v))}
+	case uint32:
+		return []otellog.KeyValue{otellog.Int64(key, int64(v))}
+	case float32:
+		return []otellog.KeyValue{otellog.Float64(key, float64(v))}
+	case float64:
+		return []otellog.KeyValue{otellog.Float64(key, v)}
+	case []byte:
+		return []otellog.KeyValue{otellog.Bytes(key, v)}
+	case time.Duration:
+		return []otellog.KeyValue{otellog.String(key, v.String())}
+	case time.Time:
+		return []otellog.KeyValue{otellog.String(key, v.Format(time.RFC3339Nano))}
+	case fmt.Stringer:
+		return []otellog.KeyValue{otellog.String(key, v.String())}
+	default:
+		// uint, uint64 and uintptr may overflow int64
+		return []otellog.KeyValue{otellog.String(key, fmt.Sprint(v))}
+	}
+}
+
+// NewOTelResource returns the default resource with host.name and host.ip of current machine,
+// attrs override the detected attributes.
+func NewOTelResource(attrs ...attribute.KeyValue) (*resource.Resource, error) {
+	var hostAttrs []attribute.KeyValue
+	if hostname, err := os.Hostname(); err == nil {
+		hostAttrs = append(hostAttrs, semconv.HostName(hostname))
+	}
+	if ip, err := xip.GetIntranetIp(); err == nil && ip != "" {
+		hostAttrs = append(hostAttrs, semconv.HostIP(ip))
+	}
+	hostAttrs = append(hostAttrs, attrs...)
+	return resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL, hostAttrs...))
+}
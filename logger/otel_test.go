@@ -0,0 +1,94 @@
+package logger
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	pkgerrors "github.com/pkg/errors"
+	otellog "go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// memoryExporter keeps exported records in memory.
+type memoryExporter struct {
+	mu      sync.Mutex
+	records []sdklog.Record
+}
+
+func (e *memoryExporter) Export(_ context.Context, records []sdklog.Record) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, r := range records {
+		e.records = append(e.records, r.Clone())
+	}
+	return nil
+}
+
+func (e *memoryExporter) Shutdown(context.Context) error   { return nil }
+func (e *memoryExporter) ForceFlush(context.Context) error { return nil }
+
+func TestOTelLogger(t *testing.T) {
+	res, err := NewOTelResource()
+	if err != nil {
+		t.Fatal(err)
+	}
+	exporter := &memoryExporter{}
+	provider := sdklog.NewLoggerProvider(
+		sdklog.WithResource(res),
+		sdklog.WithProcessor(sdklog.NewSimpleProcessor(exporter)),
+	)
+	defer provider.Shutdown(context.Background())
+
+	traceID, _ := trace.TraceIDFromHex("0102030405060708090a0b0c0d0e0f10")
+	spanID, _ := trace.SpanIDFromHex("0102030405060708")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceID,
+		SpanID:  spanID,
+	}))
+
+	log := NewOTelLogger(provider)
+	if err := log.Log(ctx, LevelWarn, DefaultMessageKey, "hello", "count", 3, "err", pkgerrors.New("boom")); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(exporter.records) != 1 {
+		t.Fatalf("records====%d", len(exporter.records))
+	}
+	record := exporter.records[0]
+	if record.Severity() != otellog.SeverityWarn || record.SeverityText() != "WARN" {
+		t.Errorf("severity====%v %s", record.Severity(), record.SeverityText())
+	}
+	if record.Body().AsString() != "hello" {
+		t.Errorf("body====%v", record.Body())
+	}
+	if record.TraceID() != traceID || record.SpanID() != spanID {
+		t.Errorf("trace====%s %s", record.TraceID(), record.SpanID())
+	}
+	if record.InstrumentationScope().Name != OTelScopeName {
+		t.Errorf("scope====%s", record.InstrumentationScope().Name)
+	}
+
+	attrs := make(map[string]otellog.Value)
+	record.WalkAttributes(func(kv otellog.KeyValue) bool {
+		attrs[kv.Key] = kv.Value
+		return true
+	})
+	if attrs["count"].Kind() != otellog.KindInt64 || attrs["count"].AsInt64() != 3 {
+		t.Errorf("count====%v", attrs["count"])
+	}
+	if attrs["err"].AsString() != "boom" {
+		t.Errorf("err====%v", attrs["err"])
+	}
+	if !strings.Contains(attrs["err"+StackSuffix].AsString(), "TestOTelLogger") {
+		t.Errorf("stack====%v", attrs["err"+StackSuffix])
+	}
+
+	resource := record.Resource()
+	if _, ok := resource.Set().Value(semconv.HostNameKey); !ok {
+		t.Errorf("resource====%v", resource.Attributes())
+	}
+}
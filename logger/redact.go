@@ -0,0 +1,78 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/opendevops-cn/codo-golang-sdk/redact"
+)
+
+// WithRedactor masks sensitive keyvals of NewLogger, see NewRedactLogger.
+func WithRedactor(r *redact.Redactor) LogConfigOption {
+	return func(c *LogConfig) {
+		c.Redactor = r
+	}
+}
+
+type redactLogger struct {
+	logger   Logger
+	redactor *redact.Redactor
+}
+
+// NewRedactLogger returns a logger masking sensitive keyvals before logging, fields of ctx included.
+// Values of sensitive keys are replaced by the mask, string and []byte values are masked by
+// the pattern rules, JSON values are masked by key as well. Errors, fmt.Stringer and other
+// non-numeric values are masked by their text form and logged as is when nothing matches.
+func NewRedactLogger(logger Logger, r *redact.Redactor) Logger {
+	return &redactLogger{logger: logger, redactor: r}
+}
+
+// Log print the kv pairs log with sensitive values masked.
+func (l *redactLogger) Log(ctx context.Context, level Level, keyvals ...interface{}) error {
+	ctx, keyvals = bindContext(ctx, keyvals)
+	masked := make([]interface{}, len(keyvals))
+	copy(masked, keyvals)
+	for i := 0; i+1 < len(masked); i += 2 {
+		key := fmt.Sprint(masked[i])
+		switch v := masked[i+1].(type) {
+		case string:
+			masked[i+1] = l.redactor.Value(key, v)
+		case []byte:
+			if l.redactor.IsSensitiveKey(key) {
+				masked[i+1] = l.redactor.Mask()
+			} else {
+				masked[i+1] = string(l.redactor.JSON(v))
+			}
+		case error:
+			masked[i+1] = l.redactText(key, v, v.Error())
+		case fmt.Stringer:
+			masked[i+1] = l.redactText(key, v, v.String())
+		default:
+			if l.redactor.IsSensitiveKey(key) {
+				masked[i+1] = l.redactor.Mask()
+			} else if v != nil && !isScalar(reflect.ValueOf(v).Kind()) {
+				masked[i+1] = l.redactText(key, v, fmt.Sprint(v))
+			}
+		}
+	}
+	return l.logger.Log(ctx, level, masked...)
+}
+
+// redactText masks the text form of v, v is kept as is when nothing is masked.
+func (l *redactLogger) redactText(key string, v interface{}, text string) interface{} {
+	if masked := l.redactor.Value(key, text); masked != text {
+		return masked
+	}
+	return v
+}
+
+func isScalar(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		return true
+	}
+	return false
+}
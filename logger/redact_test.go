@@ -0,0 +1,33 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/opendevops-cn/codo-golang-sdk/redact"
+)
+
+func TestRedactLogger(t *testing.T) {
+	var buf bytes.Buffer
+	log := NewRedactLogger(NewStdLogger(&buf), redact.New())
+
+	ctx := NewContext(context.Background(), "auth_key", "abc")
+	_ = log.Log(ctx, LevelInfo,
+		DefaultMessageKey, "login 13812345678",
+		"password", 123,
+		"body", []byte(`{"token":"t","name":"n"}`),
+		"err", errors.New("user 13800138000 not found"),
+		"ip", net.IPv4(10, 0, 0, 1),
+		"emails", []string{"someone@example.com"},
+		"count", 13800138000,
+	)
+
+	want := `INFO auth_key=****** msg=login 138****5678 password=****** body={"name":"n","token":"******"} ` +
+		`err=user 138****8000 not found ip=10.0.0.1 emails=[s******@example.com] count=13800138000` + "\n"
+	if buf.String() != want {
+		t.Errorf("log====%q", buf.String())
+	}
+}
@@ -0,0 +1,533 @@
+package logger
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// RotateInterval is the period of time-based rotation.
+type RotateInterval string
+
+const (
+	RotateHourly = RotateInterval("hourly")
+	RotateDaily  = RotateInterval("daily")
+)
+
+// Compression is the compression of rotated files.
+type Compression string
+
+const (
+	CompressionGzip = Compression("gzip")
+	CompressionZstd = Compression("zstd")
+	CompressionNone = Compression("none")
+)
+
+const megabyte = 1024 * 1024
+
+// RotateConfig configures RotateWriter.
+type RotateConfig struct {
+	// Filename supports strftime verbs %Y %y %m %d %j %H %M %S and %%, e.g. /var/log/app.%Y%m%d%H.log.
+	// A file exceeding MaxSize within a period continues as app.2026101614.1.log, app.2026101614.2.log and so on.
+	Filename string `json:"filename" yaml:"filename"`
+	// Rotation rotates at the start of every hour or day, empty rotates by size only.
+	// Filename must contain verbs that change every period, e.g. %H for hourly.
+	Rotation RotateInterval `json:"rotation" yaml:"rotation"`
+	// MaxSize is the maximum size in megabytes of a file, 0 disables size rotation.
+	MaxSize int `json:"maxSize" yaml:"maxSize"`
+	// MaxAge is the maximum number of days to retain rotated files.
+	MaxAge int `json:"maxAge" yaml:"maxAge"`
+	// MaxBackups is the maximum number of rotated files to retain.
+	MaxBackups int `json:"maxBackups" yaml:"maxBackups"`
+	// MaxTotalSize is the maximum size in megabytes of all files including the current one,
+	// the oldest rotated files are removed first.
+	MaxTotalSize int `json:"maxTotalSize" yaml:"maxTotalSize"`
+	// Compression of rotated files defaults to gzip.
+	Compression Compression `json:"compression" yaml:"compression"`
+	// Symlink is updated to point to the current file if not empty.
+	Symlink string `json:"symlink" yaml:"symlink"`
+	// UTC formats file names and rotates periods in UTC instead of local time.
+	UTC bool `json:"utc" yaml:"utc"`
+}
+
+// RotateWriter is a file writer rotating by time and size. Rotated files are compressed and
+// removed by the retention policies in background.
+type RotateWriter struct {
+	c   RotateConfig
+	now func() time.Time
+
+	mu        sync.Mutex
+	file      *os.File
+	filename  string
+	base      string
+	index     int
+	size      int64
+	periodEnd time.Time
+
+	millCh chan struct{}
+	millWg sync.WaitGroup
+}
+
+// NewRotateWriter opens the current file, appending to it if it exists.
+func NewRotateWriter(c RotateConfig) (*RotateWriter, error) {
+	return newRotateWriter(c, time.Now)
+}
+
+func newRotateWriter(c RotateConfig, now func() time.Time) (*RotateWriter, error) {
+	if c.Filename == "" {
+		return nil, errors.New("filename is required")
+	}
+	switch c.Rotation {
+	case "":
+	case RotateHourly, RotateDaily:
+		// the file name must change every period, otherwise the same file is reopened forever
+		t := time.Date(2001, 2, 3, 4, 5, 6, 0, time.UTC)
+		if strftime(c.Filename, t) == strftime(c.Filename, periodEnd(t, c.Rotation)) {
+			return nil, fmt.Errorf("filename %q must contain strftime verbs changing every %s period, e.g. app.%%Y%%m%%d%%H.log", c.Filename, c.Rotation)
+		}
+	default:
+		return nil, fmt.Errorf("unknown rotation %q", c.Rotation)
+	}
+	switch c.Compression {
+	case "":
+		c.Compression = CompressionGzip
+	case CompressionGzip, CompressionZstd, CompressionNone:
+	default:
+		return nil, fmt.Errorf("unknown compression %q", c.Compression)
+	}
+	w := &RotateWriter{c: c, now: now, millCh: make(chan struct{}, 1)}
+	if err := w.open(w.now(), false); err != nil {
+		return nil, err
+	}
+	w.millWg.Add(1)
+	go w.millRun()
+	w.mill()
+	return w, nil
+}
+
+// Write rotates before writing p if the period ends or the file would exceed MaxSize.
+func (w *RotateWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		return 0, os.ErrClosed
+	}
+	now := w.now()
+	switch {
+	case !w.periodEnd.IsZero() && !now.Before(w.periodEnd):
+		if err := w.rotate(now, false); err != nil {
+			return 0, err
+		}
+	case w.c.MaxSize > 0 && w.size > 0 && w.size+int64(len(p)) > int64(w.c.MaxSize)*megabyte:
+		if err := w.rotate(now, true); err != nil {
+			return 0, err
+		}
+	}
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// Sync commits the current file to disk.
+func (w *RotateWriter) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return nil
+	}
+	return w.file.Sync()
+}
+
+// Close closes the current file and waits for the background compression.
+func (w *RotateWriter) Close() error {
+	w.mu.Lock()
+	if w.file == nil {
+		w.mu.Unlock()
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	close(w.millCh)
+	w.mu.Unlock()
+
+	w.millWg.Wait()
+	return err
+}
+
+// Filename returns the current file name.
+func (w *RotateWriter) Filename() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.filename
+}
+
+func (w *RotateWriter) rotate(now time.Time, bySize bool) error {
+	if err := w.file.Close(); err != nil {
+		return err
+	}
+	w.file = nil
+	if err := w.open(now, bySize); err != nil {
+		return err
+	}
+	w.mill()
+	return nil
+}
+
+// open opens the first file of the period that is not full, bySize skips the current one.
+func (w *RotateWriter) open(now time.Time, bySize bool) error {
+	if w.c.UTC {
+		now = now.UTC()
+	}
+	base := strftime(w.c.Filename, now)
+	index := 0
+	if bySize && base == w.base {
+		index = w.index + 1
+	}
+	if err := os.MkdirAll(filepath.Dir(base), 0o755); err != nil {
+		return err
+	}
+
+	var (
+		name string
+		size int64
+	)
+	for ; ; index++ {
+		name = indexedName(base, index)
+		if exists(name + w.compressExt()) {
+			continue
+		}
+		info, err := os.Stat(name)
+		if os.IsNotExist(err) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if w.c.MaxSize == 0 || info.Size() < int64(w.c.MaxSize)*megabyte {
+			size = info.Size()
+			break
+		}
+	}
+
+	file, err := os.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	w.file, w.filename, w.base, w.index, w.size = file, name, base, index, size
+	w.periodEnd = periodEnd(now, w.c.Rotation)
+
+	if w.c.Symlink != "" {
+		if err := symlink(name, w.c.Symlink); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mill asks the background goroutine to compress and remove rotated files.
+func (w *RotateWriter) mill() {
+	select {
+	case w.millCh <- struct{}{}:
+	default:
+	}
+}
+
+func (w *RotateWriter) millRun() {
+	defer w.millWg.Done()
+	for range w.millCh {
+		_ = w.millOnce()
+	}
+}
+
+type rotatedFile struct {
+	name    string
+	size    int64
+	modTime time.Time
+}
+
+func (w *RotateWriter) millOnce() error {
+	matches, err := filepath.Glob(strftimeGlob(w.c.Filename) + "*")
+	if err != nil {
+		return err
+	}
+	// read the current file after listing, a file rotated to in between is never compressed while written
+	w.mu.Lock()
+	current := w.filename
+	w.mu.Unlock()
+	// the glob also matches other files sharing the prefix, e.g. app.error.log for app.log
+	own := strftimeRegexp(w.c.Filename, w.compressExt())
+	var (
+		files     []rotatedFile
+		totalSize int64
+		errs      []error
+	)
+	for _, name := range matches {
+		if !own.MatchString(name) {
+			continue
+		}
+		info, err := os.Lstat(name)
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		if name == current {
+			totalSize += info.Size()
+			continue
+		}
+		file := rotatedFile{name: name, size: info.Size(), modTime: info.ModTime()}
+		if w.c.Compression != CompressionNone && !strings.HasSuffix(name, w.compressExt()) {
+			compressed, err := w.compress(name, info)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			file = compressed
+		}
+		files = append(files, file)
+		totalSize += file.size
+	}
+
+	// newest first
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].modTime.After(files[j].modTime)
+	})
+	cutoff := w.now().Add(-time.Duration(w.c.MaxAge) * 24 * time.Hour)
+	for i := len(files) - 1; i >= 0; i-- {
+		file := files[i]
+		remove := (w.c.MaxAge > 0 && file.modTime.Before(cutoff)) ||
+			(w.c.MaxBackups > 0 && i >= w.c.MaxBackups) ||
+			(w.c.MaxTotalSize > 0 && totalSize > int64(w.c.MaxTotalSize)*megabyte)
+		if !remove {
+			continue
+		}
+		if err := os.Remove(file.name); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		totalSize -= file.size
+	}
+	return errors.Join(errs...)
+}
+
+func (w *RotateWriter) compressExt() string {
+	switch w.c.Compression {
+	case CompressionGzip:
+		return ".gz"
+	case CompressionZstd:
+		return ".zst"
+	default:
+		return ""
+	}
+}
+
+// compress writes name to name.gz or name.zst through a temp file, keeping the modification time.
+func (w *RotateWriter) compress(name string, info os.FileInfo) (rotatedFile, error) {
+	dst := name + w.compressExt()
+	src, err := os.Open(name)
+	if err != nil {
+		return rotatedFile{}, err
+	}
+	defer src.Close()
+
+	tmp, err := os.OpenFile(dst+".tmp", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, info.Mode())
+	if err != nil {
+		return rotatedFile{}, err
+	}
+	defer os.Remove(tmp.Name())
+
+	var enc io.WriteCloser
+	if w.c.Compression == CompressionZstd {
+		enc, err = zstd.NewWriter(tmp)
+		if err != nil {
+			tmp.Close()
+			return rotatedFile{}, err
+		}
+	} else {
+		enc = gzip.NewWriter(tmp)
+	}
+	_, err = io.Copy(enc, src)
+	if closeErr := enc.Close(); err == nil {
+		err = closeErr
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return rotatedFile{}, err
+	}
+
+	if err := os.Chtimes(tmp.Name(), info.ModTime(), info.ModTime()); err != nil {
+		return rotatedFile{}, err
+	}
+	if err := os.Rename(tmp.Name(), dst); err != nil {
+		return rotatedFile{}, err
+	}
+	if err := os.Remove(name); err != nil {
+		return rotatedFile{}, err
+	}
+	compressed, err := os.Stat(dst)
+	if err != nil {
+		return rotatedFile{}, err
+	}
+	return rotatedFile{name: dst, size: compressed.Size(), modTime: info.ModTime()}, nil
+}
+
+func periodEnd(now time.Time, rotation RotateInterval) time.Time {
+	switch rotation {
+	case RotateHourly:
+		return time.Date(now.Year(), now.Month(), now.Day(), now.Hour(), 0, 0, 0, now.Location()).Add(time.Hour)
+	case RotateDaily:
+		return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()).AddDate(0, 0, 1)
+	default:
+		return time.Time{}
+	}
+}
+
+// indexedName app.log, 2 -> app.2.log
+func indexedName(name string, index int) string {
+	if index == 0 {
+		return name
+	}
+	ext := filepath.Ext(name)
+	return strings.TrimSuffix(name, ext) + "." + strconv.Itoa(index) + ext
+}
+
+// symlink points link to target atomically.
+func symlink(target, link string) error {
+	if abs, err := filepath.Abs(target); err == nil {
+		target = abs
+	}
+	tmp := link + ".tmp"
+	_ = os.Remove(tmp)
+	if err := os.Symlink(target, tmp); err != nil {
+		return err
+	}
+	return os.Rename(tmp, link)
+}
+
+func exists(name string) bool {
+	_, err := os.Stat(name)
+	return err == nil
+}
+
+// strftime formats t with the verbs supported by RotateConfig.Filename.
+func strftime(pattern string, t time.Time) string {
+	return formatPattern(pattern, func(verb byte) (string, bool) {
+		switch verb {
+		case 'Y':
+			return fmt.Sprintf("%04d", t.Year()), true
+		case 'y':
+			return fmt.Sprintf("%02d", t.Year()%100), true
+		case 'm':
+			return fmt.Sprintf("%02d", int(t.Month())), true
+		case 'd':
+			return fmt.Sprintf("%02d", t.Day()), true
+		case 'j':
+			return fmt.Sprintf("%03d", t.YearDay()), true
+		case 'H':
+			return fmt.Sprintf("%02d", t.Hour()), true
+		case 'M':
+			return fmt.Sprintf("%02d", t.Minute()), true
+		case 'S':
+			return fmt.Sprintf("%02d", t.Second()), true
+		case '%':
+			return "%", true
+		default:
+			return "", false
+		}
+	})
+}
+
+// strftimeGlob replaces the strftime verbs with *, the result with a trailing * matches all
+// files of the pattern including indexed and compressed ones.
+func strftimeGlob(pattern string) string {
+	name := formatPattern(pattern, func(verb byte) (string, bool) {
+		switch verb {
+		case 'Y', 'y', 'm', 'd', 'j', 'H', 'M', 'S':
+			return "*", true
+		case '%':
+			return "%", true
+		default:
+			return "", false
+		}
+	})
+	// indexed files insert .N before the extension
+	ext := filepath.Ext(name)
+	name = strings.TrimSuffix(name, ext) + "*" + ext
+	for strings.Contains(name, "**") {
+		name = strings.ReplaceAll(name, "**", "*")
+	}
+	return name
+}
+
+// strftimeRegexp matches only the files of the pattern: the verbs replaced by digits of their width,
+// an optional .N index before the extension and an optional compression extension.
+func strftimeRegexp(pattern, compressExt string) *regexp.Regexp {
+	const placeholder = "\x00"
+	var verbs []string
+	name := formatPattern(pattern, func(verb byte) (string, bool) {
+		switch verb {
+		case 'Y':
+			verbs = append(verbs, `\d{4}`)
+		case 'j':
+			verbs = append(verbs, `\d{3}`)
+		case 'y', 'm', 'd', 'H', 'M', 'S':
+			verbs = append(verbs, `\d{2}`)
+		case '%':
+			return "%", true
+		default:
+			return "", false
+		}
+		return placeholder, true
+	})
+	quote := func(s string) string {
+		parts := strings.Split(s, placeholder)
+		for i := range parts {
+			parts[i] = regexp.QuoteMeta(parts[i])
+		}
+		var b strings.Builder
+		for i, part := range parts {
+			if i > 0 {
+				b.WriteString(verbs[0])
+				verbs = verbs[1:]
+			}
+			b.WriteString(part)
+		}
+		return b.String()
+	}
+	ext := filepath.Ext(name)
+	expr := "^" + quote(strings.TrimSuffix(name, ext)) + `(\.\d+)?` + quote(ext)
+	if compressExt != "" {
+		expr += "(" + regexp.QuoteMeta(compressExt) + ")?"
+	}
+	return regexp.MustCompile(expr + "$")
+}
+
+func formatPattern(pattern string, verb func(byte) (string, bool)) string {
+	var b strings.Builder
+	for i := 0; i < len(pattern); i++ {
+		if pattern[i] != '%' || i+1 == len(pattern) {
+			b.WriteByte(pattern[i])
+			continue
+		}
+		i++
+		if s, ok := verb(pattern[i]); ok {
+			b.WriteString(s)
+			continue
+		}
+		b.WriteByte('%')
+		b.WriteByte(pattern[i])
+	}
+	return b.String()
+}
@@ -0,0 +1,216 @@
+package logger
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// fakeClock is advanced by tests.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Add(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func listDir(t *testing.T, dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestStrftime(t *testing.T) {
+	now := time.Date(2026, 10, 16, 14, 5, 9, 0, time.UTC)
+	if got := strftime("app.%Y%m%d%H.log", now); got != "app.2026101614.log" {
+		t.Errorf("strftime====%s", got)
+	}
+	if got := strftime("%y-%j %M:%S %% %q", now); got != "26-289 05:09 % %q" {
+		t.Errorf("strftime====%s", got)
+	}
+	if got := strftimeGlob("logs/app.%Y%m%d%H.log"); got != "logs/app.*.log" {
+		t.Errorf("glob====%s", got)
+	}
+}
+
+func TestRotateWriterHourly(t *testing.T) {
+	dir := t.TempDir()
+	clock := &fakeClock{now: time.Date(2026, 10, 16, 14, 30, 0, 0, time.UTC)}
+	w, err := newRotateWriter(RotateConfig{
+		Filename: filepath.Join(dir, "app.%Y%m%d%H.log"),
+		Rotation: RotateHourly,
+		Symlink:  filepath.Join(dir, "app.log"),
+		UTC:      true,
+	}, clock.Now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, _ = w.Write([]byte("a\n"))
+	clock.Add(40 * time.Minute)
+	_, _ = w.Write([]byte("b\n"))
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	got := strings.Join(listDir(t, dir), ",")
+	if got != "app.2026101614.log.gz,app.2026101615.log,app.log" {
+		t.Fatalf("files====%s", got)
+	}
+	target, _ := os.Readlink(filepath.Join(dir, "app.log"))
+	if filepath.Base(target) != "app.2026101615.log" {
+		t.Errorf("symlink====%s", target)
+	}
+
+	f, _ := os.Open(filepath.Join(dir, "app.2026101614.log.gz"))
+	defer f.Close()
+	r, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bs, _ := io.ReadAll(r); string(bs) != "a\n" {
+		t.Errorf("gzip====%q", bs)
+	}
+}
+
+func TestRotateWriterSize(t *testing.T) {
+	dir := t.TempDir()
+	clock := &fakeClock{now: time.Date(2026, 10, 16, 14, 0, 0, 0, time.UTC)}
+	w, err := newRotateWriter(RotateConfig{
+		Filename:    filepath.Join(dir, "app.%Y%m%d.log"),
+		Rotation:    RotateDaily,
+		MaxSize:     1,
+		Compression: CompressionZstd,
+		UTC:         true,
+	}, clock.Now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	line := []byte(strings.Repeat("x", megabyte-1) + "\n")
+	for i := 0; i < 3; i++ {
+		_, _ = w.Write(line)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	got := strings.Join(listDir(t, dir), ",")
+	if got != "app.20261016.1.log.zst,app.20261016.2.log,app.20261016.log.zst" {
+		t.Fatalf("files====%s", got)
+	}
+	f, _ := os.Open(filepath.Join(dir, "app.20261016.1.log.zst"))
+	defer f.Close()
+	r, err := zstd.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if bs, _ := io.ReadAll(r); len(bs) != megabyte {
+		t.Errorf("zstd====%d", len(bs))
+	}
+}
+
+func TestRotateWriterRetention(t *testing.T) {
+	dir := t.TempDir()
+	clock := &fakeClock{now: time.Date(2026, 10, 16, 14, 0, 0, 0, time.UTC)}
+	// rotated files of previous hours, the oldest first
+	for i, hour := range []string{"10", "11", "12", "13"} {
+		name := filepath.Join(dir, "app.20261016"+hour+".log")
+		if err := os.WriteFile(name, []byte("old\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		mtime := clock.now.Add(time.Duration(i-4) * time.Hour)
+		_ = os.Chtimes(name, mtime, mtime)
+	}
+
+	w, err := newRotateWriter(RotateConfig{
+		Filename:    filepath.Join(dir, "app.%Y%m%d%H.log"),
+		Rotation:    RotateHourly,
+		MaxBackups:  2,
+		Compression: CompressionNone,
+		UTC:         true,
+	}, clock.Now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	got := strings.Join(listDir(t, dir), ",")
+	if got != "app.2026101612.log,app.2026101613.log,app.2026101614.log" {
+		t.Errorf("files====%s", got)
+	}
+}
+
+func TestRotateWriterSkipsOtherFiles(t *testing.T) {
+	dir := t.TempDir()
+	clock := &fakeClock{now: time.Date(2026, 10, 16, 14, 0, 0, 0, time.UTC)}
+	// files of another sink and an unrelated backup sharing the prefix
+	for _, name := range []string{"app.error.log", "app.error.1.log", "app.log.bak", "app.2026101613.log"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("old\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	w, err := newRotateWriter(RotateConfig{
+		Filename:   filepath.Join(dir, "app.%Y%m%d%H.log"),
+		Rotation:   RotateHourly,
+		MaxBackups: 1,
+		UTC:        true,
+	}, clock.Now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	got := strings.Join(listDir(t, dir), ",")
+	if got != "app.2026101613.log.gz,app.2026101614.log,app.error.1.log,app.error.log,app.log.bak" {
+		t.Errorf("files====%s", got)
+	}
+}
+
+func TestRotateWriterRequiresVerbs(t *testing.T) {
+	dir := t.TempDir()
+	for _, c := range []RotateConfig{
+		{Filename: filepath.Join(dir, "app.log"), Rotation: RotateDaily},
+		{Filename: filepath.Join(dir, "app.%Y%m%d.log"), Rotation: RotateHourly},
+	} {
+		if _, err := NewRotateWriter(c); err == nil {
+			t.Errorf("%s %s: expected error", c.Filename, c.Rotation)
+		}
+	}
+	w, err := NewRotateWriter(RotateConfig{Filename: filepath.Join(dir, "app.log"), MaxSize: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = w.Close()
+}
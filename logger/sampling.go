@@ -0,0 +1,372 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// RepeatedKey is the key of the number of identical logs collapsed by the dedup logger.
+	RepeatedKey = "repeated"
+	// DroppedKey is the key of the number of logs dropped by the rate limit logger since the last log of the call site.
+	DroppedKey = "dropped"
+)
+
+// SamplingConfig logs the first First logs of each level and message per Tick, then every Thereafter-th.
+// Sampling is disabled when First is 0.
+type SamplingConfig struct {
+	// Tick defaults to 1 second.
+	Tick time.Duration `json:"tick" yaml:"tick" env:"DEFAULT_LOG_SAMPLING_TICK"`
+	// First is the number of logs per tick logged without sampling.
+	First int `json:"first" yaml:"first" env:"DEFAULT_LOG_SAMPLING_FIRST"`
+	// Thereafter logs every Thereafter-th log after First, 0 drops all of them.
+	Thereafter int `json:"thereafter" yaml:"thereafter" env:"DEFAULT_LOG_SAMPLING_THEREAFTER"`
+}
+
+// RateLimitConfig limits logs of each call site with a token bucket.
+// Rate limiting is disabled when Rate is 0.
+type RateLimitConfig struct {
+	// Rate is the number of logs per second.
+	Rate float64 `json:"rate" yaml:"rate" env:"DEFAULT_LOG_RATE_LIMIT"`
+	// Burst is the bucket size, defaults to Rate and at least 1.
+	Burst int `json:"burst" yaml:"burst" env:"DEFAULT_LOG_RATE_BURST"`
+	// CallerSkip is the number of frames to skip above the call site, for wrappers of the logger
+	// in other packages, so logs are limited where the wrappers are called.
+	CallerSkip int `json:"callerSkip" yaml:"callerSkip"`
+}
+
+// DedupConfig collapses identical logs within Window into one log with RepeatedKey.
+// Dedup is disabled when Window is 0.
+type DedupConfig struct {
+	Window time.Duration `json:"window" yaml:"window" env:"DEFAULT_LOG_DEDUP_WINDOW"`
+	// MaxKeys is the maximum number of distinct logs collapsed in a window, defaults to 1024.
+	MaxKeys int `json:"maxKeys" yaml:"maxKeys" env:"DEFAULT_LOG_DEDUP_MAX_KEYS"`
+}
+
+// WithSampling sets the sampling of NewLogger.
+func WithSampling(c SamplingConfig) LogConfigOption {
+	return func(lc *LogConfig) {
+		lc.Sampling = c
+	}
+}
+
+// WithRateLimit sets the per call site rate limit of NewLogger.
+func WithRateLimit(c RateLimitConfig) LogConfigOption {
+	return func(lc *LogConfig) {
+		lc.RateLimit = c
+	}
+}
+
+// WithDedup sets the dedup window of NewLogger.
+func WithDedup(c DedupConfig) LogConfigOption {
+	return func(lc *LogConfig) {
+		lc.Dedup = c
+	}
+}
+
+// wrapLimits wraps logger with dedup, sampling and rate limit, disabled ones are skipped.
+// Dedup comes first so identical logs are collapsed before they are counted.
+func wrapLimits(logger Logger, c *LogConfig) Logger {
+	if c.RateLimit.Rate > 0 {
+		logger = NewRateLimitLogger(logger, c.RateLimit)
+	}
+	if c.Sampling.First > 0 {
+		logger = NewSampleLogger(logger, c.Sampling)
+	}
+	if c.Dedup.Window > 0 {
+		logger = NewDedupLogger(logger, c.Dedup)
+	}
+	return logger
+}
+
+type sampleKey struct {
+	level Level
+	msg   string
+}
+
+type sampleLogger struct {
+	logger     Logger
+	tick       time.Duration
+	first      int
+	thereafter int
+
+	mu      sync.Mutex
+	resetAt time.Time
+	counts  map[sampleKey]int
+}
+
+// NewSampleLogger returns a logger sampling logs by level and the value of DefaultMessageKey.
+func NewSampleLogger(logger Logger, c SamplingConfig) Logger {
+	if c.Tick <= 0 {
+		c.Tick = time.Second
+	}
+	return &sampleLogger{
+		logger:     logger,
+		tick:       c.Tick,
+		first:      c.First,
+		thereafter: c.Thereafter,
+		counts:     make(map[sampleKey]int),
+	}
+}
+
+// Log print the kv pairs log if it is sampled.
+func (l *sampleLogger) Log(ctx context.Context, level Level, keyvals ...interface{}) error {
+	if !l.sample(sampleKey{level: level, msg: messageOf(keyvals)}) {
+		return nil
+	}
+	return l.logger.Log(ctx, level, keyvals...)
+}
+
+func (l *sampleLogger) sample(key sampleKey) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Counters of all keys reset together, so formatted messages do not grow the map beyond one tick.
+	if now := time.Now(); now.After(l.resetAt) {
+		clear(l.counts)
+		l.resetAt = now.Add(l.tick)
+	}
+	l.counts[key]++
+	n := l.counts[key]
+	if n <= l.first {
+		return true
+	}
+	return l.thereafter > 0 && (n-l.first)%l.thereafter == 0
+}
+
+func messageOf(keyvals []interface{}) string {
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		if key, ok := keyvals[i].(string); ok && key == DefaultMessageKey {
+			return fmt.Sprint(keyvals[i+1])
+		}
+	}
+	return ""
+}
+
+type tokenBucket struct {
+	tokens  float64
+	last    time.Time
+	dropped int
+}
+
+type rateLimitLogger struct {
+	logger     Logger
+	rate       float64
+	burst      float64
+	callerSkip int
+
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+}
+
+// NewRateLimitLogger returns a logger limiting logs of each call site with a token bucket.
+// The call site is the first caller outside this package and the log packages it adapts, so logs
+// through Helper, Named, the global functions, slog and kratos log are limited where they are called.
+// The next log of a call site after some are dropped carries the dropped number in DroppedKey.
+func NewRateLimitLogger(logger Logger, c RateLimitConfig) Logger {
+	burst := c.Burst
+	if burst <= 0 {
+		burst = int(c.Rate)
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return &rateLimitLogger{
+		logger:     logger,
+		rate:       c.Rate,
+		burst:      float64(burst),
+		callerSkip: c.CallerSkip,
+		buckets:    make(map[string]*tokenBucket),
+	}
+}
+
+// Log print the kv pairs log if the call site has tokens left.
+func (l *rateLimitLogger) Log(ctx context.Context, level Level, keyvals ...interface{}) error {
+	ok, dropped := l.allow(callSite(l.callerSkip))
+	if !ok {
+		return nil
+	}
+	if dropped > 0 {
+		keyvals = append(keyvals[:len(keyvals):len(keyvals)], DroppedKey, dropped)
+	}
+	return l.logger.Log(ctx, level, keyvals...)
+}
+
+func (l *rateLimitLogger) allow(site string) (bool, int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	bucket, ok := l.buckets[site]
+	if !ok {
+		bucket = &tokenBucket{tokens: l.burst, last: now}
+		l.buckets[site] = bucket
+	}
+	bucket.tokens = min(l.burst, bucket.tokens+now.Sub(bucket.last).Seconds()*l.rate)
+	bucket.last = now
+	if bucket.tokens < 1 {
+		bucket.dropped++
+		return false, 0
+	}
+	bucket.tokens--
+	dropped := bucket.dropped
+	bucket.dropped = 0
+	return true, dropped
+}
+
+// packagePath is the import path of this package.
+var packagePath = reflect.TypeOf(rateLimitLogger{}).PkgPath()
+
+// adapterPackages are the log packages adapted by this package, see NewSlogHandler and NewKratosLogger.
+// Their frames are skipped like the frames of this package when looking for the call site.
+var adapterPackages = map[string]bool{
+	"log/slog":                           true,
+	"github.com/go-kratos/kratos/v2/log": true,
+}
+
+// callSite returns file:line of the first caller outside this package and adapterPackages,
+// skip frames above it are skipped.
+func callSite(skip int) string {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if pkg := funcPackage(frame.Function); pkg != packagePath && !adapterPackages[pkg] {
+			if skip <= 0 {
+				return frame.File + ":" + strconv.Itoa(frame.Line)
+			}
+			skip--
+		}
+		if !more {
+			return ""
+		}
+	}
+}
+
+// funcPackage returns the import path of a function name, e.g. log/slog.(*Logger).Info -> log/slog.
+func funcPackage(function string) string {
+	slash := strings.LastIndexByte(function, '/')
+	if dot := strings.IndexByte(function[slash+1:], '.'); dot >= 0 {
+		return function[:slash+1+dot]
+	}
+	return function
+}
+
+// defaultDedupMaxKeys is the default of DedupConfig.MaxKeys.
+const defaultDedupMaxKeys = 1024
+
+type dedupEntry struct {
+	ctx      context.Context
+	level    Level
+	keyvals  []interface{}
+	repeated int
+}
+
+type dedupLogger struct {
+	logger  Logger
+	window  time.Duration
+	maxKeys int
+
+	mu      sync.Mutex
+	entries map[string]*dedupEntry
+	// flushing is true while the flush goroutine is running, it stops once no entries are left.
+	flushing bool
+}
+
+// NewDedupLogger returns a logger collapsing identical logs within the window.
+// The first log is printed at once, the identical ones are counted and printed as one log
+// with RepeatedKey by a single ticker every window, so a log is collapsed for at most one window.
+// Logs of LevelFatal and above are never collapsed, new logs are printed as is
+// once MaxKeys distinct logs are being collapsed.
+func NewDedupLogger(logger Logger, c DedupConfig) Logger {
+	if c.MaxKeys <= 0 {
+		c.MaxKeys = defaultDedupMaxKeys
+	}
+	return &dedupLogger{
+		logger:  logger,
+		window:  c.Window,
+		maxKeys: c.MaxKeys,
+		entries: make(map[string]*dedupEntry),
+	}
+}
+
+// Log print the kv pairs log unless an identical one is printed in the window.
+func (l *dedupLogger) Log(ctx context.Context, level Level, keyvals ...interface{}) error {
+	if level >= LevelFatal {
+		return l.logger.Log(ctx, level, keyvals...)
+	}
+	key := dedupKey(level, keyvals)
+
+	l.mu.Lock()
+	if entry, ok := l.entries[key]; ok {
+		entry.repeated++
+		l.mu.Unlock()
+		return nil
+	}
+	if len(l.entries) < l.maxKeys {
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		l.entries[key] = &dedupEntry{
+			ctx:     context.WithoutCancel(ctx),
+			level:   level,
+			keyvals: append([]interface{}(nil), keyvals...),
+		}
+		if !l.flushing {
+			l.flushing = true
+			go l.run()
+		}
+	}
+	l.mu.Unlock()
+
+	return l.logger.Log(ctx, level, keyvals...)
+}
+
+// run flushes the entries every window until there are none left.
+func (l *dedupLogger) run() {
+	ticker := time.NewTicker(l.window)
+	defer ticker.Stop()
+	for range ticker.C {
+		if !l.flush() {
+			return
+		}
+	}
+}
+
+// flush prints the repeated entries and clears all of them, it returns false when there were none
+// and the flush goroutine should stop.
+func (l *dedupLogger) flush() bool {
+	l.mu.Lock()
+	entries := l.entries
+	if len(entries) == 0 {
+		l.flushing = false
+		l.mu.Unlock()
+		return false
+	}
+	l.entries = make(map[string]*dedupEntry, len(entries))
+	l.mu.Unlock()
+
+	for _, entry := range entries {
+		if entry.repeated > 0 {
+			_ = l.logger.Log(entry.ctx, entry.level, append(entry.keyvals, RepeatedKey, entry.repeated)...)
+		}
+	}
+	return true
+}
+
+func dedupKey(level Level, keyvals []interface{}) string {
+	var b strings.Builder
+	b.WriteString(level.String())
+	for _, kv := range keyvals {
+		b.WriteByte(0)
+		_, _ = fmt.Fprint(&b, kv)
+	}
+	return b.String()
+}
@@ -0,0 +1,126 @@
+package logger
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordLogger keeps keyvals of every log.
+type recordLogger struct {
+	mu   sync.Mutex
+	logs [][]interface{}
+}
+
+func (l *recordLogger) Log(_ context.Context, _ Level, keyvals ...interface{}) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.logs = append(l.logs, keyvals)
+	return nil
+}
+
+func (l *recordLogger) len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.logs)
+}
+
+func TestSampleLogger(t *testing.T) {
+	rec := &recordLogger{}
+	log := NewSampleLogger(rec, SamplingConfig{Tick: time.Minute, First: 2, Thereafter: 3})
+	ctx := context.Background()
+	for i := 0; i < 10; i++ {
+		_ = log.Log(ctx, LevelError, DefaultMessageKey, "loop")
+	}
+	// 1, 2, then 5 and 8
+	if rec.len() != 4 {
+		t.Errorf("sampled====%d", rec.len())
+	}
+	// counters are kept per level and message
+	_ = log.Log(ctx, LevelError, DefaultMessageKey, "other")
+	_ = log.Log(ctx, LevelWarn, DefaultMessageKey, "loop")
+	if rec.len() != 6 {
+		t.Errorf("sampled====%d", rec.len())
+	}
+}
+
+func TestRateLimitLogger(t *testing.T) {
+	rec := &recordLogger{}
+	log := NewRateLimitLogger(rec, RateLimitConfig{Rate: 1, Burst: 2})
+	ctx := context.Background()
+	logA := func() {
+		_ = log.Log(ctx, LevelInfo, DefaultMessageKey, "a")
+	}
+	for i := 0; i < 5; i++ {
+		logA()
+	}
+	if rec.len() != 2 {
+		t.Fatalf("limited====%d", rec.len())
+	}
+
+	limiter := log.(*rateLimitLogger)
+	limiter.mu.Lock()
+	for _, bucket := range limiter.buckets {
+		bucket.last = bucket.last.Add(-time.Second)
+	}
+	limiter.mu.Unlock()
+	logA()
+	last := rec.logs[len(rec.logs)-1]
+	if len(last) != 4 || last[2] != DroppedKey || last[3] != 3 {
+		t.Errorf("dropped====%v", last)
+	}
+}
+
+func TestDedupLogger(t *testing.T) {
+	rec := &recordLogger{}
+	log := NewDedupLogger(rec, DedupConfig{Window: 50 * time.Millisecond})
+	ctx := context.Background()
+	for i := 0; i < 5; i++ {
+		_ = log.Log(ctx, LevelError, DefaultMessageKey, "same", "id", 1)
+	}
+	_ = log.Log(ctx, LevelError, DefaultMessageKey, "same", "id", 2)
+	if rec.len() != 2 {
+		t.Fatalf("deduped====%d", rec.len())
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	if rec.len() != 3 {
+		t.Fatalf("deduped====%d", rec.len())
+	}
+	last := rec.logs[2]
+	if len(last) != 6 || last[4] != RepeatedKey || last[5] != 4 {
+		t.Errorf("repeated====%v", last)
+	}
+}
+
+func TestDedupLoggerBounds(t *testing.T) {
+	rec := &recordLogger{}
+	log := NewDedupLogger(rec, DedupConfig{Window: time.Hour, MaxKeys: 2}).(*dedupLogger)
+	ctx := context.Background()
+
+	// Fatal logs are never collapsed
+	for i := 0; i < 3; i++ {
+		_ = log.Log(ctx, LevelFatal, DefaultMessageKey, "fatal")
+	}
+	if rec.len() != 3 {
+		t.Fatalf("fatal====%d", rec.len())
+	}
+
+	// Logs beyond MaxKeys are printed as is without being tracked
+	for i := 0; i < 4; i++ {
+		_ = log.Log(ctx, LevelError, DefaultMessageKey, "failed", "id", i)
+		_ = log.Log(ctx, LevelError, DefaultMessageKey, "failed", "id", i)
+	}
+	if rec.len() != 3+2+4 || len(log.entries) != 2 {
+		t.Fatalf("logs====%d entries====%d", rec.len(), len(log.entries))
+	}
+
+	// The flush goroutine stops once no entries are left
+	if !log.flush() || log.flush() {
+		t.Error("flush should stop when no entries are left")
+	}
+	if rec.len() != 3+2+4+2 || log.flushing {
+		t.Errorf("logs====%d flushing====%v", rec.len(), log.flushing)
+	}
+}
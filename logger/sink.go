@@ -0,0 +1,472 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// SinkType is the type of a log output.
+type SinkType string
+
+const (
+	SinkStdout = SinkType("stdout")
+	SinkStderr = SinkType("stderr")
+	// SinkFile writes to a rotating file.
+	SinkFile = SinkType("file")
+	// SinkSyslog sends RFC 3164 messages over UDP or TCP.
+	SinkSyslog = SinkType("syslog")
+)
+
+// SinkKey is the attribute key of the sink name in async writer metrics.
+const SinkKey = "sink"
+
+// SinkConfig is a log output with its own minimum level and encoding.
+type SinkConfig struct {
+	Type SinkType `json:"type" yaml:"type"`
+	// Name identifies the sink in metrics, defaults to Type.
+	Name string `json:"name" yaml:"name"`
+	// Level is the minimum level of the sink, empty writes all logs enabled by LogConfig.Level.
+	Level string `json:"level" yaml:"level"`
+	// Encoding defaults to LogConfig.Encoding.
+	Encoding LogEncoding `json:"encoding" yaml:"encoding"`
+
+	// 文件配置, 含义同 LogConfig
+	Filepath   string `json:"filepath" yaml:"filepath"`
+	MaxSize    int    `json:"maxSize" yaml:"maxSize"`
+	MaxAge     int    `json:"maxAge" yaml:"maxAge"`
+	MaxBackups int    `json:"maxBackups" yaml:"maxBackups"`
+	// 按时间轮转、压缩、软链接和总大小限制, 见 RotateConfig, 设置任意一项或 Filepath 含 strftime 时使用 RotateWriter
+	Rotation     RotateInterval `json:"rotation" yaml:"rotation"`
+	MaxTotalSize int            `json:"maxTotalSize" yaml:"maxTotalSize"`
+	Compression  Compression    `json:"compression" yaml:"compression"`
+	Symlink      string         `json:"symlink" yaml:"symlink"`
+
+	// syslog 配置
+	// Network is udp or tcp, defaults to udp.
+	Network string `json:"network" yaml:"network"`
+	Address string `json:"address" yaml:"address"`
+	// Tag defaults to the process name.
+	Tag string `json:"tag" yaml:"tag"`
+	// Facility defaults to 1 (user).
+	Facility int `json:"facility" yaml:"facility"`
+
+	// kafka 配置, 见 logger/sink/kafka
+	Topic string `json:"topic" yaml:"topic"`
+	// BootstrapServers is comma separated, empty uses DEFAULT_KAFKA_BOOTSTRAP_SERVERS of the kafka package.
+	BootstrapServers string `json:"bootstrapServers" yaml:"bootstrapServers"`
+
+	// Writer is used instead of creating one by Type, it is not closed by the logger.
+	Writer SinkWriter `json:"-" yaml:"-"`
+}
+
+// SinkWriter writes encoded logs with their level, see SinkConfig.Writer and RegisterSink.
+// p is reused after WriteLevel returns and must not be retained.
+type SinkWriter interface {
+	WriteLevel(level Level, p []byte) error
+	Sync() error
+}
+
+// SinkFactory creates the writer of a sink type registered by RegisterSink, sink.Name is resolved.
+// close releases the writer when the logger is closed, it may be nil.
+type SinkFactory func(c *LogConfig, sink SinkConfig) (w SinkWriter, close func() error, err error)
+
+var (
+	sinkFactoriesMu sync.RWMutex
+	sinkFactories   = make(map[SinkType]SinkFactory)
+)
+
+// RegisterSink makes a sink type implemented by another package available in SinkConfig,
+// e.g. importing logger/sink/kafka registers its SinkType. It panics if typ is registered twice.
+func RegisterSink(typ SinkType, factory SinkFactory) {
+	sinkFactoriesMu.Lock()
+	defer sinkFactoriesMu.Unlock()
+	if _, ok := sinkFactories[typ]; ok {
+		panic(fmt.Sprintf("logger: sink type %q registered twice", typ))
+	}
+	sinkFactories[typ] = factory
+}
+
+// WithSinks sets the outputs of NewLogger, replacing stdout and Filepath.
+func WithSinks(sinks ...SinkConfig) LogConfigOption {
+	return func(c *LogConfig) {
+		c.Sinks = sinks
+	}
+}
+
+// defaultSinks keeps the outputs when no sink is configured: stdout and the optional file.
+func defaultSinks(c *LogConfig) []SinkConfig {
+	sinks := []SinkConfig{{Type: SinkStdout}}
+	if c.Filepath != "" {
+		sinks = append(sinks, SinkConfig{
+			Type:       SinkFile,
+			Filepath:   c.Filepath,
+			MaxSize:    c.MaxSize,
+			MaxAge:     c.MaxAge,
+			MaxBackups: c.MaxBackups,
+
+			Rotation:     c.Rotation,
+			MaxTotalSize: c.MaxTotalSize,
+			Compression:  c.Compression,
+			Symlink:      c.Symlink,
+		})
+	}
+	return sinks
+}
+
+// newSinkCores builds a core for each sink, closers release the sinks in order.
+func newSinkCores(c *LogConfig) ([]zapcore.Core, []func() error, error) {
+	sinks := c.Sinks
+	if len(sinks) == 0 {
+		sinks = defaultSinks(c)
+	}
+
+	var (
+		cores   []zapcore.Core
+		closers []func() error
+		names   = make(map[string]bool)
+	)
+	for i, sink := range sinks {
+		name := sink.Name
+		if name == "" {
+			name = string(sink.Type)
+		}
+		if names[name] {
+			name = fmt.Sprintf("%s-%d", name, i)
+		}
+		names[name] = true
+
+		sink.Name = name
+		core, closer, err := newSinkCore(c, sink)
+		if err != nil {
+			_ = closeAll(closers)
+			return nil, nil, fmt.Errorf("log sink %s: %w", name, err)
+		}
+		cores = append(cores, core)
+		if closer != nil {
+			closers = append(closers, closer)
+		}
+	}
+	return cores, closers, nil
+}
+
+func newSinkCore(c *LogConfig, sink SinkConfig) (zapcore.Core, func() error, error) {
+	w, closer, err := newSinkWriter(c, sink)
+	if err != nil {
+		return nil, nil, err
+	}
+	out := w
+	if c.Async.BufferSize > 0 {
+		async, err := newAsyncWriter(w, c.Async, c.MeterProvider, attribute.String(SinkKey, sink.Name))
+		if err != nil {
+			if closer != nil {
+				_ = closer()
+			}
+			return nil, nil, err
+		}
+		out, closer = async, chainClosers(async.Close, closer)
+	}
+
+	level := zap.DebugLevel
+	if sink.Level != "" {
+		level = convZapLevel(ParseLevel(sink.Level))
+	}
+	encoding := sink.Encoding
+	if encoding == "" {
+		encoding = c.Encoding
+	}
+	return &sinkCore{LevelEnabler: level, enc: newEncoder(encoding), out: out}, closer, nil
+}
+
+func newSinkWriter(c *LogConfig, sink SinkConfig) (levelWriteSyncer, func() error, error) {
+	if sink.Writer != nil {
+		return sinkWriter{sink.Writer}, nil, nil
+	}
+	switch sink.Type {
+	case SinkStdout:
+		return levelWriter{zapcore.AddSync(os.Stdout)}, nil, nil
+	case SinkStderr:
+		return levelWriter{zapcore.AddSync(os.Stderr)}, nil, nil
+	case SinkFile:
+		if sink.Filepath == "" {
+			return nil, nil, errors.New("filepath is required")
+		}
+		if sink.useRotateWriter() {
+			w, err := NewRotateWriter(RotateConfig{
+				Filename:     sink.Filepath,
+				Rotation:     sink.Rotation,
+				MaxSize:      sink.MaxSize,
+				MaxAge:       sink.MaxAge,
+				MaxBackups:   sink.MaxBackups,
+				MaxTotalSize: sink.MaxTotalSize,
+				Compression:  sink.Compression,
+				Symlink:      sink.Symlink,
+			})
+			if err != nil {
+				return nil, nil, err
+			}
+			return levelWriter{w}, w.Close, nil
+		}
+		// 配置lumberjack日志轮转
+		w := &lumberjack.Logger{
+			Filename:   sink.Filepath,
+			MaxSize:    sink.MaxSize,
+			MaxBackups: sink.MaxBackups,
+			MaxAge:     sink.MaxAge,
+			Compress:   true,
+		}
+		return levelWriter{zapcore.AddSync(w)}, w.Close, nil
+	case SinkSyslog:
+		w, err := newSyslogWriter(sink)
+		if err != nil {
+			return nil, nil, err
+		}
+		return w, w.Close, nil
+	default:
+		sinkFactoriesMu.RLock()
+		factory, ok := sinkFactories[sink.Type]
+		sinkFactoriesMu.RUnlock()
+		if !ok {
+			return nil, nil, fmt.Errorf("unknown sink type %q", sink.Type)
+		}
+		w, closer, err := factory(c, sink)
+		if err != nil {
+			return nil, nil, err
+		}
+		return sinkWriter{w}, closer, nil
+	}
+}
+
+func (sink SinkConfig) useRotateWriter() bool {
+	return sink.Rotation != "" || sink.MaxTotalSize > 0 || sink.Compression != "" || sink.Symlink != "" ||
+		strings.Contains(sink.Filepath, "%")
+}
+
+func newEncoder(encoding LogEncoding) zapcore.Encoder {
+	// 编码配置
+	encoderConfig := zap.NewProductionEncoderConfig()
+	switch encoding {
+	case LogEncodingJSON:
+		return zapcore.NewJSONEncoder(encoderConfig)
+	default:
+		encoderConfig.EncodeTime = zapcore.RFC3339TimeEncoder
+		return zapcore.NewConsoleEncoder(encoderConfig)
+	}
+}
+
+func chainClosers(closers ...func() error) func() error {
+	return func() error {
+		var errs []error
+		for _, closer := range closers {
+			if closer != nil {
+				errs = append(errs, closer())
+			}
+		}
+		return errors.Join(errs...)
+	}
+}
+
+func closeAll(closers []func() error) error {
+	return chainClosers(closers...)()
+}
+
+// levelWriteSyncer is a WriteSyncer receiving the level of each log.
+type levelWriteSyncer interface {
+	WriteLevel(level zapcore.Level, p []byte) error
+	Sync() error
+}
+
+// levelWriter adapts a WriteSyncer ignoring the level.
+type levelWriter struct {
+	zapcore.WriteSyncer
+}
+
+func (w levelWriter) WriteLevel(_ zapcore.Level, p []byte) error {
+	_, err := w.Write(p)
+	return err
+}
+
+// sinkWriter adapts a SinkWriter of other packages.
+type sinkWriter struct {
+	SinkWriter
+}
+
+func (w sinkWriter) WriteLevel(level zapcore.Level, p []byte) error {
+	return w.SinkWriter.WriteLevel(fromZapLevel(level), p)
+}
+
+// fromZapLevel is the reverse of convZapLevel, DPanic and Panic are Fatal.
+func fromZapLevel(level zapcore.Level) Level {
+	switch {
+	case level < zapcore.InfoLevel:
+		return LevelDebug
+	case level == zapcore.InfoLevel:
+		return LevelInfo
+	case level == zapcore.WarnLevel:
+		return LevelWarn
+	case level == zapcore.ErrorLevel:
+		return LevelError
+	default:
+		return LevelFatal
+	}
+}
+
+// sinkCore encodes entries in the caller and writes them with their level.
+type sinkCore struct {
+	zapcore.LevelEnabler
+	enc zapcore.Encoder
+	out levelWriteSyncer
+}
+
+func (c *sinkCore) With(fields []zapcore.Field) zapcore.Core {
+	enc := c.enc.Clone()
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+	return &sinkCore{LevelEnabler: c.LevelEnabler, enc: enc, out: c.out}
+}
+
+func (c *sinkCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *sinkCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	buf, err := c.enc.EncodeEntry(ent, fields)
+	if err != nil {
+		return err
+	}
+	err = c.out.WriteLevel(ent.Level, buf.Bytes())
+	buf.Free()
+	if err != nil {
+		return err
+	}
+	// like zapcore.ioCore, flush before panic and fatal exit the process
+	if ent.Level > zapcore.ErrorLevel {
+		return c.out.Sync()
+	}
+	return nil
+}
+
+func (c *sinkCore) Sync() error {
+	return c.out.Sync()
+}
+
+// syslogWriter sends RFC 3164 messages, the connection is redialed once when a write fails.
+type syslogWriter struct {
+	network  string
+	address  string
+	tag      string
+	facility int
+	hostname string
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+func newSyslogWriter(sink SinkConfig) (*syslogWriter, error) {
+	if sink.Address == "" {
+		return nil, errors.New("address is required")
+	}
+	w := &syslogWriter{
+		network:  sink.Network,
+		address:  sink.Address,
+		tag:      sink.Tag,
+		facility: sink.Facility,
+		hostname: hostname(),
+	}
+	if w.network == "" {
+		w.network = "udp"
+	}
+	if w.tag == "" {
+		w.tag = filepath.Base(os.Args[0])
+	}
+	if w.facility == 0 {
+		w.facility = 1
+	}
+	conn, err := net.Dial(w.network, w.address)
+	if err != nil {
+		return nil, err
+	}
+	w.conn = conn
+	return w, nil
+}
+
+func hostname() string {
+	name, err := os.Hostname()
+	if err != nil {
+		return "-"
+	}
+	return name
+}
+
+func syslogSeverity(level zapcore.Level) int {
+	switch {
+	case level >= zapcore.FatalLevel:
+		return 2 // critical
+	case level >= zapcore.ErrorLevel:
+		return 3 // error
+	case level >= zapcore.WarnLevel:
+		return 4 // warning
+	case level >= zapcore.InfoLevel:
+		return 6 // informational
+	default:
+		return 7 // debug
+	}
+}
+
+func (w *syslogWriter) WriteLevel(level zapcore.Level, p []byte) error {
+	var msg bytes.Buffer
+	_, _ = fmt.Fprintf(&msg, "<%d>%s %s %s[%d]: ",
+		w.facility*8+syslogSeverity(level), time.Now().Format(time.Stamp), w.hostname, w.tag, os.Getpid())
+	msg.Write(bytes.TrimRight(p, "\n"))
+	// stream transports are framed by newline
+	if w.network != "udp" {
+		msg.WriteByte('\n')
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.conn != nil {
+		if _, err := w.conn.Write(msg.Bytes()); err == nil {
+			return nil
+		}
+		_ = w.conn.Close()
+		w.conn = nil
+	}
+	conn, err := net.Dial(w.network, w.address)
+	if err != nil {
+		return err
+	}
+	w.conn = conn
+	_, err = conn.Write(msg.Bytes())
+	return err
+}
+
+func (w *syslogWriter) Sync() error {
+	return nil
+}
+
+func (w *syslogWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.conn == nil {
+		return nil
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
@@ -0,0 +1,194 @@
+// Package kafka is the log sink producing every log as a message of a Kafka topic.
+// Importing it registers SinkType for logger.SinkConfig:
+//
+//	import _ "github.com/opendevops-cn/codo-golang-sdk/logger/sink/kafka"
+//
+//	logger.WithSinks(logger.SinkConfig{Type: kafka.SinkType, Topic: "logs", Level: "ERROR"})
+package kafka
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/IBM/sarama"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	codokafka "github.com/opendevops-cn/codo-golang-sdk/kafka"
+	"github.com/opendevops-cn/codo-golang-sdk/logger"
+)
+
+// SinkType produces logs with a producer created by kafka.NewProducer from SinkConfig.Topic and
+// SinkConfig.BootstrapServers, the producer is closed with the logger.
+const SinkType = logger.SinkType("kafka")
+
+const (
+	defaultBufferSize = 1024
+	// closeTimeout is how long Close waits for the producer to accept the buffered logs.
+	closeTimeout = 5 * time.Second
+)
+
+func init() {
+	logger.RegisterSink(SinkType, newSink)
+}
+
+func newSink(c *logger.LogConfig, sink logger.SinkConfig) (logger.SinkWriter, func() error, error) {
+	if sink.Topic == "" {
+		return nil, nil, errors.New("topic is required")
+	}
+	var opts []codokafka.KafkaConfigOption
+	if sink.BootstrapServers != "" {
+		opts = append(opts, codokafka.WithBootstrapServers(sink.BootstrapServers))
+	}
+	producer, cleanup, err := codokafka.NewProducer(opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	w, err := NewWriter(producer, Config{Topic: sink.Topic, Name: sink.Name, MeterProvider: c.MeterProvider})
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	return w, func() error {
+		err := w.Close()
+		cleanup()
+		return err
+	}, nil
+}
+
+// Config of NewWriter.
+type Config struct {
+	Topic string
+	// BufferSize is the number of logs waiting for the producer, defaults to 1024.
+	BufferSize int
+	// Name is the logger.SinkKey attribute of the dropped counter, defaults to SinkType.
+	Name string
+	// MeterProvider of the logger.DefaultAsyncDroppedName counter, defaults to the global one.
+	MeterProvider metric.MeterProvider
+}
+
+var _ logger.SinkWriter = (*Writer)(nil)
+
+// Writer produces every log as a message with the level in the "level" header.
+// Logs are buffered and handed to the producer in background, so a slow or unavailable
+// broker never blocks the caller; logs are dropped and counted when the buffer is full.
+type Writer struct {
+	producer sarama.AsyncProducer
+	topic    string
+	dropped  metric.Int64Counter
+	attrs    metric.MeasurementOption
+
+	buf       chan *sarama.ProducerMessage
+	closing   chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewWriter returns a writer producing logs with producer, set it as logger.SinkConfig.Writer to use
+// a producer of your own. Close the writer before the producer, neither is closed by the logger.
+func NewWriter(producer sarama.AsyncProducer, c Config) (*Writer, error) {
+	if c.Topic == "" {
+		return nil, errors.New("topic is required")
+	}
+	if c.BufferSize <= 0 {
+		c.BufferSize = defaultBufferSize
+	}
+	if c.Name == "" {
+		c.Name = string(SinkType)
+	}
+	if c.MeterProvider == nil {
+		c.MeterProvider = otel.GetMeterProvider()
+	}
+	dropped, err := c.MeterProvider.Meter("codo/logger").Int64Counter(logger.DefaultAsyncDroppedName, metric.WithUnit("{log}"))
+	if err != nil {
+		return nil, err
+	}
+
+	w := &Writer{
+		producer: producer,
+		topic:    c.Topic,
+		dropped:  dropped,
+		attrs:    metric.WithAttributes(attribute.String(logger.SinkKey, c.Name)),
+		buf:      make(chan *sarama.ProducerMessage, c.BufferSize),
+		closing:  make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go w.run()
+	return w, nil
+}
+
+// WriteLevel buffers the log without blocking, it is dropped when the buffer is full.
+func (w *Writer) WriteLevel(level logger.Level, p []byte) error {
+	// p is reused by the logger after WriteLevel returns
+	value := bytes.TrimRight(append([]byte(nil), p...), "\n")
+	msg := &sarama.ProducerMessage{
+		Topic: w.topic,
+		Value: sarama.ByteEncoder(value),
+		Headers: []sarama.RecordHeader{
+			{Key: []byte(logger.LevelKey), Value: []byte(level.String())},
+		},
+	}
+	select {
+	case w.buf <- msg:
+	default:
+		w.dropped.Add(context.Background(), 1, w.attrs)
+	}
+	return nil
+}
+
+func (w *Writer) Sync() error {
+	return nil
+}
+
+// Close hands the buffered logs to the producer, waiting at most 5 seconds, and stops the writer.
+func (w *Writer) Close() error {
+	w.closeOnce.Do(func() {
+		close(w.closing)
+	})
+	<-w.done
+	return nil
+}
+
+func (w *Writer) run() {
+	defer close(w.done)
+	for {
+		select {
+		case msg := <-w.buf:
+			select {
+			case w.producer.Input() <- msg:
+			case <-w.closing:
+				w.drain(msg)
+				return
+			}
+		case <-w.closing:
+			w.drain(nil)
+			return
+		}
+	}
+}
+
+// drain hands msg and the buffered logs to the producer until closeTimeout, the rest are dropped.
+func (w *Writer) drain(msg *sarama.ProducerMessage) {
+	timer := time.NewTimer(closeTimeout)
+	defer timer.Stop()
+	for {
+		if msg == nil {
+			select {
+			case msg = <-w.buf:
+			default:
+				return
+			}
+		}
+		select {
+		case w.producer.Input() <- msg:
+			msg = nil
+		case <-timer.C:
+			w.dropped.Add(context.Background(), int64(len(w.buf)+1), w.attrs)
+			return
+		}
+	}
+}
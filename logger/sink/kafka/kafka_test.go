@@ -0,0 +1,119 @@
+package kafka
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/IBM/sarama/mocks"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+
+	"github.com/opendevops-cn/codo-golang-sdk/logger"
+)
+
+func TestWriter(t *testing.T) {
+	defer logger.ResetLevel(logger.RootName)
+
+	producer := mocks.NewAsyncProducer(t, nil)
+	producer.ExpectInputWithMessageCheckerFunctionAndSucceed(func(msg *sarama.ProducerMessage) error {
+		value, _ := msg.Value.Encode()
+		if msg.Topic != "logs" || string(msg.Headers[0].Value) != "ERROR" || value[len(value)-1] == '\n' {
+			t.Errorf("kafka message====%v", msg)
+		}
+		return nil
+	})
+	defer producer.Close()
+
+	w, err := NewWriter(producer, Config{Topic: "logs"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	log, err := logger.NewLogger(logger.WithSinks(logger.SinkConfig{Type: SinkType, Writer: w, Level: "ERROR"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	_ = log.Log(ctx, logger.LevelInfo, logger.DefaultMessageKey, "started")
+	_ = log.Log(ctx, logger.LevelError, logger.DefaultMessageKey, "failed")
+	if err := log.(interface{ Close() error }).Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 通过 SinkType 注册, 缺少 topic 时创建失败
+	if _, err := logger.NewLogger(logger.WithSinks(logger.SinkConfig{Type: SinkType})); err == nil {
+		t.Error("sink without topic should fail")
+	}
+}
+
+// stuckProducer never takes messages until they are read from input.
+type stuckProducer struct {
+	sarama.AsyncProducer
+	input chan *sarama.ProducerMessage
+}
+
+func (p *stuckProducer) Input() chan<- *sarama.ProducerMessage {
+	return p.input
+}
+
+func TestWriterDropsWhenProducerIsStuck(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	producer := &stuckProducer{input: make(chan *sarama.ProducerMessage)}
+	w, err := NewWriter(producer, Config{
+		Topic:         "logs",
+		BufferSize:    2,
+		MeterProvider: sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 10; i++ {
+			_ = w.WriteLevel(logger.LevelInfo, []byte("line\n"))
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("WriteLevel blocked by the producer")
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatal(err)
+	}
+	var dropped int64
+	for _, m := range rm.ScopeMetrics[0].Metrics {
+		if data, ok := m.Data.(metricdata.Sum[int64]); ok && m.Name == logger.DefaultAsyncDroppedName {
+			dropped = data.DataPoints[0].Value
+		}
+	}
+	// at most one line is taken by the writer goroutine waiting for the producer
+	if dropped < 7 {
+		t.Errorf("dropped====%d", dropped)
+	}
+
+	// Close hands the buffered logs to the producer once it takes them
+	received := make(chan int)
+	go func() {
+		n := 0
+		for range producer.input {
+			n++
+		}
+		received <- n
+	}()
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	close(producer.input)
+	if n := <-received; int64(n)+dropped != 10 {
+		t.Errorf("produced====%d dropped====%d", n, dropped)
+	}
+}
@@ -0,0 +1,134 @@
+package logger
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordWriter keeps the level and text of every log.
+type recordWriter struct {
+	mu     sync.Mutex
+	levels []Level
+	logs   []string
+	closed bool
+}
+
+func (w *recordWriter) WriteLevel(level Level, p []byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.levels = append(w.levels, level)
+	w.logs = append(w.logs, string(p))
+	return nil
+}
+
+func (w *recordWriter) Sync() error {
+	return nil
+}
+
+func TestLoggerSinks(t *testing.T) {
+	defer ResetLevel(RootName)
+
+	dir := t.TempDir()
+	allFile := filepath.Join(dir, "all.log")
+	errorFile := filepath.Join(dir, "error.log")
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	custom := &recordWriter{}
+
+	log, err := NewLogger(
+		func(c *LogConfig) { c.Level = "DEBUG" },
+		WithSinks(
+			SinkConfig{Type: SinkFile, Filepath: allFile},
+			SinkConfig{Type: SinkFile, Filepath: errorFile, Level: "ERROR", Encoding: LogEncodingJSON},
+			SinkConfig{Type: SinkSyslog, Address: conn.LocalAddr().String(), Tag: "app", Level: "WARN"},
+			SinkConfig{Type: "custom", Writer: custom, Level: "ERROR"},
+		),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	_ = log.Log(ctx, LevelInfo, DefaultMessageKey, "started")
+	_ = log.Log(ctx, LevelError, DefaultMessageKey, "failed")
+	if err := log.(interface{ Close() error }).Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	all, _ := os.ReadFile(allFile)
+	if !strings.Contains(string(all), "started") || !strings.Contains(string(all), "failed") {
+		t.Errorf("all.log====%s", all)
+	}
+	errs, _ := os.ReadFile(errorFile)
+	if strings.Contains(string(errs), "started") || !strings.Contains(string(errs), `"msg":"failed"`) {
+		t.Errorf("error.log====%s", errs)
+	}
+
+	if len(custom.logs) != 1 || custom.levels[0] != LevelError || !strings.Contains(custom.logs[0], "failed") {
+		t.Errorf("custom====%v %q", custom.levels, custom.logs)
+	}
+
+	buf := make([]byte, 1024)
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// facility user(1) * 8 + severity error(3)
+	if msg := string(buf[:n]); !strings.HasPrefix(msg, "<11>") || !strings.Contains(msg, " app[") || !strings.Contains(msg, "failed") {
+		t.Errorf("syslog====%s", msg)
+	}
+}
+
+func TestLoggerSinkInvalid(t *testing.T) {
+	defer ResetLevel(RootName)
+	if _, err := NewLogger(WithSinks(SinkConfig{Type: "unknown"})); err == nil {
+		t.Error("unknown sink should fail")
+	}
+}
+
+func TestRegisterSink(t *testing.T) {
+	defer ResetLevel(RootName)
+
+	w := &recordWriter{}
+	RegisterSink("test", func(_ *LogConfig, sink SinkConfig) (SinkWriter, func() error, error) {
+		if sink.Name != "test-1" || sink.Topic != "logs" {
+			t.Errorf("sink====%+v", sink)
+		}
+		return w, func() error {
+			w.closed = true
+			return nil
+		}, nil
+	})
+
+	log, err := NewLogger(WithSinks(
+		SinkConfig{Type: "test", Name: "test-1", Topic: "logs"},
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = log.Log(context.Background(), LevelWarn, DefaultMessageKey, "slow")
+	if err := log.(interface{ Close() error }).Close(); err != nil {
+		t.Fatal(err)
+	}
+	if len(w.logs) != 1 || w.levels[0] != LevelWarn || !w.closed {
+		t.Errorf("levels====%v logs====%q closed====%v", w.levels, w.logs, w.closed)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("duplicate sink type should panic")
+		}
+	}()
+	RegisterSink("test", nil)
+}
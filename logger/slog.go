@@ -0,0 +1,166 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// SlogLevelFatal is the slog level mapped to LevelFatal, slog has no fatal level.
+const SlogLevelFatal = slog.Level(12)
+
+// ToSlogLevel converts level to the slog level.
+func ToSlogLevel(level Level) slog.Level {
+	switch level {
+	case LevelDebug:
+		return slog.LevelDebug
+	case LevelInfo:
+		return slog.LevelInfo
+	case LevelWarn:
+		return slog.LevelWarn
+	case LevelError:
+		return slog.LevelError
+	case LevelFatal:
+		return SlogLevelFatal
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// FromSlogLevel converts the slog level to level, levels between two slog levels round down.
+func FromSlogLevel(level slog.Level) Level {
+	switch {
+	case level >= SlogLevelFatal:
+		return LevelFatal
+	case level >= slog.LevelError:
+		return LevelError
+	case level >= slog.LevelWarn:
+		return LevelWarn
+	case level >= slog.LevelInfo:
+		return LevelInfo
+	default:
+		return LevelDebug
+	}
+}
+
+var _ slog.Handler = (*slogHandler)(nil)
+
+// slogHandler is a slog.Handler logging through a Logger.
+type slogHandler struct {
+	logger Logger
+	// attrs are added by WithAttrs, keys are prefixed by their groups.
+	attrs []interface{}
+	// group is the key prefix of WithGroup, e.g. "request.".
+	group string
+}
+
+// NewSlogHandler returns a slog.Handler logging through l, so slog.New(NewSlogHandler(l)) drives
+// libraries using log/slog. Group names prefix attribute keys with dots, e.g. request.method.
+func NewSlogHandler(l Logger) slog.Handler {
+	return &slogHandler{logger: l}
+}
+
+func (h *slogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	name := loggerName(ctx)
+	if named, ok := h.logger.(*namedLogger); ok {
+		name = named.name
+	}
+	return levels.enabled(name, FromSlogLevel(level), configuredLevel(h.logger))
+}
+
+func (h *slogHandler) Handle(ctx context.Context, r slog.Record) error {
+	keyvals := make([]interface{}, 0, 2+len(h.attrs)+2*r.NumAttrs())
+	keyvals = append(keyvals, DefaultMessageKey, r.Message)
+	keyvals = append(keyvals, h.attrs...)
+	r.Attrs(func(attr slog.Attr) bool {
+		keyvals = appendSlogAttr(keyvals, h.group, attr)
+		return true
+	})
+	return h.logger.Log(ctx, FromSlogLevel(r.Level), keyvals...)
+}
+
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	keyvals := make([]interface{}, 0, len(h.attrs)+2*len(attrs))
+	keyvals = append(keyvals, h.attrs...)
+	for _, attr := range attrs {
+		keyvals = appendSlogAttr(keyvals, h.group, attr)
+	}
+	return &slogHandler{logger: h.logger, attrs: keyvals, group: h.group}
+}
+
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &slogHandler{logger: h.logger, attrs: h.attrs, group: h.group + name + "."}
+}
+
+// appendSlogAttr flattens groups into dotted keys and ignores empty attributes like slog handlers do.
+func appendSlogAttr(keyvals []interface{}, prefix string, attr slog.Attr) []interface{} {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return keyvals
+	}
+	if attr.Value.Kind() == slog.KindGroup {
+		if attr.Key != "" {
+			prefix += attr.Key + "."
+		}
+		for _, item := range attr.Value.Group() {
+			keyvals = appendSlogAttr(keyvals, prefix, item)
+		}
+		return keyvals
+	}
+	return append(keyvals, prefix+attr.Key, attr.Value.Any())
+}
+
+// slogLogger is a Logger logging through a slog.Logger.
+type slogLogger struct {
+	logger *slog.Logger
+}
+
+// NewSlogLogger returns a Logger logging through l. The value of DefaultMessageKey becomes the
+// message, trace_id and span_id are added when ctx carries a span.
+func NewSlogLogger(l *slog.Logger) Logger {
+	return &slogLogger{logger: l}
+}
+
+// Log print the kv pairs log through slog.
+func (l *slogLogger) Log(ctx context.Context, level Level, keyvals ...interface{}) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	slogLevel := ToSlogLevel(level)
+	if !l.logger.Enabled(ctx, slogLevel) {
+		return nil
+	}
+	ctx, keyvals = bindContext(ctx, keyvals)
+	if (len(keyvals) & 1) == 1 {
+		keyvals = append(keyvals, "KEYVALS UNPAIRED")
+	}
+
+	var (
+		msg   string
+		attrs = make([]slog.Attr, 0, len(keyvals)/2+2)
+	)
+	if span := trace.SpanContextFromContext(ctx); span.IsValid() {
+		attrs = append(attrs,
+			slog.String("trace_id", span.TraceID().String()),
+			slog.String("span_id", span.SpanID().String()),
+		)
+	}
+	for i := 0; i < len(keyvals); i += 2 {
+		key := fmt.Sprint(keyvals[i])
+		if key == DefaultMessageKey {
+			msg = fmt.Sprint(keyvals[i+1])
+			continue
+		}
+		attrs = append(attrs, slog.Any(key, keyvals[i+1]))
+	}
+	l.logger.LogAttrs(ctx, slogLevel, msg, attrs...)
+	return nil
+}
@@ -0,0 +1,63 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	klog "github.com/go-kratos/kratos/v2/log"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestSlogHandler(t *testing.T) {
+	var buf bytes.Buffer
+	log := slog.New(NewSlogHandler(NewStdLogger(&buf)))
+	log = log.With("service", "api").WithGroup("req")
+	log.WarnContext(context.Background(), "slow", "method", "GET", slog.Group("user", "id", 1))
+
+	want := "WARN msg=slow service=api req.method=GET req.user.id=1\n"
+	if buf.String() != want {
+		t.Errorf("log====%q", buf.String())
+	}
+}
+
+func TestSlogLogger(t *testing.T) {
+	var buf bytes.Buffer
+	log := NewSlogLogger(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+
+	traceID, _ := trace.TraceIDFromHex("0102030405060708090a0b0c0d0e0f10")
+	spanID, _ := trace.SpanIDFromHex("0102030405060708")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceID,
+		SpanID:  spanID,
+	}))
+	_ = log.Log(ctx, LevelError, DefaultMessageKey, "failed", "code", 500)
+
+	var got map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got["level"] != "ERROR" || got["msg"] != "failed" || got["code"] != float64(500) || got["trace_id"] != traceID.String() {
+		t.Errorf("log====%v", got)
+	}
+}
+
+func TestLevelSlogRoundTrip(t *testing.T) {
+	for _, level := range []Level{LevelDebug, LevelInfo, LevelWarn, LevelError, LevelFatal} {
+		if got := FromSlogLevel(ToSlogLevel(level)); got != level {
+			t.Errorf("%s====%s", level, got)
+		}
+	}
+}
+
+func TestKratosLogger(t *testing.T) {
+	var buf bytes.Buffer
+	h := klog.NewHelper(NewKratosLogger(NewStdLogger(&buf)))
+	h.Warnf("lease %d expired", 1)
+
+	if want := "WARN msg=lease 1 expired\n"; buf.String() != want {
+		t.Errorf("log====%q", buf.String())
+	}
+}
@@ -32,8 +32,12 @@ func NewStdLogger(w io.Writer) Logger {
 	}
 }
 
-// Log print the kv pairs log.
+// Log print the kv pairs log unless a higher level is set by SetLevel.
 func (l *stdLogger) Log(ctx context.Context, level Level, keyvals ...interface{}) error {
+	if !levels.enabled(loggerName(ctx), level, LevelDebug) {
+		return nil
+	}
+	_, keyvals = bindContext(ctx, keyvals)
 	if l.isDiscard || len(keyvals) == 0 {
 		return nil
 	}
@@ -3,13 +3,11 @@ package mysql
 import (
 	"database/sql"
 	"fmt"
-	"os"
-	"strconv"
-	"strings"
 	"time"
 
 	"github.com/XSAM/otelsql"
 	_ "github.com/go-sql-driver/mysql"
+	"github.com/opendevops-cn/codo-golang-sdk/config"
 	"go.opentelemetry.io/otel"
 
 	"go.opentelemetry.io/otel/metric"
@@ -20,65 +18,49 @@ import (
 type DBConfigOption func(c *DBConfig)
 
 type DBConfig struct {
-	Host   string `json:"host" yaml:"host" env:"DEFAULT_MYSQL_HOST"`
-	Port   uint32 `json:"port" yaml:"port" env:"DEFAULT_MYSQL_PORT"`
-	User   string `json:"user" yaml:"user" env:"DEFAULT_MYSQL_USER"`
-	Pass   string `json:"pass" yaml:"pass" env:"DEFAULT_MYSQL_PASS"`
-	DBName string `json:"db_name" yaml:"dbName" env:"DEFAULT_MYSQL_DB_NAME"`
+	Host   string `json:"host" yaml:"host" env:"DEFAULT_MYSQL_HOST" default:"127.0.0.1" required:"true"`
+	Port   uint32 `json:"port" yaml:"port" env:"DEFAULT_MYSQL_PORT" default:"3306" min:"1" max:"65535"`
+	User   string `json:"user" yaml:"user" env:"DEFAULT_MYSQL_USER" default:"admin" required:"true"`
+	Pass   string `json:"pass" yaml:"pass" env:"DEFAULT_MYSQL_PASS" default:"123456" secret:"true"`
+	DBName string `json:"db_name" yaml:"dbName" env:"DEFAULT_MYSQL_DB_NAME" default:"default" required:"true"`
 
-	ConnMaxIdleTime uint32 `json:"conn_max_idle_time" yaml:"connMaxIdleTime" env:"DEFAULT_MYSQL_CONN_MAX_IDLE_TIME"`
-	ConnMaxLifetime uint32 `json:"conn_max_lifetime" yaml:"connMaxLifetime" env:"DEFAULT_MYSQL_CONN_MAX_LIFETIME"`
-	MaxIdleConns    uint32 `json:"max_idle_conns" yaml:"maxIdleConns" env:"DEFAULT_MYSQL_MAX_IDLE_CONNS"`
-	MaxOpenConns    uint32 `json:"max_open_conns" yaml:"maxOpenConns" env:"DEFAULT_MYSQL_MAX_OPEN_CONNS"`
+	ConnMaxIdleTime uint32 `json:"conn_max_idle_time" yaml:"connMaxIdleTime" env:"DEFAULT_MYSQL_CONN_MAX_IDLE_TIME" default:"300"`
+	ConnMaxLifetime uint32 `json:"conn_max_lifetime" yaml:"connMaxLifetime" env:"DEFAULT_MYSQL_CONN_MAX_LIFETIME" default:"300"`
+	MaxIdleConns    uint32 `json:"max_idle_conns" yaml:"maxIdleConns" env:"DEFAULT_MYSQL_MAX_IDLE_CONNS" default:"60"`
+	MaxOpenConns    uint32 `json:"max_open_conns" yaml:"maxOpenConns" env:"DEFAULT_MYSQL_MAX_OPEN_CONNS" default:"60"`
 
 	TracerProvider trace.TracerProvider `json:"-"`
 	MeterProvider  metric.MeterProvider `json:"-"`
 }
 
-func defaultConfig() DBConfig {
-	emptyOr := func(str string, defaultVal string) string {
-		str = strings.TrimSpace(str)
-		if str == "" {
-			return defaultVal
-		}
-		return str
-	}
-	host := emptyOr(os.Getenv("DEFAULT_MYSQL_HOST"), "127.0.0.1")
-	port := emptyOr(os.Getenv("DEFAULT_MYSQL_PORT"), "3306")
-	user := emptyOr(os.Getenv("DEFAULT_MYSQL_USER"), "admin")
-	pass := emptyOr(os.Getenv("DEFAULT_MYSQL_PASS"), "123456")
-	dbName := emptyOr(os.Getenv("DEFAULT_MYSQL_DB_NAME"), "default")
-	connMaxIdleTime := emptyOr(os.Getenv("DEFAULT_MYSQL_CONN_MAX_IDLE_TIME"), "300")
-	connMaxLifetime := emptyOr(os.Getenv("DEFAULT_MYSQL_CONN_MAX_LIFETIME"), "300")
-	maxIdleConns := emptyOr(os.Getenv("DEFAULT_MYSQL_MAX_IDLE_CONNS"), "60")
-	maxOpenConns := emptyOr(os.Getenv("DEFAULT_MYSQL_MAX_OPEN_CONNS"), "60")
-
-	i64Port, _ := strconv.Atoi(port)
-	i64ConnMaxIdleTime, _ := strconv.Atoi(connMaxIdleTime)
-	i64ConnMaxLifetime, _ := strconv.Atoi(connMaxLifetime)
-	i64MaxIdleConns, _ := strconv.Atoi(maxIdleConns)
-	i64MaxOpenConns, _ := strconv.Atoi(maxOpenConns)
-	return DBConfig{
-		Host:            host,
-		Port:            uint32(i64Port),
-		User:            user,
-		Pass:            pass,
-		DBName:          dbName,
-		ConnMaxIdleTime: uint32(i64ConnMaxIdleTime),
-		ConnMaxLifetime: uint32(i64ConnMaxLifetime),
-		MaxIdleConns:    uint32(i64MaxIdleConns),
-		MaxOpenConns:    uint32(i64MaxOpenConns),
-
+func defaultConfig() (DBConfig, error) {
+	cfg := DBConfig{
 		TracerProvider: otel.GetTracerProvider(),
 		MeterProvider:  otel.GetMeterProvider(),
 	}
+	if err := config.ApplyDefaults(&cfg); err != nil {
+		return cfg, err
+	}
+	if err := config.LoadEnv("", &cfg); err != nil {
+		return cfg, err
+	}
+	return cfg, nil
 }
 
 func NewMysql(opts ...DBConfigOption) (*sql.DB, func(), error) {
-	cfg := defaultConfig()
+	cfg, err := defaultConfig()
+	if err != nil {
+		return nil, nil, err
+	}
 	for _, opt := range opts {
 		opt(&cfg)
 	}
+	if err := config.ResolveSecrets(&cfg, ""); err != nil {
+		return nil, nil, err
+	}
+	if err := config.Validate(&cfg); err != nil {
+		return nil, nil, err
+	}
 	netAddr := fmt.Sprintf("tcp(%s:%d)", cfg.Host, cfg.Port)
 	dsn := fmt.Sprintf("%s:%s@%s/%s?loc=Local&charset=utf8mb4&parseTime=True", cfg.User, cfg.Pass, netAddr, cfg.DBName)
 
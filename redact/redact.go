@@ -0,0 +1,213 @@
+// Package redact 敏感数据脱敏, 按 key 名称和正则规则遮盖日志、HTTP 头和 JSON 中的敏感内容
+package redact
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// DefaultMask 敏感 key 的值被整体替换为 DefaultMask
+const DefaultMask = "******"
+
+// DefaultKeys 默认的敏感 key, 匹配时忽略大小写、"_" 和 "-", key 包含其中任意一项即视为敏感,
+// 例如 db_password、X-Auth-Key、accessToken
+var DefaultKeys = []string{
+	"password", "passwd", "secret", "token", "auth_key", "authorization",
+	"cookie", "access_key", "private_key", "api_key",
+}
+
+var (
+	// PhonePattern 中国大陆手机号
+	PhonePattern = regexp.MustCompile(`\b1[3-9]\d{9}\b`)
+	// IDCardPattern 中国大陆 18 位身份证号
+	IDCardPattern = regexp.MustCompile(`\b\d{17}[\dXx]\b`)
+	// EmailPattern 邮箱
+	EmailPattern = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+)
+
+// Default 使用默认规则的 Redactor
+var Default = New()
+
+type patternRule struct {
+	re   *regexp.Regexp
+	mask func(match string) string
+}
+
+// Redactor 脱敏引擎, 创建后并发安全
+type Redactor struct {
+	keys     []string
+	patterns []patternRule
+	mask     string
+}
+
+// Option Redactor 配置
+type Option func(*Redactor)
+
+// WithKeys 追加敏感 key
+func WithKeys(keys ...string) Option {
+	return func(r *Redactor) {
+		for _, key := range keys {
+			r.keys = append(r.keys, normalizeKey(key))
+		}
+	}
+}
+
+// WithPattern 追加正则规则, 匹配的内容替换为 mask 的返回值
+func WithPattern(re *regexp.Regexp, mask func(match string) string) Option {
+	return func(r *Redactor) {
+		r.patterns = append(r.patterns, patternRule{re: re, mask: mask})
+	}
+}
+
+// WithMask 设置敏感 key 的值的替换内容, 默认为 DefaultMask
+func WithMask(mask string) Option {
+	return func(r *Redactor) {
+		r.mask = mask
+	}
+}
+
+// WithoutDefaults 清空默认的 key 和正则规则, 需要放在其他 Option 之前
+func WithoutDefaults() Option {
+	return func(r *Redactor) {
+		r.keys, r.patterns = nil, nil
+	}
+}
+
+// New 创建 Redactor, 默认包含 DefaultKeys 以及手机号、身份证号、邮箱规则
+func New(opts ...Option) *Redactor {
+	r := &Redactor{mask: DefaultMask}
+	WithKeys(DefaultKeys...)(r)
+	WithPattern(IDCardPattern, MaskMiddle(6, 4))(r)
+	WithPattern(PhonePattern, MaskMiddle(3, 4))(r)
+	WithPattern(EmailPattern, maskEmail)(r)
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// MaskMiddle 保留前 prefix 个和后 suffix 个字符, 中间替换为 *, 长度不足时整体替换
+func MaskMiddle(prefix, suffix int) func(string) string {
+	return func(s string) string {
+		runes := []rune(s)
+		if len(runes) <= prefix+suffix {
+			return strings.Repeat("*", len(runes))
+		}
+		return string(runes[:prefix]) + strings.Repeat("*", len(runes)-prefix-suffix) + string(runes[len(runes)-suffix:])
+	}
+}
+
+// maskEmail 保留用户名首字符和域名, alice@example.com -> a****@example.com
+func maskEmail(s string) string {
+	i := strings.LastIndexByte(s, '@')
+	if i <= 0 {
+		return s
+	}
+	return MaskMiddle(1, 0)(s[:i]) + s[i:]
+}
+
+// normalizeKey Auth-Key, auth_key, AuthKey -> authkey
+func normalizeKey(key string) string {
+	key = strings.ToLower(key)
+	return strings.NewReplacer("_", "", "-", "", ".", "").Replace(key)
+}
+
+// Mask 返回敏感 key 的值的替换内容
+func (r *Redactor) Mask() string {
+	return r.mask
+}
+
+// IsSensitiveKey key 是否为敏感 key
+func (r *Redactor) IsSensitiveKey(key string) bool {
+	key = normalizeKey(key)
+	if key == "" {
+		return false
+	}
+	for _, item := range r.keys {
+		if strings.Contains(key, item) {
+			return true
+		}
+	}
+	return false
+}
+
+// String 按正则规则脱敏
+func (r *Redactor) String(s string) string {
+	for _, rule := range r.patterns {
+		s = rule.re.ReplaceAllStringFunc(s, rule.mask)
+	}
+	return s
+}
+
+// Value 敏感 key 的值整体替换, 否则按正则规则脱敏, 值为 JSON 时按 JSON 脱敏
+func (r *Redactor) Value(key, value string) string {
+	if r.IsSensitiveKey(key) {
+		return r.mask
+	}
+	if looksLikeJSON(value) {
+		return string(r.JSON([]byte(value)))
+	}
+	return r.String(value)
+}
+
+// JSON 遮盖 JSON 中敏感 key 的值, 其余字符串按正则规则脱敏, 不是合法 JSON 时按字符串脱敏
+func (r *Redactor) JSON(data []byte) []byte {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var v interface{}
+	if err := decoder.Decode(&v); err != nil || decoder.More() {
+		return []byte(r.String(string(data)))
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(r.walk(v)); err != nil {
+		return []byte(r.String(string(data)))
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n"))
+}
+
+func (r *Redactor) walk(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for k, item := range value {
+			if r.IsSensitiveKey(k) {
+				value[k] = r.mask
+				continue
+			}
+			value[k] = r.walk(item)
+		}
+		return value
+	case []interface{}:
+		for i, item := range value {
+			value[i] = r.walk(item)
+		}
+		return value
+	case string:
+		return r.String(value)
+	default:
+		return v
+	}
+}
+
+// Header 返回脱敏后的 header 副本
+func (r *Redactor) Header(header http.Header) http.Header {
+	masked := make(http.Header, len(header))
+	for k, values := range header {
+		items := make([]string, len(values))
+		for i, value := range values {
+			items[i] = r.Value(k, value)
+		}
+		masked[k] = items
+	}
+	return masked
+}
+
+func looksLikeJSON(s string) bool {
+	s = strings.TrimSpace(s)
+	return len(s) > 1 && (s[0] == '{' && s[len(s)-1] == '}' || s[0] == '[' && s[len(s)-1] == ']')
+}
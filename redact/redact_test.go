@@ -0,0 +1,67 @@
+package redact
+
+import (
+	"net/http"
+	"regexp"
+	"testing"
+)
+
+func TestRedactorString(t *testing.T) {
+	r := New()
+	got := r.String("phone 13812345678, id 11010119900307123X, mail alice@example.com")
+	want := "phone 138****5678, id 110101********123X, mail a****@example.com"
+	if got != want {
+		t.Errorf("String====%s", got)
+	}
+}
+
+func TestRedactorKeys(t *testing.T) {
+	r := New(WithKeys("ticket"))
+	for _, key := range []string{"password", "DB_PASSWORD", "X-Auth-Key", "accessToken", "Cookie", "ticket_id"} {
+		if !r.IsSensitiveKey(key) {
+			t.Errorf("%s should be sensitive", key)
+		}
+	}
+	for _, key := range []string{"user", "path", ""} {
+		if r.IsSensitiveKey(key) {
+			t.Errorf("%s should not be sensitive", key)
+		}
+	}
+}
+
+func TestRedactorJSON(t *testing.T) {
+	r := New()
+	got := string(r.JSON([]byte(`{"user":{"name":"a<b>","password":"p","phones":["13812345678"]},"id":12345678901234567890,"token":{"a":1}}`)))
+	want := `{"id":12345678901234567890,"token":"******","user":{"name":"a<b>","password":"******","phones":["138****5678"]}}`
+	if got != want {
+		t.Errorf("JSON====%s", got)
+	}
+
+	if got := string(r.JSON([]byte(`not json 13812345678`))); got != "not json 138****5678" {
+		t.Errorf("JSON====%s", got)
+	}
+}
+
+func TestRedactorHeader(t *testing.T) {
+	r := New()
+	header := http.Header{}
+	header.Set("Set-Cookie", "auth_key=abc; Path=/")
+	header.Set("Content-Type", "application/json")
+	masked := r.Header(header)
+	if masked.Get("Set-Cookie") != DefaultMask || masked.Get("Content-Type") != "application/json" {
+		t.Errorf("Header====%v", masked)
+	}
+	if header.Get("Set-Cookie") != "auth_key=abc; Path=/" {
+		t.Error("header should not be modified")
+	}
+}
+
+func TestRedactorOptions(t *testing.T) {
+	r := New(WithoutDefaults(), WithMask("[hidden]"), WithKeys("pin"), WithPattern(regexp.MustCompile(`\d{4}`), MaskMiddle(0, 0)))
+	if got := r.Value("pin", "1"); got != "[hidden]" {
+		t.Errorf("Value====%s", got)
+	}
+	if got := r.Value("msg", "code 1234 to a@b.com"); got != "code **** to a@b.com" {
+		t.Errorf("Value====%s", got)
+	}
+}
@@ -1,13 +1,12 @@
 package redis
 
 import (
-	"os"
 	"strconv"
-	"strings"
 	"time"
 
 	redisotelv8 "github.com/go-redis/redis/extra/redisotel/v8"
 	redisv8 "github.com/go-redis/redis/v8"
+	"github.com/opendevops-cn/codo-golang-sdk/config"
 	"github.com/redis/go-redis/extra/redisotel/v9"
 	"github.com/redis/go-redis/v9"
 
@@ -20,54 +19,45 @@ import (
 type RedisConfigOption func(*RedisConfig)
 
 type RedisConfig struct {
-	Host         string `json:"host" yaml:"host" env:"DEFAULT_REDIS_HOST"`
-	Port         uint32 `json:"port" yaml:"port" env:"DEFAULT_REDIS_PORT"`
-	Pass         string `json:"pass" yaml:"pass" env:"DEFAULT_REDIS_PASS"`
-	DialTimeout  uint32 `json:"dial_timeout" yaml:"dialTimeout" env:"DEFAULT_REDIS_DIAL_TIMEOUT"`
-	ReadTimeout  uint32 `json:"read_timeout" yaml:"readTimeout" env:"DEFAULT_REDIS_READ_TIMEOUT"`
-	WriteTimeout uint32 `json:"write_timeout" yaml:"writeTimeout" env:"DEFAULT_REDIS_WRITE_TIMEOUT"`
+	Host         string `json:"host" yaml:"host" env:"DEFAULT_REDIS_HOST" default:"127.0.0.1" required:"true"`
+	Port         uint32 `json:"port" yaml:"port" env:"DEFAULT_REDIS_PORT" default:"6379" min:"1" max:"65535"`
+	Pass         string `json:"pass" yaml:"pass" env:"DEFAULT_REDIS_PASS" default:"123456" secret:"true"`
+	DialTimeout  uint32 `json:"dial_timeout" yaml:"dialTimeout" env:"DEFAULT_REDIS_DIAL_TIMEOUT" default:"10"`
+	ReadTimeout  uint32 `json:"read_timeout" yaml:"readTimeout" env:"DEFAULT_REDIS_READ_TIMEOUT" default:"10"`
+	WriteTimeout uint32 `json:"write_timeout" yaml:"writeTimeout" env:"DEFAULT_REDIS_WRITE_TIMEOUT" default:"10"`
 
 	TracerProvider trace.TracerProvider `json:"-"`
 	MeterProvider  metric.MeterProvider `json:"-"`
 }
 
-func defaultRedisConfig() RedisConfig {
-	emptyOr := func(str string, defaultVal string) string {
-		str = strings.TrimSpace(str)
-		if str == "" {
-			return defaultVal
-		}
-		return str
-	}
-	host := emptyOr(os.Getenv("DEFAULT_REDIS_HOST"), "127.0.0.1")
-	port := emptyOr(os.Getenv("DEFAULT_REDIS_PORT"), "6379")
-	pass := emptyOr(os.Getenv("DEFAULT_REDIS_PASS"), "123456")
-	dialTimeout := emptyOr(os.Getenv("DEFAULT_REDIS_DIAL_TIMEOUT"), "10")
-	readTimeout := emptyOr(os.Getenv("DEFAULT_REDIS_READ_TIMEOUT"), "10")
-	writeTimeout := emptyOr(os.Getenv("DEFAULT_REDIS_WRITE_TIMEOUT"), "10")
-
-	i64Port, _ := strconv.Atoi(port)
-	i64DialTimeout, _ := strconv.Atoi(dialTimeout)
-	i64ReadTimeout, _ := strconv.Atoi(readTimeout)
-	i64WriteTimeout, _ := strconv.Atoi(writeTimeout)
-
-	return RedisConfig{
-		Host:           host,
-		Port:           uint32(i64Port),
-		Pass:           pass,
-		DialTimeout:    uint32(i64DialTimeout),
-		ReadTimeout:    uint32(i64ReadTimeout),
-		WriteTimeout:   uint32(i64WriteTimeout),
+func defaultRedisConfig() (RedisConfig, error) {
+	cfg := RedisConfig{
 		TracerProvider: otel.GetTracerProvider(),
 		MeterProvider:  otel.GetMeterProvider(),
 	}
+	if err := config.ApplyDefaults(&cfg); err != nil {
+		return cfg, err
+	}
+	if err := config.LoadEnv("", &cfg); err != nil {
+		return cfg, err
+	}
+	return cfg, nil
 }
 
 func NewRedis(opts ...RedisConfigOption) (*redis.Client, error) {
-	cfg := defaultRedisConfig()
+	cfg, err := defaultRedisConfig()
+	if err != nil {
+		return nil, err
+	}
 	for _, opt := range opts {
 		opt(&cfg)
 	}
+	if err := config.ResolveSecrets(&cfg, ""); err != nil {
+		return nil, err
+	}
+	if err := config.Validate(&cfg); err != nil {
+		return nil, err
+	}
 	addr := cfg.Host + ":" + strconv.Itoa(int(cfg.Port))
 	redisClient := redis.NewClient(&redis.Options{
 		Network:      "tcp",
@@ -97,10 +87,19 @@ func NewRedis(opts ...RedisConfigOption) (*redis.Client, error) {
 }
 
 func NewRedisV8(opts ...RedisConfigOption) (*redisv8.Client, error) {
-	cfg := defaultRedisConfig()
+	cfg, err := defaultRedisConfig()
+	if err != nil {
+		return nil, err
+	}
 	for _, opt := range opts {
 		opt(&cfg)
 	}
+	if err := config.ResolveSecrets(&cfg, ""); err != nil {
+		return nil, err
+	}
+	if err := config.Validate(&cfg); err != nil {
+		return nil, err
+	}
 	addr := cfg.Host + ":" + strconv.Itoa(int(cfg.Port))
 	redisClient := redisv8.NewClient(&redisv8.Options{
 		Network:      "tcp",
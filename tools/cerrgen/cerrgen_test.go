@@ -0,0 +1,161 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const orderYAML = `module: order
+package: ordererr
+range: [10000, 10999]
+codes:
+  - name: EOrderNotFoundCode
+    code: 10001
+    http: 404
+    messages:
+      zh: 订单不存在!
+      en: Order not found!
+  - name: EOrderPaidCode
+    code: 10002
+    http: 409
+    grpc: FAILED_PRECONDITION
+    messages:
+      zh: 订单已支付!
+`
+
+func writeDef(t *testing.T, name, content string) string {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func TestGen(t *testing.T) {
+	var out bytes.Buffer
+	if err := run("gen", []string{"-def", writeDef(t, "order.yaml", orderYAML)}, &out); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"package ordererr",
+		"EOrderNotFoundCode = cerr.ErrCode(10001)",
+		`cerr.WithRegisterCodeOptionTranslation("en", "Order not found!")`,
+		"cerr.WithRegisterCodeOptionGRPCCode(codes.FailedPrecondition)",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("missing %q====%s", want, out.String())
+		}
+	}
+}
+
+func TestExportJSON(t *testing.T) {
+	var out bytes.Buffer
+	if err := run("export", []string{"-def", writeDef(t, "order.yaml", orderYAML)}, &out); err != nil {
+		t.Fatal(err)
+	}
+	var items []jsonCode
+	if err := json.Unmarshal(out.Bytes(), &items); err != nil {
+		t.Fatal(err)
+	}
+	last := items[len(items)-1]
+	if items[0].Name != "SCode" || last.Name != "EOrderPaidCode" || last.GRPCCode != "FailedPrecondition" || last.Module != "order" {
+		t.Errorf("items====%+v %+v", items[0], last)
+	}
+}
+
+func TestProtoRoundTrip(t *testing.T) {
+	var exported bytes.Buffer
+	if err := run("export", []string{"-format", "proto", "-def", writeDef(t, "order.yaml", orderYAML)}, &exported); err != nil {
+		t.Fatal(err)
+	}
+	modules, err := loadModules([]string{writeDef(t, "codes.proto", exported.String())})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(modules) != 1 || modules[0].Module != "order" || len(modules[0].Codes) != 2 {
+		t.Fatalf("modules====%+v", modules)
+	}
+	def := modules[0].Codes[0]
+	if def.Name != "EOrderNotFoundCode" || def.HTTP != 404 || def.GRPC != "NotFound" || def.Messages["en"] != "Order not found!" {
+		t.Errorf("def====%+v", def)
+	}
+}
+
+func TestParseProto3(t *testing.T) {
+	const orderProto = `syntax = "proto3";
+
+package order.v1;
+
+// @module order
+// @package ordererr
+// @range 10000-10999
+enum OrderErrCode {
+  ORDER_ERR_CODE_UNSPECIFIED = 0;
+  // @http 404
+  // @zh 订单不存在!
+  // @en Order not found!
+  E_ORDER_NOT_FOUND_CODE = 10001;
+  // @skip
+  // 预留
+  E_ORDER_RESERVED_CODE = 10002;
+}
+`
+	modules, err := loadModules([]string{writeDef(t, "order.proto", orderProto)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(modules) != 1 || modules[0].Package != "ordererr" || len(modules[0].Codes) != 1 {
+		t.Fatalf("modules====%+v", modules)
+	}
+	def := modules[0].Codes[0]
+	if def.Name != "EOrderNotFoundCode" || def.Code != 10001 || def.HTTP != 404 || def.Messages["en"] != "Order not found!" {
+		t.Errorf("def====%+v", def)
+	}
+}
+
+func TestCheckModules(t *testing.T) {
+	cases := map[string]string{
+		"out of range": `module: pay
+range: [20000, 20999]
+codes:
+  - {name: EPayFailedCode, code: 30001, messages: {zh: 支付失败!}}
+`,
+		"overlaps": `module: pay
+range: [10500, 11999]
+codes:
+  - {name: EPayFailedCode, code: 11001, messages: {zh: 支付失败!}}
+`,
+		"duplicates a code of module builtin": `module: pay
+codes:
+  - {name: EPayFailedCode, code: 115, messages: {zh: 支付失败!}}
+`,
+		"duplicates a code": `module: pay
+codes:
+  - {name: EPayFailedCode, code: 10001, messages: {zh: 支付失败!}}
+`,
+		"no zh message": `module: pay
+codes:
+  - {name: EPayFailedCode, code: 20001, messages: {en: Payment failed!}}
+`,
+	}
+	order := writeDef(t, "order.yaml", orderYAML)
+	for want, content := range cases {
+		_, err := loadModules([]string{order, writeDef(t, "pay.yaml", content)})
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s====%v", want, err)
+		}
+	}
+}
+
+func TestSnakeCamel(t *testing.T) {
+	for _, name := range []string{"SCode", "EK8sCheckCode", "ECallApiCode", "EUnAuthCode"} {
+		if got := snakeToCamel(camelToSnake(name)); got != name {
+			t.Errorf("%s====%s %s", name, camelToSnake(name), got)
+		}
+	}
+}
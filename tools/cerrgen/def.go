@@ -0,0 +1,421 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"go/token"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"google.golang.org/grpc/codes"
+	"gopkg.in/yaml.v3"
+
+	"github.com/opendevops-cn/codo-golang-sdk/cerr"
+)
+
+// moduleDef 一个模块的错误码定义, YAML 格式为
+//
+//	module: order
+//	package: ordererr
+//	range: [10000, 10999]
+//	codes:
+//	  - name: EOrderNotFoundCode
+//	    code: 10001
+//	    http: 404
+//	    grpc: NotFound
+//	    messages:
+//	      zh: 订单不存在!
+//	      en: Order not found!
+//
+// .proto 格式使用注释注解, enum 上的注解作用于整个模块, 枚举值名称转为驼峰作为常量名
+//
+//	// @module order
+//	// @package ordererr
+//	// @range 10000-10999
+//	enum OrderErrCode {
+//	  ORDER_ERR_CODE_UNSPECIFIED = 0;
+//	  // @http 404
+//	  // @grpc NotFound
+//	  // @zh 订单不存在!
+//	  E_ORDER_NOT_FOUND_CODE = 10001;
+//	}
+//
+// proto3 要求的 0 值和标注了 @skip 的枚举值不是错误码, 会被跳过
+type moduleDef struct {
+	Module  string    `yaml:"module"`
+	Package string    `yaml:"package"`
+	Range   []int32   `yaml:"range"`
+	Codes   []codeDef `yaml:"codes"`
+	// file 定义文件, 用于错误提示
+	file string
+}
+
+// codeDef 一个错误码定义, http 默认 500, grpc 默认按 http 推导
+type codeDef struct {
+	Name     string            `yaml:"name"`
+	Code     int32             `yaml:"code"`
+	HTTP     int               `yaml:"http"`
+	GRPC     string            `yaml:"grpc"`
+	Messages map[string]string `yaml:"messages"`
+}
+
+func (d *codeDef) httpCode() int {
+	if d.HTTP == 0 {
+		return http.StatusInternalServerError
+	}
+	return d.HTTP
+}
+
+// grpcCode 返回 gRPC 状态码, 未指定时返回 false
+func (d *codeDef) grpcCode() (codes.Code, bool, error) {
+	if d.GRPC == "" {
+		// 和 cerr.ErrCode.AsGRPCCode 一致, 错误码不能推导为 OK
+		if code := cerr.HTTPToGRPCCode(d.httpCode()); code != codes.OK {
+			return code, false, nil
+		}
+		return codes.Unknown, false, nil
+	}
+	code, err := parseGRPCCode(d.GRPC)
+	if err == nil && code == codes.OK {
+		return code, true, fmt.Errorf("grpc code OK is reserved for success")
+	}
+	return code, true, err
+}
+
+// parseGRPCCode 支持 NotFound 和 NOT_FOUND 两种写法
+func parseGRPCCode(name string) (codes.Code, error) {
+	for c := codes.OK; c <= codes.Unauthenticated; c++ {
+		if c.String() == name {
+			return c, nil
+		}
+	}
+	var c codes.Code
+	if err := c.UnmarshalJSON([]byte(strconv.Quote(name))); err != nil {
+		return 0, fmt.Errorf("invalid grpc code %q", name)
+	}
+	return c, nil
+}
+
+// loadModules 读取定义文件, 合并同名模块并检查冲突
+func loadModules(files []string) ([]*moduleDef, error) {
+	var modules []*moduleDef
+	for _, file := range files {
+		bs, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		var items []*moduleDef
+		switch strings.ToLower(filepath.Ext(file)) {
+		case ".yaml", ".yml":
+			items, err = parseYAML(bs)
+		case ".proto":
+			items, err = parseProto(bs)
+		default:
+			err = fmt.Errorf("unsupported definition file, use .yaml, .yml or .proto")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		for _, item := range items {
+			item.file = file
+		}
+		modules = append(modules, items...)
+	}
+
+	modules, err := mergeModules(modules)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkModules(modules); err != nil {
+		return nil, err
+	}
+	return modules, nil
+}
+
+// parseYAML 解析 YAML 定义, 支持用 --- 分隔多个模块
+func parseYAML(bs []byte) ([]*moduleDef, error) {
+	var modules []*moduleDef
+	decoder := yaml.NewDecoder(bytes.NewReader(bs))
+	for {
+		m := &moduleDef{}
+		if err := decoder.Decode(m); err != nil {
+			if errors.Is(err, io.EOF) {
+				return modules, nil
+			}
+			return nil, err
+		}
+		modules = append(modules, m)
+	}
+}
+
+var (
+	protoEnumRe  = regexp.MustCompile(`^enum\s+(\w+)\s*\{`)
+	protoValueRe = regexp.MustCompile(`^(\w+)\s*=\s*(-?\d+)\s*(\[[^\]]*\])?\s*;`)
+	protoNoteRe  = regexp.MustCompile(`^//\s*@([\w-]+)\s*(.*)$`)
+)
+
+// parseProto 解析带注解的 .proto 定义, 只识别 enum 和注释注解
+func parseProto(bs []byte) ([]*moduleDef, error) {
+	var (
+		byName  = map[string]*moduleDef{}
+		order   []string
+		notes   [][2]string
+		enum    string
+		enumMod *moduleDef
+		lineNo  int
+	)
+	module := func(name string) *moduleDef {
+		m, ok := byName[name]
+		if !ok {
+			m = &moduleDef{Module: name}
+			byName[name] = m
+			order = append(order, name)
+		}
+		return m
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(bs))
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if match := protoNoteRe.FindStringSubmatch(line); match != nil {
+			notes = append(notes, [2]string{match[1], strings.TrimSpace(match[2])})
+			continue
+		}
+		if line == "" || strings.HasPrefix(line, "//") {
+			continue
+		}
+
+		switch {
+		case protoEnumRe.MatchString(line):
+			enum = protoEnumRe.FindStringSubmatch(line)[1]
+			name, pkg, rng := enum, "", ""
+			for _, note := range notes {
+				switch note[0] {
+				case "module":
+					name = note[1]
+				case "package":
+					pkg = note[1]
+				case "range":
+					rng = note[1]
+				}
+			}
+			enumMod = module(name)
+			if pkg != "" {
+				enumMod.Package = pkg
+			}
+			if rng != "" {
+				r, err := parseRange(rng)
+				if err != nil {
+					return nil, fmt.Errorf("line %d: %w", lineNo, err)
+				}
+				enumMod.Range = r
+			}
+		case enum != "" && strings.HasPrefix(line, "}"):
+			enum, enumMod = "", nil
+		case enum != "" && protoValueRe.MatchString(line):
+			match := protoValueRe.FindStringSubmatch(line)
+			value, err := strconv.ParseInt(match[2], 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNo, err)
+			}
+			def := codeDef{Name: snakeToCamel(match[1]), Code: int32(value), Messages: map[string]string{}}
+			m := enumMod
+			skip := value == 0
+			for _, note := range notes {
+				switch note[0] {
+				case "skip":
+					skip = true
+				case "module":
+					m = module(note[1])
+				case "http":
+					if def.HTTP, err = strconv.Atoi(note[1]); err != nil {
+						return nil, fmt.Errorf("line %d: invalid http status %q", lineNo, note[1])
+					}
+				case "grpc":
+					def.GRPC = note[1]
+				case "name":
+					def.Name = note[1]
+				case "package", "range":
+					// 只作用于 enum
+				default:
+					def.Messages[note[0]] = note[1]
+				}
+			}
+			// 内置 code 由 cerr 注册, 重新读取 export 导出的 .proto 时跳过
+			if !skip && m.Module != cerr.BuiltinModule {
+				m.Codes = append(m.Codes, def)
+			}
+		}
+		notes = notes[:0]
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	modules := make([]*moduleDef, 0, len(order))
+	for _, name := range order {
+		if m := byName[name]; len(m.Codes) > 0 {
+			modules = append(modules, m)
+		}
+	}
+	return modules, nil
+}
+
+// parseRange 解析 10000-10999
+func parseRange(s string) ([]int32, error) {
+	lo, hi, ok := strings.Cut(s, "-")
+	if !ok {
+		return nil, fmt.Errorf("invalid range %q, use MIN-MAX", s)
+	}
+	from, err1 := strconv.ParseInt(strings.TrimSpace(lo), 10, 32)
+	to, err2 := strconv.ParseInt(strings.TrimSpace(hi), 10, 32)
+	if err1 != nil || err2 != nil {
+		return nil, fmt.Errorf("invalid range %q, use MIN-MAX", s)
+	}
+	return []int32{int32(from), int32(to)}, nil
+}
+
+// mergeModules 合并多个文件中的同名模块, package 和 range 不能冲突
+func mergeModules(modules []*moduleDef) ([]*moduleDef, error) {
+	var (
+		merged []*moduleDef
+		byName = map[string]*moduleDef{}
+	)
+	for _, m := range modules {
+		if m.Module == "" {
+			return nil, fmt.Errorf("%s: module is required", m.file)
+		}
+		if m.Module == cerr.BuiltinModule {
+			return nil, fmt.Errorf("%s: module %q is reserved", m.file, m.Module)
+		}
+		prev, ok := byName[m.Module]
+		if !ok {
+			byName[m.Module] = m
+			merged = append(merged, m)
+			continue
+		}
+		if m.Package != "" && prev.Package != "" && m.Package != prev.Package {
+			return nil, fmt.Errorf("module %s: package %s in %s conflicts with %s in %s", m.Module, m.Package, m.file, prev.Package, prev.file)
+		}
+		if m.Range != nil && prev.Range != nil && fmt.Sprint(m.Range) != fmt.Sprint(prev.Range) {
+			return nil, fmt.Errorf("module %s: range %v in %s conflicts with %v in %s", m.Module, m.Range, m.file, prev.Range, prev.file)
+		}
+		if prev.Package == "" {
+			prev.Package = m.Package
+		}
+		if prev.Range == nil {
+			prev.Range = m.Range
+		}
+		prev.Codes = append(prev.Codes, m.Codes...)
+	}
+	return merged, nil
+}
+
+// checkModules 检查定义是否完整, code 和常量名是否重复, code 是否在模块区间内, 模块区间是否重叠
+func checkModules(modules []*moduleDef) error {
+	var (
+		codeOwners = map[int32]string{}
+		nameOwners = map[string]string{}
+	)
+	for _, info := range cerr.Codes() {
+		codeOwners[int32(info.Code)] = info.Module
+		if info.Name != "" {
+			nameOwners[info.Name] = info.Module
+		}
+	}
+
+	for _, m := range modules {
+		if m.Range != nil {
+			if len(m.Range) != 2 || m.Range[0] > m.Range[1] {
+				return fmt.Errorf("module %s: invalid range %v, use [MIN, MAX]", m.Module, m.Range)
+			}
+			for code, owner := range codeOwners {
+				if owner == cerr.BuiltinModule && inRange(m.Range, code) {
+					return fmt.Errorf("module %s: range %v contains builtin code %d", m.Module, m.Range, code)
+				}
+			}
+		}
+		for _, def := range m.Codes {
+			if !token.IsIdentifier(def.Name) || !token.IsExported(def.Name) {
+				return fmt.Errorf("module %s: code %d has invalid name %q", m.Module, def.Code, def.Name)
+			}
+			if def.Messages[cerr.DefaultLocale] == "" {
+				return fmt.Errorf("module %s: %s has no %s message", m.Module, def.Name, cerr.DefaultLocale)
+			}
+			if _, _, err := def.grpcCode(); err != nil {
+				return fmt.Errorf("module %s: %s: %w", m.Module, def.Name, err)
+			}
+			if m.Range != nil && !inRange(m.Range, def.Code) {
+				return fmt.Errorf("module %s: %s = %d is out of range %v", m.Module, def.Name, def.Code, m.Range)
+			}
+			if owner, ok := codeOwners[def.Code]; ok {
+				return fmt.Errorf("module %s: %s = %d duplicates a code of module %s", m.Module, def.Name, def.Code, owner)
+			}
+			if owner, ok := nameOwners[def.Name]; ok {
+				return fmt.Errorf("module %s: name %s duplicates a name of module %s", m.Module, def.Name, owner)
+			}
+			codeOwners[def.Code] = m.Module
+			nameOwners[def.Name] = m.Module
+		}
+	}
+
+	ranged := make([]*moduleDef, 0, len(modules))
+	for _, m := range modules {
+		if m.Range != nil {
+			ranged = append(ranged, m)
+		}
+	}
+	sort.Slice(ranged, func(i, j int) bool {
+		return ranged[i].Range[0] < ranged[j].Range[0]
+	})
+	for i := 1; i < len(ranged); i++ {
+		if prev, cur := ranged[i-1], ranged[i]; cur.Range[0] <= prev.Range[1] {
+			return fmt.Errorf("range %v of module %s overlaps range %v of module %s", cur.Range, cur.Module, prev.Range, prev.Module)
+		}
+	}
+	return nil
+}
+
+func inRange(r []int32, code int32) bool {
+	return code >= r[0] && code <= r[1]
+}
+
+// camelToSnake EK8sCheckCode -> E_K8S_CHECK_CODE
+func camelToSnake(name string) string {
+	runes := []rune(name)
+	var sb strings.Builder
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && !unicode.IsUpper(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || nextLower {
+				sb.WriteByte('_')
+			}
+		}
+		sb.WriteRune(unicode.ToUpper(r))
+	}
+	return sb.String()
+}
+
+// snakeToCamel E_K8S_CHECK_CODE -> EK8sCheckCode
+func snakeToCamel(name string) string {
+	var sb strings.Builder
+	for _, part := range strings.Split(name, "_") {
+		if part == "" {
+			continue
+		}
+		sb.WriteString(strings.ToUpper(part[:1]))
+		sb.WriteString(strings.ToLower(part[1:]))
+	}
+	return sb.String()
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/opendevops-cn/codo-golang-sdk/cerr"
+)
+
+// 导出格式
+const (
+	formatJSON     = "json"
+	formatMarkdown = "markdown"
+	formatTS       = "ts"
+	formatProto    = "proto"
+)
+
+const generatedHeader = "// Code generated by cerrgen. DO NOT EDIT.\n"
+
+// exportCodes 合并已注册的 code 和定义文件中的 code, 按 code 升序
+func exportCodes(modules []*moduleDef) []cerr.CodeInfo {
+	infos := cerr.Codes()
+	for _, m := range modules {
+		for _, def := range m.Codes {
+			grpcCode, _, _ := def.grpcCode()
+			infos = append(infos, cerr.CodeInfo{
+				Code:     cerr.ErrCode(def.Code),
+				Name:     def.Name,
+				Module:   m.Module,
+				Messages: def.Messages,
+				HTTPCode: def.httpCode(),
+				GRPCCode: grpcCode,
+			})
+		}
+	}
+	sort.SliceStable(infos, func(i, j int) bool {
+		return infos[i].Code < infos[j].Code
+	})
+	return infos
+}
+
+func export(w io.Writer, format string, infos []cerr.CodeInfo, protoPackage string) error {
+	switch format {
+	case formatJSON:
+		return exportJSON(w, infos)
+	case formatMarkdown, "md":
+		return exportMarkdown(w, infos)
+	case formatTS, "typescript":
+		return exportTS(w, infos)
+	case formatProto:
+		return exportProto(w, infos, protoPackage)
+	default:
+		return fmt.Errorf("unknown format %q, use json, markdown, ts or proto", format)
+	}
+}
+
+// codeName 没有常量名时使用 Code{code}
+func codeName(info cerr.CodeInfo) string {
+	if info.Name != "" {
+		return info.Name
+	}
+	return "Code" + strings.ReplaceAll(strconv.Itoa(int(info.Code)), "-", "Neg")
+}
+
+// sortedLocales 返回出现过的语言, DefaultLocale 在最前
+func sortedLocales(infos []cerr.CodeInfo) []string {
+	seen := map[string]bool{}
+	for _, info := range infos {
+		for locale := range info.Messages {
+			seen[locale] = true
+		}
+	}
+	locales := make([]string, 0, len(seen))
+	for locale := range seen {
+		locales = append(locales, locale)
+	}
+	sort.Slice(locales, func(i, j int) bool {
+		if (locales[i] == cerr.DefaultLocale) != (locales[j] == cerr.DefaultLocale) {
+			return locales[i] == cerr.DefaultLocale
+		}
+		return locales[i] < locales[j]
+	})
+	return locales
+}
+
+type jsonCode struct {
+	Code       int32             `json:"code"`
+	Name       string            `json:"name"`
+	Module     string            `json:"module"`
+	HTTPStatus int               `json:"http_status"`
+	GRPCCode   string            `json:"grpc_code"`
+	Messages   map[string]string `json:"messages"`
+}
+
+func exportJSON(w io.Writer, infos []cerr.CodeInfo) error {
+	items := make([]jsonCode, 0, len(infos))
+	for _, info := range infos {
+		items = append(items, jsonCode{
+			Code:       int32(info.Code),
+			Name:       codeName(info),
+			Module:     info.Module,
+			HTTPStatus: info.HTTPCode,
+			GRPCCode:   info.GRPCCode.String(),
+			Messages:   info.Messages,
+		})
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(items)
+}
+
+func exportMarkdown(w io.Writer, infos []cerr.CodeInfo) error {
+	locales := sortedLocales(infos)
+	var sb strings.Builder
+	sb.WriteString("# 错误码\n\n| Code | Name | Module | HTTP | gRPC |")
+	for _, locale := range locales {
+		sb.WriteString(" " + locale + " |")
+	}
+	sb.WriteString("\n|---:|---|---|---:|---|")
+	sb.WriteString(strings.Repeat("---|", len(locales)))
+	sb.WriteString("\n")
+	for _, info := range infos {
+		fmt.Fprintf(&sb, "| %d | %s | %s | %d | %s |", info.Code, codeName(info), info.Module, info.HTTPCode, info.GRPCCode)
+		for _, locale := range locales {
+			sb.WriteString(" " + markdownEscape(info.Messages[locale]) + " |")
+		}
+		sb.WriteString("\n")
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func markdownEscape(s string) string {
+	return strings.NewReplacer("|", `\|`, "\r", "", "\n", "<br>").Replace(s)
+}
+
+func exportTS(w io.Writer, infos []cerr.CodeInfo) error {
+	var sb strings.Builder
+	sb.WriteString(generatedHeader)
+	sb.WriteString("\nexport enum ErrCode {\n")
+	for _, info := range infos {
+		fmt.Fprintf(&sb, "  %s = %d,\n", codeName(info), info.Code)
+	}
+	sb.WriteString("}\n\nexport const ErrCodeMessages: Record<number, Record<string, string>> = {\n")
+	for _, info := range infos {
+		bs, err := json.Marshal(info.Messages)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(&sb, "  [ErrCode.%s]: %s,\n", codeName(info), bs)
+	}
+	sb.WriteString("};\n\nexport const ErrCodeHTTPStatus: Record<number, number> = {\n")
+	for _, info := range infos {
+		fmt.Fprintf(&sb, "  [ErrCode.%s]: %d,\n", codeName(info), info.HTTPCode)
+	}
+	sb.WriteString("};\n")
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// exportProto 输出带注解的 enum, 可以再作为 -def 读取
+func exportProto(w io.Writer, infos []cerr.CodeInfo, protoPackage string) error {
+	locales := sortedLocales(infos)
+	var sb strings.Builder
+	sb.WriteString(generatedHeader)
+	fmt.Fprintf(&sb, "\nsyntax = \"proto3\";\n\npackage %s;\n\nenum ErrCode {\n", protoPackage)
+	for i, info := range infos {
+		if i == 0 && info.Code != 0 {
+			return fmt.Errorf("the first proto3 enum value must be 0, got %d", info.Code)
+		}
+		name := codeName(info)
+		snake := camelToSnake(name)
+		if info.Module != "" {
+			fmt.Fprintf(&sb, "  // @module %s\n", info.Module)
+		}
+		if snakeToCamel(snake) != name {
+			fmt.Fprintf(&sb, "  // @name %s\n", name)
+		}
+		fmt.Fprintf(&sb, "  // @http %d\n  // @grpc %s\n", info.HTTPCode, info.GRPCCode)
+		for _, locale := range locales {
+			if msg, ok := info.Messages[locale]; ok {
+				fmt.Fprintf(&sb, "  // @%s %s\n", locale, strings.Join(strings.Fields(msg), " "))
+			}
+		}
+		fmt.Fprintf(&sb, "  %s = %d;\n", snake, info.Code)
+	}
+	sb.WriteString("}\n")
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
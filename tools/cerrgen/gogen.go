@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"go/format"
+	"go/token"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/opendevops-cn/codo-golang-sdk/cerr"
+)
+
+// generateGo 生成 Go 常量和在 init 中调用 cerr.RegisterCode 的代码, 注册失败时 panic
+func generateGo(w io.Writer, modules []*moduleDef, pkg string) error {
+	if pkg == "" {
+		for _, m := range modules {
+			if m.Package == "" {
+				continue
+			}
+			if pkg != "" && pkg != m.Package {
+				return fmt.Errorf("modules use different packages %s and %s, use -pkg", pkg, m.Package)
+			}
+			pkg = m.Package
+		}
+	}
+	if !token.IsIdentifier(pkg) {
+		return fmt.Errorf("invalid package name %q, set package in the definition or use -pkg", pkg)
+	}
+
+	var (
+		consts   strings.Builder
+		inits    strings.Builder
+		useCodes bool
+	)
+	for _, m := range modules {
+		if m.Range != nil {
+			fmt.Fprintf(&consts, "\n// %s 模块错误码, 区间 [%d, %d]\nconst (\n", m.Module, m.Range[0], m.Range[1])
+		} else {
+			fmt.Fprintf(&consts, "\n// %s 模块错误码\nconst (\n", m.Module)
+		}
+		for _, def := range m.Codes {
+			fmt.Fprintf(&consts, "\t// %s %s\n\t%s = cerr.ErrCode(%d)\n",
+				def.Name, strings.Join(strings.Fields(def.Messages[cerr.DefaultLocale]), " "), def.Name, def.Code)
+
+			fmt.Fprintf(&inits, "\tif err := cerr.RegisterCode(%s, %s,\n", def.Name, strconv.Quote(def.Messages[cerr.DefaultLocale]))
+			fmt.Fprintf(&inits, "\t\tcerr.WithRegisterCodeOptionModule(%s),\n", strconv.Quote(m.Module))
+			fmt.Fprintf(&inits, "\t\tcerr.WithRegisterCodeOptionName(%s),\n", strconv.Quote(def.Name))
+			fmt.Fprintf(&inits, "\t\tcerr.WithRegisterCodeOptionHTTPCode(%d),\n", def.httpCode())
+			if grpcCode, explicit, _ := def.grpcCode(); explicit {
+				useCodes = true
+				fmt.Fprintf(&inits, "\t\tcerr.WithRegisterCodeOptionGRPCCode(codes.%s),\n", grpcCode)
+			}
+			locales := make([]string, 0, len(def.Messages))
+			for locale := range def.Messages {
+				if locale != cerr.DefaultLocale {
+					locales = append(locales, locale)
+				}
+			}
+			sort.Strings(locales)
+			for _, locale := range locales {
+				fmt.Fprintf(&inits, "\t\tcerr.WithRegisterCodeOptionTranslation(%s, %s),\n", strconv.Quote(locale), strconv.Quote(def.Messages[locale]))
+			}
+			inits.WriteString("\t); err != nil {\n\t\tpanic(err)\n\t}\n")
+		}
+		consts.WriteString(")\n")
+	}
+
+	var sb strings.Builder
+	sb.WriteString(generatedHeader)
+	fmt.Fprintf(&sb, "\npackage %s\n\nimport (\n", pkg)
+	sb.WriteString("\t\"github.com/opendevops-cn/codo-golang-sdk/cerr\"\n")
+	if useCodes {
+		sb.WriteString("\t\"google.golang.org/grpc/codes\"\n")
+	}
+	sb.WriteString(")\n")
+	sb.WriteString(consts.String())
+	sb.WriteString("\nfunc init() {\n")
+	sb.WriteString(inits.String())
+	sb.WriteString("}\n")
+
+	bs, err := format.Source([]byte(sb.String()))
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(bs)
+	return err
+}
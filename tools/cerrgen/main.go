@@ -0,0 +1,104 @@
+// cerrgen 导出 cerr 错误码, 以及根据定义文件生成 Go 代码
+//
+//	cerrgen export -format json|markdown|ts|proto [-def order.yaml] [-o codes.ts]
+//	cerrgen gen -def order.yaml [-pkg ordererr] [-o order_codes.go]
+//
+// export 输出内置 code 和 -def 定义的 code, gen 生成 Go 常量和 RegisterCode 调用.
+// 定义文件支持 YAML 和带注解的 .proto, 格式见 moduleDef, 生成前会检查 code 和模块区间是否冲突
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+	if flag.NArg() == 0 {
+		usage()
+		os.Exit(2)
+	}
+
+	if err := run(flag.Arg(0), flag.Args()[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintf(out, "Usage: %s export|gen [flags]\n", os.Args[0])
+	fmt.Fprintln(out, "  export 导出错误码为 json、markdown、ts 或 proto")
+	fmt.Fprintln(out, "  gen    根据定义文件生成 Go 常量和 RegisterCode 调用")
+}
+
+// defFiles 可重复的 -def 参数, 也支持逗号分隔
+type defFiles []string
+
+func (f *defFiles) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *defFiles) Set(value string) error {
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			*f = append(*f, item)
+		}
+	}
+	return nil
+}
+
+func run(command string, args []string, stdout io.Writer) error {
+	var (
+		defs    defFiles
+		output  string
+		buf     bytes.Buffer
+		flagSet = flag.NewFlagSet("cerrgen "+command, flag.ContinueOnError)
+	)
+	flagSet.Var(&defs, "def", "定义文件, .yaml/.yml 或 .proto, 可重复")
+	flagSet.StringVar(&output, "o", "", "输出文件, 默认标准输出")
+
+	switch command {
+	case "export":
+		format := flagSet.String("format", formatJSON, "输出格式, json、markdown、ts 或 proto")
+		protoPackage := flagSet.String("proto-package", "cerr", "proto 格式的 package")
+		if err := flagSet.Parse(args); err != nil {
+			return err
+		}
+		modules, err := loadModules(defs)
+		if err != nil {
+			return err
+		}
+		if err := export(&buf, *format, exportCodes(modules), *protoPackage); err != nil {
+			return err
+		}
+	case "gen":
+		pkg := flagSet.String("pkg", "", "生成代码的 package, 默认使用定义文件中的 package")
+		if err := flagSet.Parse(args); err != nil {
+			return err
+		}
+		if len(defs) == 0 {
+			return fmt.Errorf("-def is required")
+		}
+		modules, err := loadModules(defs)
+		if err != nil {
+			return err
+		}
+		if err := generateGo(&buf, modules, *pkg); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown command %q, use export or gen", command)
+	}
+
+	if output == "" {
+		_, err := stdout.Write(buf.Bytes())
+		return err
+	}
+	return os.WriteFile(output, buf.Bytes(), 0o644)
+}
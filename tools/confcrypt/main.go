@@ -0,0 +1,73 @@
+// confcrypt 加解密配置中的敏感字段, 生成的密文可以直接写入 YAML 或环境变量,
+// config.LoadConfig 加载时会自动解密
+//
+//	AES_CRYPTO_KEY=xxx confcrypt 123456
+//	echo -n 123456 | confcrypt -key xxx
+//	confcrypt -key xxx -d enc:AES256-GCM:...
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/opendevops-cn/codo-golang-sdk/config"
+)
+
+func main() {
+	var (
+		key     string
+		decrypt bool
+	)
+	flag.StringVar(&key, "key", os.Getenv(config.SecretKeyEnv), "加解密密钥, 默认读取环境变量 "+config.SecretKeyEnv)
+	flag.BoolVar(&decrypt, "d", false, "解密")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [-key KEY] [-d] [value...]\n", os.Args[0])
+		fmt.Fprintln(flag.CommandLine.Output(), "没有传入 value 时从标准输入按行读取")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if err := run(key, decrypt, flag.Args(), os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(key string, decrypt bool, values []string, in io.Reader, out io.Writer) error {
+	if key == "" {
+		return fmt.Errorf("secret key is empty, use -key or env %s", config.SecretKeyEnv)
+	}
+
+	if len(values) == 0 {
+		scanner := bufio.NewScanner(in)
+		for scanner.Scan() {
+			if line := strings.TrimRight(scanner.Text(), "\r"); line != "" {
+				values = append(values, line)
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return err
+		}
+	}
+
+	for _, value := range values {
+		var (
+			result string
+			err    error
+		)
+		if decrypt {
+			result, err = config.DecryptSecret(key, value)
+		} else {
+			result, err = config.EncryptSecret(key, value)
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(out, result)
+	}
+	return nil
+}
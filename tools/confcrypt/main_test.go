@@ -0,0 +1,36 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	const key = "test-key"
+
+	var encrypted bytes.Buffer
+	if err := run(key, false, []string{"123456", "p@ssw0rd"}, nil, &encrypted); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(encrypted.String()), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "enc:") || strings.Contains(encrypted.String(), "123456") {
+		t.Fatalf("encrypted====%s", encrypted.String())
+	}
+
+	// 没有传入 value 时从标准输入按行读取, 忽略空行
+	var decrypted bytes.Buffer
+	if err := run(key, true, nil, strings.NewReader(lines[0]+"\r\n\n"+lines[1]+"\n"), &decrypted); err != nil {
+		t.Fatal(err)
+	}
+	if got := decrypted.String(); got != "123456\np@ssw0rd\n" {
+		t.Errorf("decrypted====%q", got)
+	}
+
+	if err := run("wrong-key", true, lines[:1], nil, &decrypted); err == nil {
+		t.Error("decrypt with wrong key should fail")
+	}
+	if err := run("", false, []string{"123456"}, nil, &encrypted); err == nil {
+		t.Error("empty key should fail")
+	}
+}
@@ -0,0 +1,45 @@
+// Package confdump 输出最终生效的配置, 每个字段标注来源, 用于排查线上配置问题
+// 配置结构体由业务定义, 所以需要在业务程序中注册为子命令, 例如
+//
+//	if len(os.Args) > 1 && os.Args[1] == "confdump" {
+//		err := confdump.Run(&cfg, os.Args[2:], os.Stdout, config.WithYaml("config.yaml"))
+//		...
+//	}
+//
+// 支持 -format yaml|json, 标记了 secret:"true" 的字段输出为 ******
+package confdump
+
+import (
+	"errors"
+	"flag"
+	"io"
+
+	"github.com/opendevops-cn/codo-golang-sdk/config"
+)
+
+// Run 按 opts 加载配置到 dst 并输出到 out, args 为子命令参数
+// 配置校验失败时仍然会输出, 再返回校验错误
+func Run(dst interface{}, args []string, out io.Writer, opts ...config.ConfigOption) error {
+	flagSet := flag.NewFlagSet("confdump", flag.ContinueOnError)
+	flagSet.SetOutput(out)
+	format := flagSet.String("format", config.DumpYAML, "输出格式, yaml 或 json")
+	if err := flagSet.Parse(args); err != nil {
+		return err
+	}
+
+	provenance := config.NewProvenance()
+	loadErr := config.LoadConfig(dst, append(opts, config.WithProvenance(provenance))...)
+	var validationErrs config.ValidationErrors
+	if loadErr != nil && !errors.As(loadErr, &validationErrs) {
+		return loadErr
+	}
+
+	bs, err := config.Dump(dst, *format, provenance)
+	if err != nil {
+		return err
+	}
+	if _, err := out.Write(bs); err != nil {
+		return err
+	}
+	return loadErr
+}
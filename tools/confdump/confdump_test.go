@@ -0,0 +1,102 @@
+package confdump
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/opendevops-cn/codo-golang-sdk/config"
+)
+
+type mysqlConfig struct {
+	Host string `json:"host" default:"127.0.0.1"`
+	Port uint32 `json:"port" max:"65535"`
+	Pass string `json:"pass" secret:"true"`
+}
+
+type testConfig struct {
+	Name  string      `json:"name"`
+	MySQL mysqlConfig `json:"mysql"`
+}
+
+func writeYAML(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "app.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRunYAML(t *testing.T) {
+	path := writeYAML(t, "name: app\nmysql:\n  pass: p@ssw0rd\n")
+	t.Setenv("TEST_CONFDUMP_MYSQL_PORT", "3307")
+
+	var out bytes.Buffer
+	var dst testConfig
+	if err := Run(&dst, []string{"-format", "yaml"}, &out, config.WithYaml(path), config.WithEnv("TEST_CONFDUMP")); err != nil {
+		t.Fatal(err)
+	}
+
+	got := out.String()
+	for _, want := range []string{
+		"name: app # file:" + path,
+		"host: 127.0.0.1 # " + config.OriginDefault,
+		"port: 3307 # " + config.OriginEnv,
+		"pass: '******' # file:" + path,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("want====%s\ngot====%s", want, got)
+		}
+	}
+	if strings.Contains(got, "p@ssw0rd") {
+		t.Errorf("secret leaked====%s", got)
+	}
+}
+
+func TestRunJSON(t *testing.T) {
+	path := writeYAML(t, "name: app\nmysql:\n  pass: p@ssw0rd\n")
+
+	var out bytes.Buffer
+	var dst testConfig
+	if err := Run(&dst, []string{"-format", "json"}, &out, config.WithYaml(path)); err != nil {
+		t.Fatal(err)
+	}
+
+	var got struct {
+		Config  testConfig        `json:"config"`
+		Origins map[string]string `json:"origins"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("%v====%s", err, out.String())
+	}
+	if got.Config.Name != "app" || got.Config.MySQL.Pass != "******" {
+		t.Errorf("config====%+v", got.Config)
+	}
+	if got.Origins["mysql.pass"] != "file:"+path || got.Origins["mysql.host"] != config.OriginDefault {
+		t.Errorf("origins====%v", got.Origins)
+	}
+}
+
+func TestRunValidationError(t *testing.T) {
+	path := writeYAML(t, "name: app\nmysql:\n  port: 70000\n")
+
+	var out bytes.Buffer
+	var dst testConfig
+	err := Run(&dst, nil, &out, config.WithYaml(path))
+	var validationErrs config.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		t.Fatalf("err====%v", err)
+	}
+	if !strings.Contains(out.String(), "port: 70000 # file:"+path) {
+		t.Errorf("out====%s", out.String())
+	}
+
+	if err := Run(&dst, []string{"-format", "xml"}, &out, config.WithYaml(path)); err == nil {
+		t.Error("unsupported format should fail")
+	}
+}
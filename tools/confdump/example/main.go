@@ -0,0 +1,54 @@
+// example 演示在业务程序中注册 confdump 子命令
+//
+//	EXAMPLE_MYSQL_HOST=10.0.0.1 go run ./tools/confdump/example confdump -conf config.yaml -format json
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/opendevops-cn/codo-golang-sdk/config"
+	"github.com/opendevops-cn/codo-golang-sdk/tools/confdump"
+)
+
+type mysqlConfig struct {
+	Host string `json:"host" default:"127.0.0.1"`
+	Port uint32 `json:"port" default:"3306"`
+	User string `json:"user"`
+	Pass string `json:"pass" secret:"true"`
+}
+
+type appConfig struct {
+	Name  string      `json:"name" default:"example"`
+	MySQL mysqlConfig `json:"mysql"`
+}
+
+func main() {
+	if len(os.Args) > 1 && os.Args[1] == "confdump" {
+		if err := dump(os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+	fmt.Fprintf(os.Stderr, "Usage: %s confdump [-conf config.yaml] [-format yaml|json]\n", os.Args[0])
+	os.Exit(2)
+}
+
+// dump -conf 由业务自己解析, 其余参数交给 confdump
+func dump(args []string) error {
+	flagSet := flag.NewFlagSet("confdump", flag.ContinueOnError)
+	conf := flagSet.String("conf", "", "配置文件")
+	format := flagSet.String("format", config.DumpYAML, "输出格式, yaml 或 json")
+	if err := flagSet.Parse(args); err != nil {
+		return err
+	}
+
+	opts := []config.ConfigOption{config.WithEnv("EXAMPLE")}
+	if *conf != "" {
+		opts = append(opts, config.WithYaml(*conf))
+	}
+	var cfg appConfig
+	return confdump.Run(&cfg, []string{"-format", *format}, os.Stdout, opts...)
+}
@@ -5,9 +5,8 @@ import (
 	"google.golang.org/grpc/status"
 )
 
+// FromError 还原 CodeError.AsGrpcError 转换的错误, status 为 OK 时返回 false
 func FromError(status *status.Status) (*cerr.CodeError, bool) {
-	if cerr.ErrCode(status.Code()) == cerr.SCode {
-		return nil, false
-	}
-	return cerr.New(cerr.ErrCode(status.Code()), status.Err()), true
+	e := cerr.FromGrpcStatus(status)
+	return e, e != nil
 }
@@ -0,0 +1,75 @@
+package cgrpc
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/opendevops-cn/codo-golang-sdk/cerr"
+	"google.golang.org/grpc"
+)
+
+// toStatusError 将 CodeError 转为 gRPC status 错误, 其他错误原样返回
+func toStatusError(err error) error {
+	var e *cerr.CodeError
+	if errors.As(err, &e) {
+		return e.AsGrpcError().Err()
+	}
+	return err
+}
+
+// UnaryServerInterceptor 将 handler 返回的 CodeError 转为标准 gRPC 状态码的错误
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		return resp, toStatusError(err)
+	}
+}
+
+// StreamServerInterceptor 将 handler 返回的 CodeError 转为标准 gRPC 状态码的错误
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return toStatusError(handler(srv, ss))
+	}
+}
+
+// UnaryClientInterceptor 将服务端返回的 gRPC 错误还原为 CodeError
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return cerr.FromGrpcError(invoker(ctx, method, req, reply, cc, opts...))
+	}
+}
+
+// StreamClientInterceptor 将建立流和收发消息时的 gRPC 错误还原为 CodeError, io.EOF 原样返回
+func StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		cs, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			return nil, cerr.FromGrpcError(err)
+		}
+		return &clientStream{ClientStream: cs}, nil
+	}
+}
+
+type clientStream struct {
+	grpc.ClientStream
+}
+
+func (s *clientStream) SendMsg(m interface{}) error {
+	return fromStreamError(s.ClientStream.SendMsg(m))
+}
+
+func (s *clientStream) RecvMsg(m interface{}) error {
+	return fromStreamError(s.ClientStream.RecvMsg(m))
+}
+
+func (s *clientStream) CloseSend() error {
+	return fromStreamError(s.ClientStream.CloseSend())
+}
+
+func fromStreamError(err error) error {
+	if err == nil || errors.Is(err, io.EOF) {
+		return err
+	}
+	return cerr.FromGrpcError(err)
+}
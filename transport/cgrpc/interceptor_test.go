@@ -0,0 +1,34 @@
+package cgrpc
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/opendevops-cn/codo-golang-sdk/cerr"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestInterceptorRoundTrip(t *testing.T) {
+	server := UnaryServerInterceptor()
+	client := UnaryClientInterceptor()
+
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		_, err := server(ctx, req, &grpc.UnaryServerInfo{FullMethod: method}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, cerr.New(cerr.EDataNotFoundCode, errors.New("order 1001 not found")).
+				WithFieldViolation("id", "unknown order")
+		})
+		if s, _ := status.FromError(err); s.Code() != codes.NotFound {
+			t.Errorf("server status====%s", s)
+		}
+		return err
+	}
+	err := client(context.Background(), "/order.Order/Get", nil, nil, nil, invoker)
+
+	var e *cerr.CodeError
+	if !errors.As(err, &e) || e.Code != cerr.EDataNotFoundCode || len(e.Details.FieldViolations) != 1 {
+		t.Errorf("client error====%v", err)
+	}
+}
@@ -17,14 +17,18 @@ import (
 	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
 )
 
 type options struct {
 	propagator propagation.TextMapPropagator
+	// localeQuery 指定错误提示语言的 query 参数, 优先于 Accept-Language
+	localeQuery string
 }
 
 var optionsDefault = options{
-	propagator: propagation.NewCompositeTextMapPropagator(propagation.Baggage{}, propagation.TraceContext{}),
+	propagator:  propagation.NewCompositeTextMapPropagator(propagation.Baggage{}, propagation.TraceContext{}),
+	localeQuery: "lang",
 }
 
 type Resp struct {
@@ -40,6 +44,8 @@ type Resp struct {
 	Result json.RawMessage `json:"result"`
 	// TraceID
 	TraceID string `json:"trace_id"`
+	// 错误详情, google.rpc 错误详情的 JSON, 例如 {"@type": "type.googleapis.com/google.rpc.BadRequest", ...}
+	Details []json.RawMessage `json:"details,omitempty"`
 }
 
 var (
@@ -139,20 +145,28 @@ func ErrorEncoder(writer http.ResponseWriter, request *http.Request, err error)
 	// 错误转化
 	if impl, ok := err.(interface{ ErrorName() string }); ok &&
 		strings.HasSuffix(impl.ErrorName(), "ValidationError") {
-		err = cerr.New(cerr.EInvalidParamCode, err)
+		codeError := cerr.New(cerr.EInvalidParamCode, err)
+		if v, ok := err.(interface {
+			Field() string
+			Reason() string
+		}); ok {
+			codeError.WithFieldViolation(v.Field(), v.Reason())
+		}
+		err = codeError
 	}
 
 	// 错误返回
 	codeError := cerr.From(err)
 	errCode := codeError.Code
 	statusCode := codeError.Code.AsHTTPCode()
-	msg := codeError.Code.String()
+	msg, locale := codeError.Code.Localize(requestLocales(request)...)
 
 	ctx := optionsDefault.propagator.Extract(request.Context(), propagation.HeaderCarrier(request.Header))
 	sp := trace.SpanContextFromContext(ctx)
 	milliSecondsStr := strconv.Itoa(int(time.Now().UnixMilli()))
 
 	// 写入
+	writer.Header().Set("Content-Language", locale)
 	writer.WriteHeader(statusCode)
 	_ = json.NewEncoder(writer).Encode(&Resp{
 		Code:      errCode,
@@ -160,12 +174,58 @@ func ErrorEncoder(writer http.ResponseWriter, request *http.Request, err error)
 		Reason:    err.Error(),
 		Timestamp: milliSecondsStr,
 		TraceID:   sp.TraceID().String(),
+		Details:   encodeDetails(codeError),
 	})
 }
 
+// encodeDetails 将错误详情编码为 google.rpc 错误详情的 JSON
+func encodeDetails(e *cerr.CodeError) []json.RawMessage {
+	if e.Details.IsEmpty() {
+		return nil
+	}
+	var details []json.RawMessage
+	for _, msg := range e.DetailsProto() {
+		a, err := anypb.New(msg)
+		if err != nil {
+			continue
+		}
+		bs, err := marshalOptions.Marshal(a)
+		if err != nil {
+			continue
+		}
+		details = append(details, bs)
+	}
+	return details
+}
+
+// decodeDetails 解析 encodeDetails 编码的错误详情, 无法解析的会被忽略
+func decodeDetails(details []json.RawMessage) cerr.Details {
+	anys := make([]*anypb.Any, 0, len(details))
+	for _, bs := range details {
+		a := &anypb.Any{}
+		if err := unmarshalOptions.Unmarshal(bs, a); err != nil {
+			continue
+		}
+		anys = append(anys, a)
+	}
+	return cerr.DetailsFromAny(anys)
+}
+
+// requestLocales 按 query 参数、Accept-Language 的顺序返回偏好的错误提示语言
+func requestLocales(request *http.Request) []string {
+	var preferred []string
+	if lang := request.URL.Query().Get(optionsDefault.localeQuery); lang != "" {
+		preferred = append(preferred, lang)
+	}
+	preferred = append(preferred, cerr.ParseAcceptLanguage(request.Header.Get("Accept-Language"))...)
+	return preferred
+}
+
 func FromError(resp *Resp) (*cerr.CodeError, bool) {
 	if resp.Code == cerr.SCode {
 		return nil, false
 	}
-	return cerr.New(resp.Code, errors.New(resp.Reason)), true
+	e := cerr.New(resp.Code, errors.New(resp.Reason))
+	e.Details = decodeDetails(resp.Details)
+	return e, true
 }
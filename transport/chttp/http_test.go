@@ -0,0 +1,61 @@
+package chttp
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opendevops-cn/codo-golang-sdk/cerr"
+)
+
+func TestErrorEncoderDetails(t *testing.T) {
+	src := cerr.New(cerr.EDataNotFoundCode, errors.New("order 1001 not found")).
+		WithResourceInfo(cerr.ResourceInfo{ResourceType: "order", ResourceName: "1001"}).
+		WithRetryInfo(time.Second)
+
+	rec := httptest.NewRecorder()
+	ErrorEncoder(rec, httptest.NewRequest("GET", "/orders/1001?lang=en", nil), src)
+
+	if rec.Code != 404 || rec.Header().Get("Content-Language") != "en" {
+		t.Errorf("resp====%d %s", rec.Code, rec.Header().Get("Content-Language"))
+	}
+	if !strings.Contains(rec.Body.String(), `"@type":"type.googleapis.com/google.rpc.ResourceInfo"`) {
+		t.Errorf("body====%s", rec.Body.String())
+	}
+
+	var resp Resp
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Msg != "Data not found!" {
+		t.Errorf("msg====%s", resp.Msg)
+	}
+	got, ok := FromError(&resp)
+	if !ok || got.Code != src.Code || !reflect.DeepEqual(got.Details, src.Details) {
+		t.Errorf("from error====%+v", got)
+	}
+}
+
+func TestErrorEncoderContentLanguage(t *testing.T) {
+	code := cerr.ErrCode(90201)
+	if err := cerr.RegisterCode(code, "余额不足!", cerr.WithRegisterCodeOptionTranslation("en", "Insufficient balance!")); err != nil {
+		t.Fatal(err)
+	}
+
+	request := httptest.NewRequest("GET", "/orders", nil)
+	request.Header.Set("Accept-Language", "ja,en;q=0.8")
+	rec := httptest.NewRecorder()
+	ErrorEncoder(rec, request, cerr.New(code, errors.New("balance 0")))
+
+	var resp Resp
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Msg != "Insufficient balance!" || rec.Header().Get("Content-Language") != "en" {
+		t.Errorf("resp====%s %s", resp.Msg, rec.Header().Get("Content-Language"))
+	}
+}